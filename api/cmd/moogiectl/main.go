@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itskarma/moogie/api/internal/models"
+)
+
+// fileList collects repeated -f flags
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 || os.Args[1] != "apply" {
+		fmt.Fprintln(os.Stderr, "Usage: moogiectl apply -f <file or directory> [-f ...] [--prune] [--dry-run] [--api-url URL]")
+		os.Exit(2)
+	}
+
+	var files fileList
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	flags.Var(&files, "f", "Manifest file or directory of *.yaml/*.yml files (repeatable)")
+	prune := flags.Bool("prune", false, "Delete manifest-managed jobs that are not in the given files")
+	dryRun := flags.Bool("dry-run", false, "Show what would change without applying it")
+	apiURL := flags.String("api-url", getEnvOrDefault("MOOGIE_API_URL", "http://localhost:8080"), "Moogie API URL")
+	flags.Parse(os.Args[2:])
+
+	if len(files) == 0 {
+		log.Fatal("at least one -f is required")
+	}
+
+	body, err := readManifests(files)
+	if err != nil {
+		log.Fatalf("Failed to read manifests: %v", err)
+	}
+
+	result, err := apply(*apiURL, body, *prune, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to apply manifests: %v", err)
+	}
+
+	printResult(result)
+}
+
+// readManifests concatenates all manifest files into a single multi-document YAML stream
+func readManifests(paths []string) ([]byte, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// apply posts the manifests to the API and returns the diff
+func apply(apiURL string, body []byte, prune, dryRun bool) (*models.ApplyResult, error) {
+	query := url.Values{}
+	query.Set("prune", fmt.Sprint(prune))
+	query.Set("dryRun", fmt.Sprint(dryRun))
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v1/apply?%s", apiURL, query.Encode()), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("API returned %d: %s", resp.StatusCode, apiErr.Error)
+		}
+		return nil, fmt.Errorf("API returned non-success status: %d", resp.StatusCode)
+	}
+
+	var result models.ApplyResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func printResult(result *models.ApplyResult) {
+	suffix := ""
+	if result.DryRun {
+		suffix = " (dry run)"
+	}
+
+	for _, name := range result.Created {
+		fmt.Printf("job/%s created%s\n", name, suffix)
+	}
+	for _, name := range result.Updated {
+		fmt.Printf("job/%s updated%s\n", name, suffix)
+	}
+	for _, name := range result.Deleted {
+		fmt.Printf("job/%s deleted%s\n", name, suffix)
+	}
+	for _, name := range result.Unchanged {
+		fmt.Printf("job/%s unchanged\n", name)
+	}
+
+	fmt.Printf("\n%d created, %d updated, %d deleted, %d unchanged%s\n",
+		len(result.Created), len(result.Updated), len(result.Deleted), len(result.Unchanged), suffix)
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -41,9 +42,15 @@ func main() {
 	jobService := services.NewJobService(db)
 	executionService := services.NewExecutionService(db, jobService)
 	dashboardService := services.NewDashboardService(db, jobService, executionService)
+	applyService := services.NewApplyService(db)
+	certificateService := services.NewCertificateService(db)
+	heartbeatService := services.NewHeartbeatService(db)
 
 	// Initialize handlers
-	handler := handlers.NewHandler(jobService, executionService, dashboardService, wsHub)
+	handler := handlers.NewHandler(jobService, executionService, dashboardService, applyService, certificateService, heartbeatService, wsHub)
+
+	// Record failures for heartbeat jobs that stop pinging
+	go handler.MonitorHeartbeats(30 * time.Second)
 
 	// Setup Gin router
 	if cfg.AppEnv == "production" {
@@ -55,7 +62,7 @@ func main() {
 	// Setup CORS
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = cfg.AllowedOrigins
-	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
 	router.Use(cors.New(corsConfig))
 
@@ -87,8 +94,17 @@ func setupRoutes(router *gin.Engine, handler *handlers.Handler) {
 		{
 			jobs.GET("", handler.GetJobs)
 			jobs.GET("/:id", handler.GetJob)
+			jobs.GET("/:id/timings", handler.GetJobTimings)
+			jobs.GET("/:id/heartbeat", handler.GetJobHeartbeat)
+			jobs.POST("", handler.CreateJob)
+			jobs.PUT("/:id", handler.UpdateJob)
+			jobs.PATCH("/:id", handler.PatchJob)
+			jobs.DELETE("/:id", handler.DeleteJob)
 		}
 
+		// Declarative check manifests
+		v1.POST("/apply", handler.ApplyManifests)
+
 		// Executions
 		executions := v1.Group("/executions")
 		{
@@ -100,5 +116,18 @@ func setupRoutes(router *gin.Engine, handler *handlers.Handler) {
 		{
 			dashboard.GET("/summary", handler.GetDashboardSummary)
 		}
+
+		// Certificate inventory
+		certificates := v1.Group("/certificates")
+		{
+			certificates.GET("", handler.GetCertificates)
+			certificates.GET("/expiring", handler.GetExpiringCertificates)
+		}
+
+		// Heartbeat pings from push-based jobs
+		heartbeats := v1.Group("/heartbeats")
+		{
+			heartbeats.POST("/:token", handler.RecordHeartbeat)
+		}
 	}
 }
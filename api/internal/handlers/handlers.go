@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/itskarma/moogie/api/internal/manifest"
 	"github.com/itskarma/moogie/api/internal/models"
 	"github.com/itskarma/moogie/api/internal/services"
+	"github.com/itskarma/moogie/api/internal/specs"
 	"github.com/itskarma/moogie/api/internal/websocket"
 )
 
 type Handler struct {
-	jobService       *services.JobService
-	executionService *services.ExecutionService
-	dashboardService *services.DashboardService
-	wsHub            *websocket.Hub
+	jobService         *services.JobService
+	executionService   *services.ExecutionService
+	dashboardService   *services.DashboardService
+	applyService       *services.ApplyService
+	certificateService *services.CertificateService
+	heartbeatService   *services.HeartbeatService
+	wsHub              *websocket.Hub
 }
 
 // NewHandler creates a new handler instance
@@ -24,13 +33,19 @@ func NewHandler(
 	jobService *services.JobService,
 	executionService *services.ExecutionService,
 	dashboardService *services.DashboardService,
+	applyService *services.ApplyService,
+	certificateService *services.CertificateService,
+	heartbeatService *services.HeartbeatService,
 	wsHub *websocket.Hub,
 ) *Handler {
 	return &Handler{
-		jobService:       jobService,
-		executionService: executionService,
-		dashboardService: dashboardService,
-		wsHub:            wsHub,
+		jobService:         jobService,
+		executionService:   executionService,
+		dashboardService:   dashboardService,
+		applyService:       applyService,
+		certificateService: certificateService,
+		heartbeatService:   heartbeatService,
+		wsHub:              wsHub,
 	}
 }
 
@@ -110,6 +125,221 @@ func (h *Handler) GetJob(c *gin.Context) {
 	c.JSON(http.StatusOK, job)
 }
 
+// @Summary Get job timing breakdown
+// @Description Get the HTTP phase timings (DNS, connect, TLS, TTFB, transfer) of a job's executions with averages and 95th percentiles
+// @Tags jobs
+// @Produce json
+// @Param id path int true "Job ID"
+// @Param from query string false "Start date (ISO 8601 format)"
+// @Param to query string false "End date (ISO 8601 format)"
+// @Param limit query int false "Limit number of executions included" default(100)
+// @Success 200 {object} models.TimingBreakdown
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /jobs/{id}/timings [get]
+func (h *Handler) GetJobTimings(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 100 // default
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	breakdown, err := h.executionService.GetTimingBreakdown(id, from, to, limit)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// @Summary Create job
+// @Description Create a new monitoring job
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param job body models.CreateJobRequest true "Job data"
+// @Success 201 {object} models.Job
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /jobs [post]
+func (h *Handler) CreateJob(c *gin.Context) {
+	var req models.CreateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.jobService.CreateJob(&req)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	// The heartbeat token is only returned to the client creating the job
+	broadcast := *job
+	broadcast.Heartbeat = nil
+	h.wsHub.BroadcastJobUpdated(&broadcast)
+
+	c.JSON(http.StatusCreated, job)
+}
+
+// @Summary Update job
+// @Description Replace the name, type, config and enabled flag of a job
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path int true "Job ID"
+// @Param job body models.UpdateJobRequest true "Job data"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /jobs/{id} [put]
+func (h *Handler) UpdateJob(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	var req models.UpdateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.jobService.UpdateJob(id, &req)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	h.wsHub.BroadcastJobUpdated(job)
+
+	c.JSON(http.StatusOK, job)
+}
+
+// @Summary Patch job
+// @Description Partially update a job, e.g. {"enabled": false} to disable it
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path int true "Job ID"
+// @Param job body models.PatchJobRequest true "Fields to update"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /jobs/{id} [patch]
+func (h *Handler) PatchJob(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	var req models.PatchJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.jobService.PatchJob(id, &req)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	h.wsHub.BroadcastJobUpdated(job)
+
+	c.JSON(http.StatusOK, job)
+}
+
+// @Summary Delete job
+// @Description Delete a job and its execution history
+// @Tags jobs
+// @Param id path int true "Job ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /jobs/{id} [delete]
+func (h *Handler) DeleteJob(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := h.jobService.DeleteJob(id)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	h.wsHub.BroadcastJobUpdated(job)
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Apply check manifests
+// @Description Create, update and optionally prune jobs from one or more moogie.io/v1 YAML documents
+// @Tags apply
+// @Accept application/yaml
+// @Produce json
+// @Param prune query bool false "Delete manifest-managed jobs that are not in the request"
+// @Param dryRun query bool false "Report the diff without changing anything"
+// @Success 200 {object} models.ApplyResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /apply [post]
+func (h *Handler) ApplyManifests(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	docs, err := manifest.Parse(bytes.NewReader(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prune := c.Query("prune") == "true"
+	dryRun := c.Query("dryRun") == "true"
+
+	result, changed, err := h.applyService.Apply(docs, prune, dryRun)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	for i := range changed {
+		h.wsHub.BroadcastJobUpdated(&changed[i])
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // @Summary Create execution result
 // @Description Create a new execution result (called by runner service)
 // @Tags executions
@@ -138,12 +368,166 @@ func (h *Handler) CreateExecution(c *gin.Context) {
 		return
 	}
 
-	// Broadcast the new execution to WebSocket clients
-	h.wsHub.BroadcastExecutionCreated(execution)
+	h.broadcastExecution(execution)
+
+	c.JSON(http.StatusCreated, execution)
+}
+
+// @Summary Get job heartbeat
+// @Description Get the heartbeat of a heartbeat job, including the token used to ping it
+// @Tags heartbeats
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} models.Heartbeat
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /jobs/{id}/heartbeat [get]
+func (h *Handler) GetJobHeartbeat(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	heartbeat, err := h.heartbeatService.GetHeartbeat(id)
+	if err != nil {
+		if err.Error() == "heartbeat not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Heartbeat not found"})
+		} else {
+			writeJobError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, heartbeat)
+}
+
+// @Summary Record heartbeat ping
+// @Description Record an execution for a heartbeat job, called by the monitored job itself when it finishes. The body is optional and defaults to a success. Pings for disabled jobs are rejected.
+// @Tags heartbeats
+// @Accept json
+// @Produce json
+// @Param token path string true "Heartbeat token"
+// @Param ping body models.HeartbeatPingRequest false "Ping result"
+// @Success 201 {object} models.Execution
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /heartbeats/{token} [post]
+func (h *Handler) RecordHeartbeat(c *gin.Context) {
+	// A ping without a body is a success
+	var req models.HeartbeatPingRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	execution, err := h.heartbeatService.RecordPing(c.Param("token"), &req)
+	if err != nil {
+		switch err.Error() {
+		case "heartbeat not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Heartbeat not found"})
+		case "job is disabled":
+			c.JSON(http.StatusConflict, gin.H{"error": "Job is disabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	h.broadcastExecution(execution)
 
 	c.JSON(http.StatusCreated, execution)
 }
 
+// MonitorHeartbeats records a failed execution for each heartbeat job that
+// missed its deadline, checking every interval until the process exits
+func (h *Handler) MonitorHeartbeats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		missed, err := h.heartbeatService.RecordMissed(now)
+		if err != nil {
+			log.Printf("Failed to check heartbeats: %v", err)
+			continue
+		}
+		for i := range missed {
+			h.broadcastExecution(&missed[i])
+		}
+	}
+}
+
+// broadcastExecution sends a new execution to WebSocket clients, together with
+// the status change and certificate replacement it causes
+func (h *Handler) broadcastExecution(execution *models.Execution) {
+	h.wsHub.BroadcastExecutionCreated(execution)
+
+	// Let clients know when a job moves between success, degraded and failure
+	if change, err := h.executionService.GetStatusChange(execution); err != nil {
+		log.Printf("Failed to compare execution status: %v", err)
+	} else if change != nil {
+		h.wsHub.BroadcastStatusChanged(change)
+	}
+
+	// Keep the certificate inventory up to date from SSL check results
+	if replaced, err := h.certificateService.RecordExecution(execution); err != nil {
+		log.Printf("Failed to record certificate: %v", err)
+	} else if replaced != nil {
+		h.wsHub.BroadcastCertificateReplaced(replaced)
+	}
+}
+
+// @Summary Get certificates
+// @Description Get every TLS certificate seen by SSL checks, ordered by expiry, with the jobs that see it
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Certificate
+// @Failure 500 {object} map[string]string
+// @Router /certificates [get]
+func (h *Handler) GetCertificates(c *gin.Context) {
+	certificates, err := h.certificateService.GetCertificates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, certificates)
+}
+
+// @Summary Get expiring certificates
+// @Description Get certificates that expire within the given number of days across all jobs, including expired ones
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Param days query int false "Days until expiry (default: 30)"
+// @Success 200 {array} models.Certificate
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /certificates/expiring [get]
+func (h *Handler) GetExpiringCertificates(c *gin.Context) {
+	days := 30 // default
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsedDays, err := strconv.Atoi(daysStr)
+		if err != nil || parsedDays < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a non-negative integer"})
+			return
+		}
+		days = parsedDays
+	}
+
+	certificates, err := h.certificateService.GetExpiringCertificates(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, certificates)
+}
+
 // @Summary Get dashboard summary
 // @Description Get aggregated dashboard data with job summaries
 // @Tags dashboard
@@ -193,6 +577,33 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 	})
 }
 
+// parseJobID parses the :id path parameter
+func parseJobID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// writeJobError maps job service errors to HTTP responses
+func writeJobError(c *gin.Context, err error) {
+	var validationErr *specs.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": validationErr.Details(),
+		})
+	case err.Error() == "job not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+	case err.Error() == "job name already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": "Job name already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // parseDateRange parses the from and to query parameters
 // Expects ISO 8601 timestamp format (2006-01-02T15:04:05Z)
 // Returns default range (last 7 days) if not provided
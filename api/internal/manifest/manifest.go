@@ -0,0 +1,100 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/itskarma/moogie/api/internal/models"
+	"github.com/itskarma/moogie/api/internal/specs"
+	"gopkg.in/yaml.v3"
+)
+
+// Document represents a single Kubernetes-style check manifest, e.g. config/checks/api-health-check.yaml
+type Document struct {
+	APIVersion string                 `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                 `json:"kind" yaml:"kind"`
+	Metadata   Metadata               `json:"metadata" yaml:"metadata"`
+	Spec       map[string]interface{} `json:"spec" yaml:"spec"`
+}
+
+// Metadata holds the identifying fields of a manifest
+type Metadata struct {
+	Name   string            `json:"name" yaml:"name"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Parse reads one or more YAML (or JSON) documents separated by "---".
+// Empty documents are skipped and every document is validated.
+func Parse(r io.Reader) ([]Document, error) {
+	decoder := yaml.NewDecoder(r)
+
+	var docs []Document
+	names := make(map[string]bool)
+	for index := 0; ; index++ {
+		var doc Document
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("document %d: %w", index+1, err)
+		}
+
+		// Skip empty documents, e.g. a trailing "---"
+		if doc.APIVersion == "" && doc.Kind == "" && doc.Metadata.Name == "" && doc.Spec == nil {
+			continue
+		}
+
+		if err := doc.Validate(); err != nil {
+			return nil, fmt.Errorf("document %d: %w", index+1, err)
+		}
+
+		if names[doc.Metadata.Name] {
+			return nil, fmt.Errorf("document %d: duplicate metadata.name %q", index+1, doc.Metadata.Name)
+		}
+		names[doc.Metadata.Name] = true
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// Validate checks the envelope of a manifest; the spec itself is validated with the job
+func (d *Document) Validate() error {
+	if d.APIVersion != specs.APIVersion {
+		return fmt.Errorf("unsupported apiVersion %q, expected %q", d.APIVersion, specs.APIVersion)
+	}
+
+	if _, ok := specs.LookupKind(d.Kind); !ok {
+		return fmt.Errorf("unknown kind %q", d.Kind)
+	}
+
+	if strings.TrimSpace(d.Metadata.Name) == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+
+	if d.Spec == nil {
+		return fmt.Errorf("spec is required")
+	}
+
+	return nil
+}
+
+// ToJob converts the manifest into a job. The whole document is stored as the job config.
+func (d *Document) ToJob() (*models.Job, error) {
+	config, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %q: %w", d.Metadata.Name, err)
+	}
+
+	kind, _ := specs.LookupKind(d.Kind)
+	return &models.Job{
+		Name:    d.Metadata.Name,
+		Type:    kind.Type,
+		Config:  config,
+		Enabled: true,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itskarma/moogie/api/internal/specs"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantNames []string
+		wantErr   string
+	}{
+		{
+			name: "several documents",
+			input: `apiVersion: moogie.io/v1
+kind: HttpCheck
+metadata:
+  name: api-health
+spec:
+  url: https://example.com/health
+  schedule: "*/5 * * * *"
+---
+apiVersion: moogie.io/v1
+kind: PingCheck
+metadata:
+  name: gateway-ping
+spec:
+  host: 10.0.0.1
+  schedule: "* * * * *"
+---
+`,
+			wantNames: []string{"api-health", "gateway-ping"},
+		},
+		{
+			name:      "JSON",
+			input:     `{"apiVersion":"moogie.io/v1","kind":"PingCheck","metadata":{"name":"gateway-ping"},"spec":{"host":"10.0.0.1"}}`,
+			wantNames: []string{"gateway-ping"},
+		},
+		{name: "empty", input: "---\n"},
+		{
+			name:    "unsupported apiVersion",
+			input:   "apiVersion: moogie.io/v2\nkind: PingCheck\nmetadata: {name: a}\nspec: {}\n",
+			wantErr: `document 1: unsupported apiVersion "moogie.io/v2", expected "moogie.io/v1"`,
+		},
+		{
+			name:    "unknown kind",
+			input:   "apiVersion: moogie.io/v1\nkind: FtpCheck\nmetadata: {name: a}\nspec: {}\n",
+			wantErr: `document 1: unknown kind "FtpCheck"`,
+		},
+		{
+			name:    "missing name",
+			input:   "apiVersion: moogie.io/v1\nkind: PingCheck\nspec: {}\n",
+			wantErr: "document 1: metadata.name is required",
+		},
+		{
+			name:    "missing spec",
+			input:   "apiVersion: moogie.io/v1\nkind: PingCheck\nmetadata: {name: a}\n",
+			wantErr: "document 1: spec is required",
+		},
+		{
+			name:    "duplicate name",
+			input:   "apiVersion: moogie.io/v1\nkind: PingCheck\nmetadata: {name: a}\nspec: {}\n---\napiVersion: moogie.io/v1\nkind: HttpCheck\nmetadata: {name: a}\nspec: {}\n",
+			wantErr: `document 2: duplicate metadata.name "a"`,
+		},
+		{
+			name:    "invalid YAML",
+			input:   "apiVersion: moogie.io/v1\nkind: [PingCheck\n",
+			wantErr: "document 1: yaml:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := Parse(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to start with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			var names []string
+			for _, doc := range docs {
+				names = append(names, doc.Metadata.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestToJob(t *testing.T) {
+	docs, err := Parse(strings.NewReader("apiVersion: moogie.io/v1\nkind: PingCheck\nmetadata:\n  name: gateway-ping\nspec:\n  host: 10.0.0.1\n  count: 3\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	job, err := docs[0].ToJob()
+	if err != nil {
+		t.Fatalf("ToJob: %v", err)
+	}
+	if job.Name != "gateway-ping" || job.Type != "ping" || !job.Enabled {
+		t.Errorf("job = %+v", job)
+	}
+	want := `{"apiVersion":"moogie.io/v1","kind":"PingCheck","metadata":{"name":"gateway-ping"},"spec":{"count":3,"host":"10.0.0.1"}}`
+	if string(job.Config) != want {
+		t.Errorf("config = %s, want %s", job.Config, want)
+	}
+}
+
+// The example manifests must stay valid as the specs change
+func TestExampleManifests(t *testing.T) {
+	files, err := filepath.Glob("../../../config/checks/*.yaml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no example manifests found: %v", err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			docs, err := Parse(f)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			for _, doc := range docs {
+				job, err := doc.ToJob()
+				if err != nil {
+					t.Fatalf("ToJob: %v", err)
+				}
+				if _, _, err := specs.NormalizeConfig(job.Type, job.Name, job.Config); err != nil {
+					t.Errorf("%s: %v", job.Name, err)
+				}
+			}
+		})
+	}
+}
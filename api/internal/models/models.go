@@ -11,53 +11,151 @@ import (
 type Job struct {
 	ID        uint            `json:"id" gorm:"primaryKey"`
 	Name      string          `json:"name" gorm:"not null;uniqueIndex"`
-	Type      string          `json:"type" gorm:"not null"` // e.g., "ping", "ssl", "api-health", "dns"
-	Config    json.RawMessage `json:"config" gorm:"type:jsonb;not null"`
+	Type      string          `json:"type" gorm:"not null"`              // e.g., "http", "tcp", "udp", "grpc", "websocket", "database", "mail", "dns", "ssl", "ping", "heartbeat"
+	Config    json.RawMessage `json:"config" gorm:"type:jsonb;not null"` // moogie.io/v1 document, see internal/specs
 	Enabled   bool            `json:"enabled" gorm:"default:true"`
+	ManagedBy string          `json:"managed_by,omitempty"` // "manifest" for jobs created or updated through /apply
 	CreatedAt time.Time       `json:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at"`
 
 	// Relationships
 	Executions []Execution `json:"executions,omitempty" gorm:"foreignKey:JobID"`
+	Heartbeat  *Heartbeat  `json:"heartbeat,omitempty" gorm:"foreignKey:JobID"` // only returned when a heartbeat job is created
 
 	// Computed fields (not stored in DB)
-	SuccessRate     float64 `json:"success_rate" gorm:"-"`
+	SuccessRate     float64    `json:"success_rate" gorm:"-"`  // success and degraded executions
+	DegradedRate    float64    `json:"degraded_rate" gorm:"-"` // degraded executions only
 	LastExecution   *time.Time `json:"last_execution" gorm:"-"`
-	AvgResponseTime float64 `json:"avg_response_time" gorm:"-"`
+	AvgResponseTime float64    `json:"avg_response_time" gorm:"-"`
 }
 
+// ManagedByManifest marks jobs that /apply owns and may prune
+const ManagedByManifest = "manifest"
+
+// Execution statuses. A degraded check passed but was slower than its latency warning threshold.
+const (
+	StatusSuccess  = "success"
+	StatusDegraded = "degraded"
+	StatusFailure  = "failure"
+)
+
 // Execution represents a job execution result
 type Execution struct {
 	ID           uint            `json:"id" gorm:"primaryKey"`
 	JobID        uint            `json:"job_id" gorm:"not null;index"`
-	Status       string          `json:"status" gorm:"not null"` // "success", "failure"
+	Status       string          `json:"status" gorm:"not null"` // "success", "degraded", "failure"
 	ResponseTime int64           `json:"response_time"`          // in milliseconds
 	Details      json.RawMessage `json:"details" gorm:"type:jsonb"`
 	Timestamp    time.Time       `json:"timestamp" gorm:"not null;index"`
 
 	// Relationships
 	Job Job `json:"job,omitempty" gorm:"foreignKey:JobID"`
+
+	// Computed fields (not stored in DB)
+	Timings *Timings `json:"timings,omitempty" gorm:"-"` // HTTP phase timings from details.timings
+}
+
+// Timings is the phase breakdown of an HTTP check, in milliseconds
+type Timings struct {
+	DNSLookup       float64 `json:"dns_lookup_ms"`
+	TCPConnect      float64 `json:"tcp_connect_ms"`
+	TLSHandshake    float64 `json:"tls_handshake_ms"`
+	TimeToFirstByte float64 `json:"ttfb_ms"`
+	ContentTransfer float64 `json:"content_transfer_ms"`
+	Total           float64 `json:"total_ms"`
+}
+
+// TimingSample is the phase breakdown of a single execution
+type TimingSample struct {
+	ExecutionID uint      `json:"execution_id"`
+	Status      string    `json:"status"`
+	Timestamp   time.Time `json:"timestamp"`
+	Timings     Timings   `json:"timings"`
+}
+
+// TimingBreakdown summarises a job's HTTP phase timings over a time range
+type TimingBreakdown struct {
+	JobID   uint           `json:"job_id"`
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Count   int            `json:"count"`
+	Average Timings        `json:"average"`
+	P95     Timings        `json:"p95"`
+	Samples []TimingSample `json:"samples"` // newest first
 }
 
 // CreateExecutionRequest represents the request body for creating a new execution
 type CreateExecutionRequest struct {
 	JobName      string          `json:"job_name" binding:"required"`
-	Status       string          `json:"status" binding:"required,oneof=success failure"`
+	Status       string          `json:"status" binding:"required,oneof=success degraded failure"`
 	ResponseTime int64           `json:"response_time"`
 	Details      json.RawMessage `json:"details"`
 	Timestamp    time.Time       `json:"timestamp"`
 }
 
+// Heartbeat tracks the pings of a heartbeat job, which reports to
+// POST /api/v1/heartbeats/:token instead of being run by the runner
+type Heartbeat struct {
+	JobID      uint       `json:"job_id" gorm:"primaryKey"`
+	Token      string     `json:"token" gorm:"not null;uniqueIndex"`
+	LastPing   *time.Time `json:"last_ping"`
+	LastStatus string     `json:"last_status,omitempty"`
+	ExpectedBy time.Time  `json:"expected_by" gorm:"not null;index"` // a failure is recorded if no ping arrives by then
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HeartbeatPingRequest represents the optional request body of a heartbeat ping
+type HeartbeatPingRequest struct {
+	Status       string `json:"status" binding:"omitempty,oneof=success degraded failure"` // default: success
+	ResponseTime int64  `json:"response_time"`                                             // how long the job ran, in milliseconds
+	Message      string `json:"message"`
+}
+
+// CreateJobRequest represents the request body for creating a new job
+type CreateJobRequest struct {
+	Name    string          `json:"name" binding:"required"`
+	Type    string          `json:"type" binding:"required"`
+	Config  json.RawMessage `json:"config" binding:"required"`
+	Enabled *bool           `json:"enabled"` // defaults to true
+}
+
+// UpdateJobRequest represents the request body for replacing an existing job
+type UpdateJobRequest struct {
+	Name    string          `json:"name" binding:"required"`
+	Type    string          `json:"type" binding:"required"`
+	Config  json.RawMessage `json:"config" binding:"required"`
+	Enabled *bool           `json:"enabled"` // defaults to true
+}
+
+// PatchJobRequest represents the request body for partially updating a job.
+// Only the fields that are present are changed, e.g. {"enabled": false} disables a job.
+type PatchJobRequest struct {
+	Name    *string         `json:"name"`
+	Type    *string         `json:"type"`
+	Config  json.RawMessage `json:"config"`
+	Enabled *bool           `json:"enabled"`
+}
+
+// ApplyResult reports the changes made (or planned, in a dry run) by applying check manifests
+type ApplyResult struct {
+	DryRun    bool     `json:"dry_run"`
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Deleted   []string `json:"deleted"`
+	Unchanged []string `json:"unchanged"`
+}
+
 // DashboardSummary represents aggregated dashboard metrics
 type DashboardSummary struct {
-	TotalJobs       int64                    `json:"total_jobs"`
-	ActiveJobs      int64                    `json:"active_jobs"`
-	OverallSuccess  float64                  `json:"overall_success_rate"`
-	TotalExecutions int64                    `json:"total_executions"`
-	JobSummaries    []JobSummary             `json:"job_summaries"`
-	RecentActivity  []Execution              `json:"recent_activity"`
-	StatusBreakdown map[string]int64         `json:"status_breakdown"`
-	TypeBreakdown   map[string]int64         `json:"type_breakdown"`
+	TotalJobs       int64            `json:"total_jobs"`
+	ActiveJobs      int64            `json:"active_jobs"`
+	OverallSuccess  float64          `json:"overall_success_rate"`
+	OverallDegraded float64          `json:"overall_degraded_rate"`
+	TotalExecutions int64            `json:"total_executions"`
+	JobSummaries    []JobSummary     `json:"job_summaries"`
+	RecentActivity  []Execution      `json:"recent_activity"`
+	StatusBreakdown map[string]int64 `json:"status_breakdown"`
+	TypeBreakdown   map[string]int64 `json:"type_breakdown"`
 }
 
 // JobSummary represents a summary of job metrics
@@ -67,6 +165,7 @@ type JobSummary struct {
 	Type             string      `json:"type"`
 	Enabled          bool        `json:"enabled"`
 	SuccessRate      float64     `json:"success_rate"`
+	DegradedRate     float64     `json:"degraded_rate"`
 	LastExecution    *time.Time  `json:"last_execution"`
 	AvgResponseTime  float64     `json:"avg_response_time"`
 	ExecutionCount   int64       `json:"execution_count"`
@@ -81,6 +180,65 @@ type Labels struct {
 	Team        string `json:"team,omitempty"`
 }
 
+// StatusChange describes a job whose latest execution has a different status than the one before it
+type StatusChange struct {
+	JobID       uint      `json:"job_id"`
+	JobName     string    `json:"job_name"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	ExecutionID uint      `json:"execution_id"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Certificate is a TLS certificate seen by SSL checks, identified by its SHA-256 fingerprint
+type Certificate struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Fingerprint  string    `json:"fingerprint" gorm:"not null;uniqueIndex"`
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	SANs         []string  `json:"sans" gorm:"column:sans;type:jsonb;serializer:json"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after" gorm:"index"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+
+	// Computed fields (not stored in DB)
+	DaysUntilExpiry int              `json:"days_until_expiry" gorm:"-"`
+	Jobs            []CertificateJob `json:"jobs" gorm:"-"`
+}
+
+// JobCertificate records that a job's executions reported a certificate
+type JobCertificate struct {
+	JobID         uint      `gorm:"primaryKey"`
+	CertificateID uint      `gorm:"primaryKey"`
+	Host          string    `gorm:"not null"`
+	FirstSeen     time.Time `gorm:"not null"`
+	LastSeen      time.Time `gorm:"not null"`
+}
+
+// CertificateJob is a job that has seen a certificate. Current is true when it is
+// the certificate the job saw most recently.
+type CertificateJob struct {
+	JobID     uint      `json:"job_id"`
+	JobName   string    `json:"job_name"`
+	Host      string    `json:"host"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Current   bool      `json:"current"`
+}
+
+// CertificateReplaced describes a job that started seeing a new certificate in place of another
+type CertificateReplaced struct {
+	JobID       uint         `json:"job_id"`
+	JobName     string       `json:"job_name"`
+	Host        string       `json:"host"`
+	Previous    *Certificate `json:"previous"`
+	Current     *Certificate `json:"current"`
+	ExecutionID uint         `json:"execution_id"`
+	Timestamp   time.Time    `json:"timestamp"`
+}
+
 // WebSocketMessage represents a message sent via WebSocket
 type WebSocketMessage struct {
 	Type string      `json:"type"` // "execution_created", "job_updated", etc.
@@ -96,6 +254,39 @@ func (Execution) TableName() string {
 	return "executions"
 }
 
+func (Heartbeat) TableName() string {
+	return "heartbeats"
+}
+
+func (Certificate) TableName() string {
+	return "certificates"
+}
+
+func (JobCertificate) TableName() string {
+	return "job_certificates"
+}
+
+// AfterFind computes the days left before the certificate expires
+func (c *Certificate) AfterFind(tx *gorm.DB) error {
+	c.DaysUntilExpiry = int(time.Until(c.NotAfter).Hours() / 24)
+	return nil
+}
+
+// AfterFind extracts the HTTP phase timings reported by the runner from the details
+func (e *Execution) AfterFind(tx *gorm.DB) error {
+	e.Timings = nil
+	if len(e.Details) == 0 {
+		return nil
+	}
+	var details struct {
+		Timings *Timings `json:"timings"`
+	}
+	if err := json.Unmarshal(e.Details, &details); err == nil {
+		e.Timings = details.Timings
+	}
+	return nil
+}
+
 // BeforeCreate sets the timestamp if not provided
 func (e *Execution) BeforeCreate(tx *gorm.DB) error {
 	if e.Timestamp.IsZero() {
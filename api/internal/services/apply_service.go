@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/itskarma/moogie/api/internal/manifest"
+	"github.com/itskarma/moogie/api/internal/models"
+	"github.com/itskarma/moogie/api/internal/specs"
+	"gorm.io/gorm"
+)
+
+type ApplyService struct {
+	db *gorm.DB
+}
+
+func NewApplyService(db *gorm.DB) *ApplyService {
+	return &ApplyService{db: db}
+}
+
+// Apply reconciles jobs with the given manifests, keyed on metadata.name.
+// With prune, jobs that were created from manifests but are no longer present are deleted.
+// With dryRun, the diff is computed but nothing is written.
+// The jobs that were created, updated or deleted are returned for broadcasting.
+func (s *ApplyService) Apply(docs []manifest.Document, prune, dryRun bool) (*models.ApplyResult, []models.Job, error) {
+	result := &models.ApplyResult{
+		DryRun:    dryRun,
+		Created:   []string{},
+		Updated:   []string{},
+		Deleted:   []string{},
+		Unchanged: []string{},
+	}
+
+	var changed []models.Job
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		desired := make(map[string]bool)
+
+		for _, doc := range docs {
+			job, err := doc.ToJob()
+			if err != nil {
+				return err
+			}
+
+			if err := validateJob(job); err != nil {
+				return prefixValidationError(job.Name, err)
+			}
+			desired[job.Name] = true
+
+			var existing models.Job
+			err = tx.Where("name = ?", job.Name).First(&existing).Error
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return fmt.Errorf("failed to fetch job %q: %w", job.Name, err)
+			}
+
+			job.ManagedBy = models.ManagedByManifest
+			if err == gorm.ErrRecordNotFound {
+				result.Created = append(result.Created, job.Name)
+				if !dryRun {
+					if err := tx.Create(job).Error; err != nil {
+						return fmt.Errorf("failed to create job %q: %w", job.Name, err)
+					}
+					if _, err := syncHeartbeat(tx, job); err != nil {
+						return fmt.Errorf("failed to create job %q: %w", job.Name, err)
+					}
+				}
+				changed = append(changed, *job)
+				continue
+			}
+
+			if existing.Type == job.Type && sameJSON(existing.Config, job.Config) {
+				result.Unchanged = append(result.Unchanged, job.Name)
+				// A job created through the API with the same config is
+				// taken over by the manifest, so pruning can remove it later
+				if !dryRun && existing.ManagedBy != models.ManagedByManifest {
+					if err := tx.Model(&existing).Update("managed_by", models.ManagedByManifest).Error; err != nil {
+						return fmt.Errorf("failed to update job %q: %w", job.Name, err)
+					}
+				}
+				continue
+			}
+
+			// The enabled flag is not part of the manifest, so it is left as is
+			existing.Type = job.Type
+			existing.Config = job.Config
+			existing.ManagedBy = models.ManagedByManifest
+			result.Updated = append(result.Updated, job.Name)
+			if !dryRun {
+				if err := tx.Model(&existing).
+					Select("type", "config", "managed_by").
+					Updates(&existing).Error; err != nil {
+					return fmt.Errorf("failed to update job %q: %w", job.Name, err)
+				}
+				if _, err := syncHeartbeat(tx, &existing); err != nil {
+					return fmt.Errorf("failed to update job %q: %w", job.Name, err)
+				}
+			}
+			changed = append(changed, existing)
+		}
+
+		if prune {
+			// Only jobs that were applied from manifests are pruned, jobs
+			// created through the API are left alone
+			var managed []models.Job
+			if err := tx.Where("managed_by = ?", models.ManagedByManifest).
+				Find(&managed).Error; err != nil {
+				return fmt.Errorf("failed to fetch managed jobs: %w", err)
+			}
+
+			for _, job := range managed {
+				if desired[job.Name] {
+					continue
+				}
+				result.Deleted = append(result.Deleted, job.Name)
+				if !dryRun {
+					if err := tx.Delete(&job).Error; err != nil {
+						return fmt.Errorf("failed to delete job %q: %w", job.Name, err)
+					}
+				}
+				changed = append(changed, job)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(result.Created)
+	sort.Strings(result.Updated)
+	sort.Strings(result.Deleted)
+	sort.Strings(result.Unchanged)
+
+	if dryRun {
+		changed = nil
+	}
+
+	return result, changed, nil
+}
+
+// prefixValidationError prefixes field paths with the job name so errors can be traced to a document
+func prefixValidationError(name string, err error) error {
+	validationErr, ok := err.(*specs.ValidationError)
+	if !ok {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	prefixed := &specs.ValidationError{}
+	for _, fieldErr := range validationErr.Errors {
+		field := name
+		if fieldErr.Field != "" {
+			field = name + "." + fieldErr.Field
+		}
+		prefixed.Errors = append(prefixed.Errors, specs.FieldError{Field: field, Message: fieldErr.Message})
+	}
+	return prefixed
+}
+
+// sameJSON reports whether two JSON documents are equal regardless of key order and whitespace
+func sameJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+
+	ca, errA := json.Marshal(va)
+	cb, errB := json.Marshal(vb)
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	return bytes.Equal(ca, cb)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/itskarma/moogie/api/internal/specs"
+)
+
+func TestPrefixValidationError(t *testing.T) {
+	err := prefixValidationError("api-health", &specs.ValidationError{Errors: []specs.FieldError{
+		{Field: "config.spec.url", Message: "is required"},
+		{Message: "is invalid"},
+	}})
+	validationErr, ok := err.(*specs.ValidationError)
+	if !ok {
+		t.Fatalf("error = %T, want a *specs.ValidationError", err)
+	}
+	want := []specs.FieldError{
+		{Field: "api-health.config.spec.url", Message: "is required"},
+		{Field: "api-health", Message: "is invalid"},
+	}
+	if !reflect.DeepEqual(validationErr.Errors, want) {
+		t.Errorf("errors = %+v, want %+v", validationErr.Errors, want)
+	}
+
+	cause := errors.New("connection refused")
+	err = prefixValidationError("api-health", cause)
+	if err.Error() != "api-health: connection refused" || !errors.Is(err, cause) {
+		t.Errorf("error = %v, want the cause wrapped", err)
+	}
+}
+
+func TestSameJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"identical", `{"a":1}`, `{"a":1}`, true},
+		{"key order and whitespace", `{"a":1,"b":[1,2]}`, "{\n  \"b\": [1, 2],\n  \"a\": 1\n}", true},
+		{"different value", `{"a":1}`, `{"a":2}`, false},
+		{"array order", `[1,2]`, `[2,1]`, false},
+		{"extra key", `{"a":1}`, `{"a":1,"b":null}`, false},
+		{"invalid", `{"a":1}`, `{"a":`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameJSON([]byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("sameJSON(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
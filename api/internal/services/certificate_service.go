@@ -0,0 +1,191 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/itskarma/moogie/api/internal/models"
+	"gorm.io/gorm"
+)
+
+type CertificateService struct {
+	db *gorm.DB
+}
+
+func NewCertificateService(db *gorm.DB) *CertificateService {
+	return &CertificateService{db: db}
+}
+
+// certificateDetails are the leaf certificate fields an SSL check reports in an execution's details
+type certificateDetails struct {
+	Host         string    `json:"host"`
+	Fingerprint  string    `json:"fingerprint_sha256"`
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	SANs         []string  `json:"sans"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+// RecordExecution adds the certificate reported by an execution to the
+// inventory. If the job previously saw a different certificate and has not
+// seen this one before, the replacement is returned.
+func (s *CertificateService) RecordExecution(execution *models.Execution) (*models.CertificateReplaced, error) {
+	if len(execution.Details) == 0 {
+		return nil, nil
+	}
+	var details certificateDetails
+	if err := json.Unmarshal(execution.Details, &details); err != nil || details.Fingerprint == "" {
+		return nil, nil
+	}
+
+	var replaced *models.CertificateReplaced
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		certificate, err := upsertCertificate(tx, &details, execution.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		var previous models.JobCertificate
+		err = tx.Where("job_id = ?", execution.JobID).Order("last_seen DESC").First(&previous).Error
+		hasPrevious := err == nil
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to fetch job certificate: %w", err)
+		}
+
+		var link models.JobCertificate
+		err = tx.Where("job_id = ? AND certificate_id = ?", execution.JobID, certificate.ID).First(&link).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			link = models.JobCertificate{
+				JobID:         execution.JobID,
+				CertificateID: certificate.ID,
+				Host:          details.Host,
+				FirstSeen:     execution.Timestamp,
+				LastSeen:      execution.Timestamp,
+			}
+			if err := tx.Create(&link).Error; err != nil {
+				return fmt.Errorf("failed to record job certificate: %w", err)
+			}
+
+			if hasPrevious && previous.CertificateID != certificate.ID {
+				var old models.Certificate
+				if err := tx.First(&old, previous.CertificateID).Error; err != nil {
+					return fmt.Errorf("failed to fetch previous certificate: %w", err)
+				}
+				replaced = &models.CertificateReplaced{
+					JobID:       execution.JobID,
+					JobName:     execution.Job.Name,
+					Host:        details.Host,
+					Previous:    &old,
+					Current:     certificate,
+					ExecutionID: execution.ID,
+					Timestamp:   execution.Timestamp,
+				}
+			}
+		case err != nil:
+			return fmt.Errorf("failed to fetch job certificate: %w", err)
+		case execution.Timestamp.After(link.LastSeen):
+			err := tx.Model(&link).Updates(map[string]interface{}{"last_seen": execution.Timestamp, "host": details.Host}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update job certificate: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+// upsertCertificate creates the certificate or extends the time it was last seen
+func upsertCertificate(tx *gorm.DB, details *certificateDetails, seen time.Time) (*models.Certificate, error) {
+	var certificate models.Certificate
+	err := tx.Where("fingerprint = ?", details.Fingerprint).First(&certificate).Error
+	if err == gorm.ErrRecordNotFound {
+		certificate = models.Certificate{
+			Fingerprint:  details.Fingerprint,
+			Subject:      details.Subject,
+			Issuer:       details.Issuer,
+			SerialNumber: details.SerialNumber,
+			SANs:         details.SANs,
+			NotBefore:    details.NotBefore,
+			NotAfter:     details.NotAfter,
+			FirstSeen:    seen,
+			LastSeen:     seen,
+		}
+		if err := tx.Create(&certificate).Error; err != nil {
+			return nil, fmt.Errorf("failed to create certificate: %w", err)
+		}
+		certificate.DaysUntilExpiry = int(time.Until(certificate.NotAfter).Hours() / 24)
+		return &certificate, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch certificate: %w", err)
+	}
+
+	if seen.After(certificate.LastSeen) {
+		if err := tx.Model(&certificate).Update("last_seen", seen).Error; err != nil {
+			return nil, fmt.Errorf("failed to update certificate: %w", err)
+		}
+	}
+	return &certificate, nil
+}
+
+// GetCertificates returns the certificate inventory ordered by expiry, with the jobs that have seen each certificate
+func (s *CertificateService) GetCertificates() ([]models.Certificate, error) {
+	return s.findCertificates(s.db)
+}
+
+// GetExpiringCertificates returns the certificates that expire within the given
+// number of days, including those that have already expired
+func (s *CertificateService) GetExpiringCertificates(days int) ([]models.Certificate, error) {
+	return s.findCertificates(s.db.Where("not_after <= ?", time.Now().AddDate(0, 0, days)))
+}
+
+func (s *CertificateService) findCertificates(query *gorm.DB) ([]models.Certificate, error) {
+	var certificates []models.Certificate
+	if err := query.Order("not_after ASC").Find(&certificates).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch certificates: %w", err)
+	}
+	if len(certificates) == 0 {
+		return certificates, nil
+	}
+
+	ids := make([]uint, len(certificates))
+	for i, certificate := range certificates {
+		ids[i] = certificate.ID
+	}
+
+	var rows []struct {
+		models.CertificateJob
+		CertificateID uint
+	}
+	err := s.db.Table("job_certificates").
+		Select(`job_certificates.certificate_id, job_certificates.job_id, jobs.name AS job_name, job_certificates.host,
+			job_certificates.first_seen, job_certificates.last_seen,
+			job_certificates.last_seen = (SELECT MAX(latest.last_seen) FROM job_certificates latest WHERE latest.job_id = job_certificates.job_id) AS current`).
+		Joins("JOIN jobs ON jobs.id = job_certificates.job_id").
+		Where("job_certificates.certificate_id IN ?", ids).
+		Order("jobs.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch certificate jobs: %w", err)
+	}
+
+	jobs := make(map[uint][]models.CertificateJob)
+	for _, row := range rows {
+		jobs[row.CertificateID] = append(jobs[row.CertificateID], row.CertificateJob)
+	}
+	for i := range certificates {
+		certificates[i].Jobs = jobs[certificates[i].ID]
+		if certificates[i].Jobs == nil {
+			certificates[i].Jobs = []models.CertificateJob{}
+		}
+	}
+
+	return certificates, nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/itskarma/moogie/api/internal/models"
+)
+
+// Executions without a certificate are ignored before the database is touched
+func TestRecordExecutionWithoutCertificate(t *testing.T) {
+	service := NewCertificateService(nil)
+	for _, details := range []string{"", `{"status_code":200}`, `{"host":"example.com","fingerprint_sha256":""}`, `{"host":`} {
+		replaced, err := service.RecordExecution(&models.Execution{JobID: 1, Details: json.RawMessage(details)})
+		if replaced != nil || err != nil {
+			t.Errorf("details %s: replaced = %v, error = %v", details, replaced, err)
+		}
+	}
+}
+
+func TestCertificateDetails(t *testing.T) {
+	// The fields reported by the runner's SSL check
+	raw := `{"host":"example.com","fingerprint_sha256":"ab:cd","subject":"CN=example.com","issuer":"CN=Test CA",
+		"serial_number":"31","sans":["example.com","www.example.com"],
+		"not_before":"2026-01-01T00:00:00Z","not_after":"2026-04-01T00:00:00Z"}`
+	var details certificateDetails
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		t.Fatal(err)
+	}
+	if details.Fingerprint != "ab:cd" || details.SerialNumber != "31" || len(details.SANs) != 2 ||
+		details.NotAfter.Month() != 4 || details.NotBefore.Year() != 2026 {
+		t.Errorf("details = %+v", details)
+	}
+}
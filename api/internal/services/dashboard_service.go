@@ -1,11 +1,11 @@
 package services
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/itskarma/moogie/api/internal/models"
+	"github.com/itskarma/moogie/api/internal/specs"
 	"gorm.io/gorm"
 )
 
@@ -44,15 +44,23 @@ func (s *DashboardService) GetSummary(from, to time.Time) (*models.DashboardSumm
 		return nil, fmt.Errorf("failed to count total executions: %w", err)
 	}
 
-	// Calculate overall success rate
+	// Calculate overall success and degraded rates
 	if summary.TotalExecutions > 0 {
 		var successfulExecutions int64
 		if err := s.db.Model(&models.Execution{}).
-			Where("status = 'success' AND timestamp BETWEEN ? AND ?", from, to).
+			Where("status IN ? AND timestamp BETWEEN ? AND ?",
+				[]string{models.StatusSuccess, models.StatusDegraded}, from, to).
 			Count(&successfulExecutions).Error; err != nil {
 			return nil, fmt.Errorf("failed to count successful executions: %w", err)
 		}
-		summary.OverallSuccess = float64(successfulExecutions) / float64(summary.TotalExecutions) * 100
+
+		var degradedExecutions int64
+		if err := s.db.Model(&models.Execution{}).
+			Where("status = ? AND timestamp BETWEEN ? AND ?", models.StatusDegraded, from, to).
+			Count(&degradedExecutions).Error; err != nil {
+			return nil, fmt.Errorf("failed to count degraded executions: %w", err)
+		}
+		summary.OverallSuccess, summary.OverallDegraded = executionRates(summary.TotalExecutions, successfulExecutions, degradedExecutions)
 	}
 
 	// Get job summaries
@@ -129,6 +137,7 @@ func (s *DashboardService) getJobSummaries(from, to time.Time) ([]models.JobSumm
 			Type:             job.Type,
 			Enabled:          job.Enabled,
 			SuccessRate:      job.SuccessRate,
+			DegradedRate:     job.DegradedRate,
 			LastExecution:    job.LastExecution,
 			AvgResponseTime:  job.AvgResponseTime,
 			ExecutionCount:   executionCount,
@@ -142,20 +151,18 @@ func (s *DashboardService) getJobSummaries(from, to time.Time) ([]models.JobSumm
 	return summaries, nil
 }
 
-// extractLabels extracts labels from job config JSON
+// extractLabels extracts labels from the metadata of a job config
 func extractLabels(configJSON []byte) models.Labels {
-	var config struct {
-		Metadata struct {
-			Labels models.Labels `json:"labels"`
-		} `json:"metadata"`
-	}
-
-	// Try to unmarshal the config
-	if err := json.Unmarshal(configJSON, &config); err != nil {
+	doc, err := specs.ParseDocument(configJSON)
+	if err != nil {
 		return models.Labels{} // Return empty labels if parsing fails
 	}
 
-	return config.Metadata.Labels
+	return models.Labels{
+		Service:     doc.Metadata.Labels["service"],
+		Environment: doc.Metadata.Labels["environment"],
+		Team:        doc.Metadata.Labels["team"],
+	}
 }
 
 // getStatusBreakdown returns count of executions by status
@@ -174,7 +181,12 @@ func (s *DashboardService) getStatusBreakdown(from, to time.Time) (map[string]in
 		return nil, err
 	}
 
-	breakdown := make(map[string]int64)
+	// Always report every status so clients can rely on the keys being present
+	breakdown := map[string]int64{
+		models.StatusSuccess:  0,
+		models.StatusDegraded: 0,
+		models.StatusFailure:  0,
+	}
 	for _, result := range results {
 		breakdown[result.Status] = result.Count
 	}
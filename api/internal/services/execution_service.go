@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/itskarma/moogie/api/internal/models"
@@ -54,6 +56,34 @@ func (s *ExecutionService) CreateExecution(req *models.CreateExecutionRequest) (
 	return execution, nil
 }
 
+// GetStatusChange compares an execution with the job's previous execution and
+// returns the change, or nil if the status is the same or there is no previous execution
+func (s *ExecutionService) GetStatusChange(execution *models.Execution) (*models.StatusChange, error) {
+	var previous models.Execution
+	err := s.db.Where("job_id = ? AND id <> ? AND timestamp <= ?", execution.JobID, execution.ID, execution.Timestamp).
+		Order("timestamp DESC").
+		First(&previous).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch previous execution: %w", err)
+	}
+
+	if previous.Status == execution.Status {
+		return nil, nil
+	}
+
+	return &models.StatusChange{
+		JobID:       execution.JobID,
+		JobName:     execution.Job.Name,
+		From:        previous.Status,
+		To:          execution.Status,
+		ExecutionID: execution.ID,
+		Timestamp:   execution.Timestamp,
+	}, nil
+}
+
 // GetExecutionsByJobID retrieves executions for a specific job
 func (s *ExecutionService) GetExecutionsByJobID(jobID uint, from, to time.Time, limit int) ([]models.Execution, error) {
 	var executions []models.Execution
@@ -88,3 +118,90 @@ func (s *ExecutionService) GetRecentExecutions(limit int) ([]models.Execution, e
 
 	return executions, nil
 }
+
+// GetTimingBreakdown returns the HTTP phase timings of a job's executions in a
+// time range, with the average and 95th percentile of each phase
+func (s *ExecutionService) GetTimingBreakdown(jobID uint, from, to time.Time, limit int) (*models.TimingBreakdown, error) {
+	if _, err := s.jobService.findJob(jobID); err != nil {
+		return nil, err
+	}
+
+	var executions []models.Execution
+	query := s.db.Where("job_id = ? AND timestamp BETWEEN ? AND ? AND details->'timings' IS NOT NULL", jobID, from, to).
+		Order("timestamp DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&executions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch executions: %w", err)
+	}
+
+	breakdown := &models.TimingBreakdown{
+		JobID:   jobID,
+		From:    from,
+		To:      to,
+		Samples: []models.TimingSample{},
+	}
+
+	for _, execution := range executions {
+		if execution.Timings == nil {
+			continue
+		}
+		breakdown.Samples = append(breakdown.Samples, models.TimingSample{
+			ExecutionID: execution.ID,
+			Status:      execution.Status,
+			Timestamp:   execution.Timestamp,
+			Timings:     *execution.Timings,
+		})
+	}
+
+	breakdown.Count = len(breakdown.Samples)
+	breakdown.Average = summarizeTimings(breakdown.Samples, average)
+	breakdown.P95 = summarizeTimings(breakdown.Samples, percentile95)
+
+	return breakdown, nil
+}
+
+// summarizeTimings applies an aggregate to each phase across the samples
+func summarizeTimings(samples []models.TimingSample, aggregate func([]float64) float64) models.Timings {
+	phase := func(get func(models.Timings) float64) float64 {
+		values := make([]float64, len(samples))
+		for i, sample := range samples {
+			values[i] = get(sample.Timings)
+		}
+		return aggregate(values)
+	}
+
+	return models.Timings{
+		DNSLookup:       phase(func(t models.Timings) float64 { return t.DNSLookup }),
+		TCPConnect:      phase(func(t models.Timings) float64 { return t.TCPConnect }),
+		TLSHandshake:    phase(func(t models.Timings) float64 { return t.TLSHandshake }),
+		TimeToFirstByte: phase(func(t models.Timings) float64 { return t.TimeToFirstByte }),
+		ContentTransfer: phase(func(t models.Timings) float64 { return t.ContentTransfer }),
+		Total:           phase(func(t models.Timings) float64 { return t.Total }),
+	}
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return math.Round(sum/float64(len(values))*100) / 100
+}
+
+// percentile95 uses the nearest-rank method
+func percentile95(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return sorted[rank]
+}
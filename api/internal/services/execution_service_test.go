@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/itskarma/moogie/api/internal/models"
+)
+
+func TestExecutionTimingsFromDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		details string
+		want    *models.Timings
+	}{
+		{
+			name:    "http check",
+			details: `{"status_code":200,"timings":{"dns_lookup_ms":1.5,"tcp_connect_ms":2,"tls_handshake_ms":10.25,"ttfb_ms":40,"content_transfer_ms":5,"total_ms":45}}`,
+			want:    &models.Timings{DNSLookup: 1.5, TCPConnect: 2, TLSHandshake: 10.25, TimeToFirstByte: 40, ContentTransfer: 5, Total: 45},
+		},
+		{name: "no timings", details: `{"port":22}`},
+		{name: "no details"},
+		{name: "invalid details", details: `{"timings":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execution := models.Execution{Details: json.RawMessage(tt.details), Timings: &models.Timings{Total: 1}}
+			if err := execution.AfterFind(nil); err != nil {
+				t.Fatalf("AfterFind: %v", err)
+			}
+			switch {
+			case tt.want == nil && execution.Timings != nil:
+				t.Errorf("timings = %+v, want none", *execution.Timings)
+			case tt.want != nil && (execution.Timings == nil || *execution.Timings != *tt.want):
+				t.Errorf("timings = %+v, want %+v", execution.Timings, *tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeTimings(t *testing.T) {
+	var samples []models.TimingSample
+	for i := 1; i <= 20; i++ {
+		v := float64(i)
+		samples = append(samples, models.TimingSample{Timings: models.Timings{
+			DNSLookup: v, TCPConnect: 2 * v, TLSHandshake: 0, TimeToFirstByte: 10 * v, ContentTransfer: v / 3, Total: 10*v + v/3,
+		}})
+	}
+
+	avg := summarizeTimings(samples, average)
+	want := models.Timings{DNSLookup: 10.5, TCPConnect: 21, TLSHandshake: 0, TimeToFirstByte: 105, ContentTransfer: 3.5, Total: 108.5}
+	if avg != want {
+		t.Errorf("average = %+v, want %+v", avg, want)
+	}
+
+	// The nearest rank of the 95th percentile of 20 samples is the 19th
+	p95 := summarizeTimings(samples, percentile95)
+	want = models.Timings{DNSLookup: 19, TCPConnect: 38, TLSHandshake: 0, TimeToFirstByte: 190, ContentTransfer: 19.0 / 3, Total: 190 + 19.0/3}
+	if p95 != want {
+		t.Errorf("p95 = %+v, want %+v", p95, want)
+	}
+
+	if empty := summarizeTimings(nil, percentile95); empty != (models.Timings{}) {
+		t.Errorf("no samples = %+v, want zeros", empty)
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	tests := []struct {
+		values  []float64
+		average float64
+		p95     float64
+	}{
+		{nil, 0, 0},
+		{[]float64{7}, 7, 7},
+		{[]float64{3, 1, 2}, 2, 3},
+		{[]float64{1, 2, 2}, 1.67, 2},
+		{[]float64{100, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 5.71, 1},
+	}
+
+	for _, tt := range tests {
+		if got := average(tt.values); got != tt.average {
+			t.Errorf("average(%v) = %v, want %v", tt.values, got, tt.average)
+		}
+		if got := percentile95(tt.values); got != tt.p95 {
+			t.Errorf("percentile95(%v) = %v, want %v", tt.values, got, tt.p95)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/itskarma/moogie/api/internal/models"
+	"github.com/itskarma/moogie/api/internal/specs"
+	"gorm.io/gorm"
+)
+
+// heartbeatType is the job type whose executions are pushed by the job itself
+const heartbeatType = "heartbeat"
+
+type HeartbeatService struct {
+	db *gorm.DB
+}
+
+func NewHeartbeatService(db *gorm.DB) *HeartbeatService {
+	return &HeartbeatService{db: db}
+}
+
+// RecordPing records an execution for the heartbeat job with the given token
+// and moves its deadline to the next expected ping
+func (s *HeartbeatService) RecordPing(token string, req *models.HeartbeatPingRequest) (*models.Execution, error) {
+	var heartbeat models.Heartbeat
+	if err := s.db.Where("token = ?", token).First(&heartbeat).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("heartbeat not found")
+		}
+		return nil, fmt.Errorf("failed to fetch heartbeat: %w", err)
+	}
+
+	var job models.Job
+	if err := s.db.First(&job, heartbeat.JobID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch job: %w", err)
+	}
+	// Disabled jobs are not monitored for missed pings, so their pings are
+	// not recorded either
+	if !job.Enabled {
+		return nil, fmt.Errorf("job is disabled")
+	}
+	spec, err := heartbeatSpec(&job)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := req.Status
+	if status == "" {
+		status = models.StatusSuccess
+	}
+
+	details := map[string]interface{}{"expected_by": heartbeat.ExpectedBy}
+	if heartbeat.LastPing != nil {
+		details["since_last_ping_ms"] = now.Sub(*heartbeat.LastPing).Milliseconds()
+	}
+	if now.After(heartbeat.ExpectedBy) {
+		details["late"] = true
+	}
+	if req.Message != "" {
+		details["message"] = req.Message
+		if status == models.StatusFailure {
+			details["error"] = req.Message
+		}
+	}
+
+	expectedBy, err := spec.Deadline(now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid heartbeat config: %w", err)
+	}
+
+	execution := &models.Execution{
+		JobID:        job.ID,
+		Status:       status,
+		ResponseTime: req.ResponseTime,
+		Timestamp:    now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := createHeartbeatExecution(tx, execution, details); err != nil {
+			return err
+		}
+		return tx.Model(&heartbeat).Updates(map[string]interface{}{
+			"last_ping":   now,
+			"last_status": status,
+			"expected_by": expectedBy,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+
+	execution.Job = job
+	return execution, nil
+}
+
+// GetHeartbeat returns the heartbeat, including its token, of a heartbeat job
+func (s *HeartbeatService) GetHeartbeat(jobID uint) (*models.Heartbeat, error) {
+	var job models.Job
+	if err := s.db.First(&job, jobID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("job not found")
+		}
+		return nil, fmt.Errorf("failed to fetch job: %w", err)
+	}
+
+	var heartbeat models.Heartbeat
+	if err := s.db.Where("job_id = ?", job.ID).First(&heartbeat).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("heartbeat not found")
+		}
+		return nil, fmt.Errorf("failed to fetch heartbeat: %w", err)
+	}
+	return &heartbeat, nil
+}
+
+// RecordMissed records a failed execution for every enabled heartbeat job
+// whose deadline has passed. The deadline then moves on by one period, so a
+// job that stays silent fails once per expected ping.
+func (s *HeartbeatService) RecordMissed(now time.Time) ([]models.Execution, error) {
+	var heartbeats []models.Heartbeat
+	if err := s.db.Joins("JOIN jobs ON jobs.id = heartbeats.job_id").
+		Where("jobs.type = ? AND jobs.enabled = ? AND heartbeats.expected_by < ?", heartbeatType, true, now).
+		Find(&heartbeats).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch overdue heartbeats: %w", err)
+	}
+
+	var missed []models.Execution
+	for _, heartbeat := range heartbeats {
+		execution, err := s.recordMissed(&heartbeat, now)
+		if err != nil {
+			log.Printf("Failed to record missed heartbeat for job %d: %v", heartbeat.JobID, err)
+			continue
+		}
+		if execution != nil {
+			missed = append(missed, *execution)
+		}
+	}
+
+	return missed, nil
+}
+
+// recordMissed records a single missed ping. It returns nil if another API
+// instance already recorded it.
+func (s *HeartbeatService) recordMissed(heartbeat *models.Heartbeat, now time.Time) (*models.Execution, error) {
+	var job models.Job
+	if err := s.db.First(&job, heartbeat.JobID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch job: %w", err)
+	}
+	spec, err := heartbeatSpec(&job)
+	if err != nil {
+		return nil, err
+	}
+	expectedBy, err := spec.Deadline(now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid heartbeat config: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"error":       fmt.Sprintf("No ping received by %s", heartbeat.ExpectedBy.UTC().Format(time.RFC3339)),
+		"missed":      true,
+		"expected_by": heartbeat.ExpectedBy,
+		"last_ping":   heartbeat.LastPing,
+	}
+	execution := &models.Execution{
+		JobID:     job.ID,
+		Status:    models.StatusFailure,
+		Timestamp: now,
+	}
+
+	recorded := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the deadline first so concurrent monitors record it only once
+		result := tx.Model(&models.Heartbeat{}).
+			Where("job_id = ? AND expected_by = ?", heartbeat.JobID, heartbeat.ExpectedBy).
+			Update("expected_by", expectedBy)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		recorded = true
+		return createHeartbeatExecution(tx, execution, details)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record missed heartbeat: %w", err)
+	}
+	if !recorded {
+		return nil, nil
+	}
+
+	execution.Job = job
+	return execution, nil
+}
+
+// createHeartbeatExecution stores an execution with the given details
+func createHeartbeatExecution(tx *gorm.DB, execution *models.Execution, details map[string]interface{}) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	execution.Details = encoded
+	return tx.Create(execution).Error
+}
+
+// syncHeartbeat gives a heartbeat job a token on creation and restarts its
+// deadline from now whenever the job is saved, so enabling a job or changing
+// its schedule does not count the time before as missed. Jobs of other types
+// lose their token. The heartbeat is returned for heartbeat jobs.
+func syncHeartbeat(tx *gorm.DB, job *models.Job) (*models.Heartbeat, error) {
+	if job.Type != heartbeatType {
+		if err := tx.Where("job_id = ?", job.ID).Delete(&models.Heartbeat{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete heartbeat: %w", err)
+		}
+		return nil, nil
+	}
+
+	spec, err := heartbeatSpec(job)
+	if err != nil {
+		return nil, err
+	}
+	expectedBy, err := spec.Deadline(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid heartbeat config: %w", err)
+	}
+
+	var heartbeat models.Heartbeat
+	err = tx.Where("job_id = ?", job.ID).First(&heartbeat).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		token, err := newHeartbeatToken()
+		if err != nil {
+			return nil, err
+		}
+		heartbeat = models.Heartbeat{JobID: job.ID, Token: token, ExpectedBy: expectedBy}
+		if err := tx.Create(&heartbeat).Error; err != nil {
+			return nil, fmt.Errorf("failed to create heartbeat: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch heartbeat: %w", err)
+	default:
+		heartbeat.ExpectedBy = expectedBy
+		if err := tx.Model(&heartbeat).Update("expected_by", expectedBy).Error; err != nil {
+			return nil, fmt.Errorf("failed to update heartbeat: %w", err)
+		}
+	}
+
+	return &heartbeat, nil
+}
+
+// heartbeatSpec decodes the spec of a heartbeat job
+func heartbeatSpec(job *models.Job) (*specs.HeartbeatSpec, error) {
+	doc, err := specs.ParseDocument(job.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config for job %s: %w", job.Name, err)
+	}
+	var spec specs.HeartbeatSpec
+	if err := json.Unmarshal(doc.Spec, &spec); err != nil {
+		return nil, fmt.Errorf("invalid heartbeat spec for job %s: %w", job.Name, err)
+	}
+	return &spec, nil
+}
+
+// newHeartbeatToken returns a random token for a heartbeat URL
+func newHeartbeatToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate heartbeat token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/itskarma/moogie/api/internal/models"
+)
+
+func TestHeartbeatSpec(t *testing.T) {
+	job := &models.Job{Name: "nightly-backup", Config: json.RawMessage(`{"kind":"HeartbeatCheck","spec":{"schedule":"0 3 * * *","grace":"30m"}}`)}
+	spec, err := heartbeatSpec(job)
+	if err != nil || spec.Schedule != "0 3 * * *" || spec.Grace != "30m" {
+		t.Errorf("heartbeatSpec = %+v, %v", spec, err)
+	}
+
+	for _, config := range []string{`{"spec":`, `{"spec":{"period":60}}`} {
+		job.Config = json.RawMessage(config)
+		if _, err := heartbeatSpec(job); err == nil {
+			t.Errorf("heartbeatSpec with %s succeeded, want an error", config)
+		}
+	}
+}
+
+func TestNewHeartbeatToken(t *testing.T) {
+	first, err := newHeartbeatToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := newHeartbeatToken()
+	if decoded, err := hex.DecodeString(first); err != nil || len(decoded) != 16 {
+		t.Errorf("token = %q, want 32 hex digits", first)
+	}
+	if first == second {
+		t.Error("tokens repeat")
+	}
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/itskarma/moogie/api/internal/models"
+	"github.com/itskarma/moogie/api/internal/specs"
 	"gorm.io/gorm"
 )
 
@@ -84,7 +86,197 @@ func (s *JobService) GetJobByName(name string) (*models.Job, error) {
 	return &job, nil
 }
 
-// computeJobMetrics calculates success rate, last execution, and avg response time
+// CreateJob validates and stores a new job
+func (s *JobService) CreateJob(req *models.CreateJobRequest) (*models.Job, error) {
+	job := &models.Job{
+		Name:    strings.TrimSpace(req.Name),
+		Type:    req.Type,
+		Config:  req.Config,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+
+	if err := validateJob(job); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureNameAvailable(job.Name, 0); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		// GORM skips zero values for columns with a default, so a disabled
+		// job has to be written explicitly
+		if !job.Enabled {
+			if err := tx.Model(job).Update("enabled", false).Error; err != nil {
+				return err
+			}
+		}
+		// The token is only returned here and from GET /jobs/:id/heartbeat
+		heartbeat, err := syncHeartbeat(tx, job)
+		job.Heartbeat = heartbeat
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return job, nil
+}
+
+// UpdateJob replaces the name, type, config and enabled flag of an existing job
+func (s *JobService) UpdateJob(id uint, req *models.UpdateJobRequest) (*models.Job, error) {
+	job, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Name = strings.TrimSpace(req.Name)
+	job.Type = req.Type
+	job.Config = req.Config
+	job.Enabled = req.Enabled == nil || *req.Enabled
+
+	if err := validateJob(job); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureNameAvailable(job.Name, job.ID); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).
+			Select("name", "type", "config", "enabled").
+			Updates(job).Error; err != nil {
+			return err
+		}
+		_, err := syncHeartbeat(tx, job)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+
+	return job, nil
+}
+
+// PatchJob updates only the fields present in the request
+func (s *JobService) PatchJob(id uint, req *models.PatchJobRequest) (*models.Job, error) {
+	job, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		job.Name = strings.TrimSpace(*req.Name)
+		updates["name"] = job.Name
+	}
+	if req.Type != nil {
+		job.Type = *req.Type
+		updates["type"] = job.Type
+	}
+	if req.Config != nil {
+		job.Config = req.Config
+		updates["config"] = job.Config
+	}
+	if req.Enabled != nil {
+		job.Enabled = *req.Enabled
+		updates["enabled"] = job.Enabled
+	}
+
+	if len(updates) == 0 {
+		return nil, specs.NewValidationError("", "no fields to update")
+	}
+
+	if err := validateJob(job); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if err := s.ensureNameAvailable(job.Name, job.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(updates).Error; err != nil {
+			return err
+		}
+		_, err := syncHeartbeat(tx, job)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+
+	return job, nil
+}
+
+// DeleteJob removes a job and, through the foreign key cascade, its executions.
+// The deleted job is returned so callers can broadcast it.
+func (s *JobService) DeleteJob(id uint) (*models.Job, error) {
+	job, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	return job, nil
+}
+
+// findJob loads a job without executions or computed metrics
+func (s *JobService) findJob(id uint) (*models.Job, error) {
+	var job models.Job
+
+	if err := s.db.First(&job, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("job not found")
+		}
+		return nil, fmt.Errorf("failed to fetch job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ensureNameAvailable checks that no other job uses the given name
+func (s *JobService) ensureNameAvailable(name string, excludeID uint) error {
+	var count int64
+	if err := s.db.Model(&models.Job{}).
+		Where("name = ? AND id <> ?", name, excludeID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check job name: %w", err)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("job name already exists")
+	}
+
+	return nil
+}
+
+// validateJob checks the name, type and config of a job before it is written.
+// The config is replaced with its normalized form, see specs.NormalizeConfig.
+func validateJob(job *models.Job) error {
+	if job.Name == "" {
+		return specs.NewValidationError("name", "is required")
+	}
+
+	config, _, err := specs.NormalizeConfig(job.Type, job.Name, job.Config)
+	if err != nil {
+		return err
+	}
+	job.Config = config
+
+	return nil
+}
+
+// computeJobMetrics calculates success and degraded rates, last execution, and avg response time
 func (s *JobService) computeJobMetrics(job *models.Job, from, to time.Time) error {
 	// Count total executions in date range
 	var totalCount int64
@@ -96,21 +288,29 @@ func (s *JobService) computeJobMetrics(job *models.Job, from, to time.Time) erro
 
 	if totalCount == 0 {
 		job.SuccessRate = 0
+		job.DegradedRate = 0
 		job.AvgResponseTime = 0
 		job.LastExecution = nil
 		return nil
 	}
 
-	// Count successful executions
+	// Count successful executions; degraded checks passed, just slowly
 	var successCount int64
 	if err := s.db.Model(&models.Execution{}).
-		Where("job_id = ? AND status = 'success' AND timestamp BETWEEN ? AND ?", job.ID, from, to).
+		Where("job_id = ? AND status IN ? AND timestamp BETWEEN ? AND ?",
+			job.ID, []string{models.StatusSuccess, models.StatusDegraded}, from, to).
 		Count(&successCount).Error; err != nil {
 		return err
 	}
 
-	// Calculate success rate
-	job.SuccessRate = float64(successCount) / float64(totalCount) * 100
+	var degradedCount int64
+	if err := s.db.Model(&models.Execution{}).
+		Where("job_id = ? AND status = ? AND timestamp BETWEEN ? AND ?", job.ID, models.StatusDegraded, from, to).
+		Count(&degradedCount).Error; err != nil {
+		return err
+	}
+
+	job.SuccessRate, job.DegradedRate = executionRates(totalCount, successCount, degradedCount)
 
 	// Get average response time
 	var avgResponseTime float64
@@ -136,3 +336,12 @@ func (s *JobService) computeJobMetrics(job *models.Job, from, to time.Time) erro
 
 	return nil
 }
+
+// executionRates returns the percentage of executions that passed, degraded
+// ones included, and the percentage that were degraded
+func executionRates(total, passed, degraded int64) (successRate, degradedRate float64) {
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(passed) / float64(total) * 100, float64(degraded) / float64(total) * 100
+}
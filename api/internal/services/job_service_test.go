@@ -0,0 +1,68 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/itskarma/moogie/api/internal/models"
+	"github.com/itskarma/moogie/api/internal/specs"
+)
+
+func TestExecutionRates(t *testing.T) {
+	tests := []struct {
+		name                      string
+		total, passed, degraded   int64
+		wantSuccess, wantDegraded float64
+	}{
+		{"no executions", 0, 0, 0, 0, 0},
+		{"all successful", 4, 4, 0, 100, 0},
+		{"degraded count as passed", 4, 4, 1, 100, 25},
+		{"failures and degraded", 8, 6, 2, 75, 25},
+		{"all degraded", 3, 3, 3, 100, 100},
+		{"all failed", 5, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success, degraded := executionRates(tt.total, tt.passed, tt.degraded)
+			if success != tt.wantSuccess || degraded != tt.wantDegraded {
+				t.Errorf("rates = %v%%, %v%% degraded, want %v%%, %v%% degraded", success, degraded, tt.wantSuccess, tt.wantDegraded)
+			}
+		})
+	}
+}
+
+func TestValidateJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     models.Job
+		wantErr string
+	}{
+		{name: "valid", job: models.Job{Name: "api-health", Type: "http", Config: json.RawMessage(`{"spec":{"schedule":"*/5 * * * *","url":"https://example.com"}}`)}},
+		{name: "missing name", job: models.Job{Type: "http", Config: json.RawMessage(`{}`)}, wantErr: "name: is required"},
+		{name: "unknown type", job: models.Job{Name: "api-health", Type: "gopher", Config: json.RawMessage(`{}`)}, wantErr: "type: must be one of"},
+		{name: "invalid config", job: models.Job{Name: "api-health", Type: "http", Config: json.RawMessage(`{"spec":{"schedule":"* * * * *"}}`)}, wantErr: "config.spec.url: is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := tt.job
+			err := validateJob(&job)
+			if tt.wantErr != "" {
+				if _, ok := err.(*specs.ValidationError); !ok || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want a validation error starting with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateJob: %v", err)
+			}
+			// The stored config is normalized
+			want := `{"apiVersion":"moogie.io/v1","kind":"HttpCheck","metadata":{"name":"api-health"},"spec":{"schedule":"*/5 * * * *","url":"https://example.com"}}`
+			if string(job.Config) != want {
+				t.Errorf("config = %s, want %s", job.Config, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package specs
+
+import (
+	"strings"
+	"time"
+)
+
+// CommonSpec holds the scheduling fields shared by every check kind
+type CommonSpec struct {
+	Schedule     string    `json:"schedule"`
+	Timeout      string    `json:"timeout,omitempty"`
+	Retries      int       `json:"retries,omitempty"`
+	RetryDelay   string    `json:"retryDelay,omitempty"`   // wait before the first retry, default 1s
+	RetryBackoff float64   `json:"retryBackoff,omitempty"` // multiplier applied to the delay after each retry, default 2
+	Latency      Latency   `json:"latency,omitempty"`
+	Alerts       AlertSpec `json:"alerts,omitempty"`
+}
+
+// Latency holds response time thresholds. A passing check slower than Warning
+// is reported as degraded, and one slower than Critical as failed.
+type Latency struct {
+	Warning  string `json:"warning,omitempty"`
+	Critical string `json:"critical,omitempty"`
+}
+
+// AlertSpec configures when and where alerts are sent
+type AlertSpec struct {
+	OnFailure        bool   `json:"onFailure,omitempty"`
+	OnHighLatency    bool   `json:"onHighLatency,omitempty"`
+	OnExpiringSoon   bool   `json:"onExpiringSoon,omitempty"`
+	LatencyThreshold string `json:"latencyThreshold,omitempty"`
+	Email            string `json:"email,omitempty"`
+}
+
+func (c *CommonSpec) validate(errs *errorList) {
+	errs.schedule("schedule", c.Schedule)
+	errs.duration("timeout", c.Timeout)
+	errs.between("retries", c.Retries, 0, 10)
+	errs.duration("retryDelay", c.RetryDelay)
+	if c.RetryBackoff != 0 && (c.RetryBackoff < 1 || c.RetryBackoff > 10) {
+		errs.add("retryBackoff", "must be between 1 and 10")
+	}
+	errs.duration("latency.warning", c.Latency.Warning)
+	errs.duration("latency.critical", c.Latency.Critical)
+	if c.Latency.Warning != "" && c.Latency.Critical != "" {
+		warning, werr := time.ParseDuration(c.Latency.Warning)
+		critical, cerr := time.ParseDuration(c.Latency.Critical)
+		if werr == nil && cerr == nil && warning >= critical {
+			errs.add("latency.critical", "must be greater than latency.warning")
+		}
+	}
+	errs.duration("alerts.latencyThreshold", c.Alerts.LatencyThreshold)
+	if c.Alerts.Email != "" && !strings.Contains(c.Alerts.Email, "@") {
+		errs.add("alerts.email", "must be an email address")
+	}
+}
@@ -0,0 +1,73 @@
+package specs
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func init() {
+	Register(CheckKind{Type: "database", Kind: "DatabaseCheck", New: func() Spec { return &DatabaseSpec{} }})
+}
+
+// DatabaseSpec configures a DatabaseCheck, which logs in to PostgreSQL, MySQL
+// or Redis, runs a query and asserts on the first row
+type DatabaseSpec struct {
+	CommonSpec
+	Engine             string              `json:"engine"` // postgres, mysql or redis
+	Host               string              `json:"host"`
+	Port               int                 `json:"port,omitempty"` // default: 5432, 3306 or 6379
+	Username           string              `json:"username,omitempty"`
+	Password           string              `json:"password,omitempty"`
+	PasswordEnv        string              `json:"passwordEnv,omitempty"` // runner environment variable holding the password
+	Database           string              `json:"database,omitempty"`    // database name, or the database number for redis
+	TLS                bool                `json:"tls,omitempty"`
+	InsecureSkipVerify bool                `json:"insecureSkipVerify,omitempty"`
+	Query              string              `json:"query,omitempty"` // default: SELECT 1, or PING for redis
+	Assertions         []DatabaseAssertion `json:"assertions,omitempty"`
+}
+
+// DatabaseAssertion compares a column of the first row with a value. Values
+// that are both numbers are compared numerically.
+type DatabaseAssertion struct {
+	Column   string `json:"column,omitempty"` // default: the first column
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+func (s *DatabaseSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	if errs.required("engine", s.Engine) {
+		errs.oneOf("engine", s.Engine, "postgres", "mysql", "redis")
+	}
+	errs.host("host", s.Host)
+	errs.port("port", s.Port, false)
+	if s.Engine != "redis" {
+		errs.required("username", s.Username)
+	}
+	if s.Password != "" && s.PasswordEnv != "" {
+		errs.add("passwordEnv", "cannot be set together with password")
+	}
+	if s.Engine == "redis" && s.Database != "" {
+		if n, err := strconv.Atoi(s.Database); err != nil || n < 0 {
+			errs.add("database", "must be a database number for redis")
+		}
+	}
+	if s.InsecureSkipVerify && !s.TLS {
+		errs.add("insecureSkipVerify", "requires tls")
+	}
+	for i, assertion := range s.Assertions {
+		field := fmt.Sprintf("assertions[%d]", i)
+		if !errs.required(field+".operator", assertion.Operator) {
+			continue
+		}
+		errs.oneOf(field+".operator", assertion.Operator, "==", "!=", "<", "<=", ">", ">=", "contains")
+		switch assertion.Operator {
+		case "<", "<=", ">", ">=":
+			if _, err := strconv.ParseFloat(assertion.Value, 64); err != nil {
+				errs.add(field+".value", "must be a number for %s", assertion.Operator)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,104 @@
+package specs
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(CheckKind{Type: "dns", Kind: "DnsCheck", New: func() Spec { return &DNSSpec{} }})
+}
+
+// DNSSpec configures a DnsCheck
+type DNSSpec struct {
+	CommonSpec
+	Domain         string   `json:"domain"`
+	RecordType     string   `json:"recordType,omitempty"` // default: A
+	ExpectedIP     string   `json:"expectedIp,omitempty"`
+	ExpectedIPs    []string `json:"expectedIps,omitempty"`
+	ExpectedValues []string `json:"expectedValues,omitempty"` // each must match at least one record
+	Match          string   `json:"match,omitempty"`          // exact (default), contains or regex
+	Nameserver     string   `json:"nameserver,omitempty"`     // e.g. "8.8.8.8" or "8.8.8.8:53"
+
+	Consistency *DNSConsistency `json:"consistency,omitempty"`
+	DNSSEC      *DNSSEC         `json:"dnssec,omitempty"`
+}
+
+// DNSConsistency compares the answers and SOA serials of several nameservers
+type DNSConsistency struct {
+	Nameservers   []string `json:"nameservers,omitempty"`
+	Authoritative bool     `json:"authoritative,omitempty"` // compare every NS listed for the zone
+	Zone          string   `json:"zone,omitempty"`          // default: the zone containing the domain
+}
+
+// DNSSEC validates the answer's signatures up to a trust anchor
+type DNSSEC struct {
+	TrustAnchors  []string `json:"trustAnchors,omitempty"`  // DS or DNSKEY records, default: the root zone KSKs
+	ExpiryWarning string   `json:"expiryWarning,omitempty"` // fail when a signature expires within this duration, default 24h
+}
+
+func (s *DNSSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.host("domain", s.Domain)
+	errs.oneOf("recordType", s.RecordType, "A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "SOA")
+	errs.ip("expectedIp", s.ExpectedIP)
+	for i, ip := range s.ExpectedIPs {
+		errs.ip(fmt.Sprintf("expectedIps[%d]", i), ip)
+	}
+	if (s.ExpectedIP != "" || len(s.ExpectedIPs) > 0) && s.RecordType != "" && s.RecordType != "A" && s.RecordType != "AAAA" {
+		errs.add("recordType", "must be A or AAAA when expectedIp or expectedIps is set")
+	}
+	errs.oneOf("match", s.Match, "exact", "contains", "regex")
+	if s.Match == "regex" {
+		for i, pattern := range s.ExpectedValues {
+			errs.regexp(fmt.Sprintf("expectedValues[%d]", i), pattern)
+		}
+	}
+	if s.Nameserver != "" {
+		errs.host("nameserver", s.Nameserver)
+	}
+	if s.Consistency != nil {
+		s.Consistency.validate(&errs)
+	}
+	if s.DNSSEC != nil {
+		if s.Consistency != nil {
+			errs.add("dnssec", "cannot be combined with consistency")
+		}
+		s.DNSSEC.validate(&errs)
+	}
+	return errs
+}
+
+func (c *DNSConsistency) validate(errs *errorList) {
+	if !c.Authoritative && len(c.Nameservers) < 2 {
+		errs.add("consistency.nameservers", "must list at least two nameservers unless authoritative is set")
+	}
+	for i, server := range c.Nameservers {
+		errs.host(fmt.Sprintf("consistency.nameservers[%d]", i), server)
+	}
+	if c.Zone != "" {
+		errs.host("consistency.zone", c.Zone)
+	}
+}
+
+func (d *DNSSEC) validate(errs *errorList) {
+	for i, anchor := range d.TrustAnchors {
+		if !isTrustAnchor(anchor) {
+			errs.add(fmt.Sprintf("dnssec.trustAnchors[%d]", i), "must be a DS or DNSKEY record, e.g. \". IN DS 20326 8 2 E06D...\"")
+		}
+	}
+	errs.duration("dnssec.expiryWarning", d.ExpiryWarning)
+}
+
+// isTrustAnchor reports whether a record in zone file format is a DS or DNSKEY record
+func isTrustAnchor(record string) bool {
+	fields := strings.Fields(record)
+	for i, field := range fields {
+		if field == "DS" || field == "DNSKEY" {
+			// Owner name and type must be followed by the record data
+			return i > 0 && len(fields) > i+3
+		}
+	}
+	return false
+}
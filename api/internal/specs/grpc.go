@@ -0,0 +1,41 @@
+package specs
+
+func init() {
+	Register(CheckKind{Type: "grpc", Kind: "GrpcCheck", New: func() Spec { return &GRPCSpec{} }})
+}
+
+// GRPCSpec configures a GrpcCheck, which calls grpc.health.v1.Health/Check
+type GRPCSpec struct {
+	CommonSpec
+	Host               string            `json:"host"`
+	Port               int               `json:"port"`
+	Service            string            `json:"service,omitempty"` // default: the server as a whole
+	TLS                bool              `json:"tls,omitempty"`     // default: plaintext
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty"`
+	CAFile             string            `json:"caFile,omitempty"`         // PEM bundle on the runner, default: system roots
+	Metadata           map[string]string `json:"metadata,omitempty"`       // request metadata, e.g. authorization
+	ExpectedStatus     string            `json:"expectedStatus,omitempty"` // default: SERVING
+	Reflection         bool              `json:"reflection,omitempty"`     // list the server's services
+}
+
+func (s *GRPCSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.host("host", s.Host)
+	errs.port("port", s.Port, true)
+	errs.oneOf("expectedStatus", s.ExpectedStatus, "SERVING", "NOT_SERVING", "SERVICE_UNKNOWN")
+	if !s.TLS {
+		if s.InsecureSkipVerify {
+			errs.add("insecureSkipVerify", "requires tls")
+		}
+		if s.CAFile != "" {
+			errs.add("caFile", "requires tls")
+		}
+	}
+	for key := range s.Metadata {
+		if key == "" {
+			errs.add("metadata", "keys must not be empty")
+		}
+	}
+	return errs
+}
@@ -0,0 +1,69 @@
+package specs
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func init() {
+	Register(CheckKind{Type: "heartbeat", Kind: "HeartbeatCheck", New: func() Spec { return &HeartbeatSpec{} }})
+}
+
+// DefaultHeartbeatGrace is how late a ping may arrive when grace is not set
+const DefaultHeartbeatGrace = 5 * time.Minute
+
+// HeartbeatSpec configures a HeartbeatCheck. The runner does not run these:
+// the monitored job pings POST /api/v1/heartbeats/:token itself, and the API
+// records a failure when no ping arrives before the deadline.
+type HeartbeatSpec struct {
+	Schedule string `json:"schedule,omitempty"` // cron expression the job runs on
+	Period   string `json:"period,omitempty"`   // interval between pings, instead of a schedule
+	Grace    string `json:"grace,omitempty"`    // default: 5m
+}
+
+func (s *HeartbeatSpec) Validate() []FieldError {
+	var errs errorList
+	switch {
+	case s.Schedule == "" && s.Period == "":
+		errs.add("schedule", "is required when period is not set")
+	case s.Schedule != "" && s.Period != "":
+		errs.add("period", "cannot be set together with schedule")
+	case s.Schedule != "":
+		errs.schedule("schedule", s.Schedule)
+	default:
+		errs.duration("period", s.Period)
+		if period, err := time.ParseDuration(s.Period); err == nil && period > 0 && period < time.Minute {
+			errs.add("period", "must be at least 1m")
+		}
+	}
+	errs.duration("grace", s.Grace)
+	return errs
+}
+
+// Deadline returns the time by which the ping following one at last must
+// arrive: the next scheduled run, or last plus the period, plus the grace
+func (s *HeartbeatSpec) Deadline(last time.Time) (time.Time, error) {
+	grace := DefaultHeartbeatGrace
+	if s.Grace != "" {
+		d, err := time.ParseDuration(s.Grace)
+		if err != nil {
+			return time.Time{}, err
+		}
+		grace = d
+	}
+
+	if s.Schedule != "" {
+		schedule, err := cron.ParseStandard(s.Schedule)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return schedule.Next(last).Add(grace), nil
+	}
+
+	period, err := time.ParseDuration(s.Period)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return last.Add(period).Add(grace), nil
+}
@@ -0,0 +1,58 @@
+package specs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatSpecValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec HeartbeatSpec
+		want []FieldError
+	}{
+		{name: "schedule", spec: HeartbeatSpec{Schedule: "0 3 * * *", Grace: "30m"}},
+		{name: "period", spec: HeartbeatSpec{Period: "1h"}},
+		{name: "neither", spec: HeartbeatSpec{}, want: []FieldError{{"schedule", "is required when period is not set"}}},
+		{name: "both", spec: HeartbeatSpec{Schedule: "0 3 * * *", Period: "1h"}, want: []FieldError{{"period", "cannot be set together with schedule"}}},
+		{name: "bad schedule", spec: HeartbeatSpec{Schedule: "nightly"}, want: []FieldError{{"schedule", `must be a cron expression such as "*/5 * * * *"`}}},
+		{name: "short period", spec: HeartbeatSpec{Period: "30s"}, want: []FieldError{{"period", "must be at least 1m"}}},
+		{name: "bad grace", spec: HeartbeatSpec{Period: "1h", Grace: "soon"}, want: []FieldError{{"grace", `must be a duration such as "30s" or "500ms"`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.Validate(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeartbeatSpecDeadline(t *testing.T) {
+	last := time.Date(2026, 3, 10, 3, 2, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		spec HeartbeatSpec
+		want time.Time
+	}{
+		{name: "schedule with default grace", spec: HeartbeatSpec{Schedule: "0 3 * * *"}, want: time.Date(2026, 3, 11, 3, 5, 0, 0, time.UTC)},
+		{name: "schedule with grace", spec: HeartbeatSpec{Schedule: "*/15 * * * *", Grace: "2m"}, want: time.Date(2026, 3, 10, 3, 17, 0, 0, time.UTC)},
+		{name: "period", spec: HeartbeatSpec{Period: "1h", Grace: "10m"}, want: time.Date(2026, 3, 10, 4, 12, 0, 0, time.UTC)},
+		{name: "period with default grace", spec: HeartbeatSpec{Period: "24h"}, want: time.Date(2026, 3, 11, 3, 7, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.spec.Deadline(last)
+			if err != nil || !got.Equal(tt.want) {
+				t.Errorf("Deadline() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	for _, spec := range []HeartbeatSpec{{Schedule: "nightly"}, {Period: "hourly"}, {Period: "1h", Grace: "soon"}} {
+		if _, err := spec.Deadline(last); err == nil {
+			t.Errorf("Deadline() with %+v succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	Register(CheckKind{Type: "http", Kind: "HttpCheck", New: func() Spec { return &HTTPSpec{} }})
+}
+
+// HTTPSpec configures an HttpCheck
+type HTTPSpec struct {
+	CommonSpec
+	URL                string            `json:"url"`
+	Method             string            `json:"method,omitempty"`             // default: GET
+	ExpectedStatusCode int               `json:"expectedStatusCode,omitempty"` // default: 200
+	Headers            map[string]string `json:"headers,omitempty"`
+	Body               string            `json:"body,omitempty"`
+	Assertions         *HTTPAssertions   `json:"assertions,omitempty"`
+}
+
+// HTTPAssertions are checks made against the response body
+type HTTPAssertions struct {
+	Contains    []string        `json:"contains,omitempty"`
+	NotContains []string        `json:"notContains,omitempty"`
+	Matches     []string        `json:"matches,omitempty"`    // regular expressions
+	NotMatches  []string        `json:"notMatches,omitempty"` // regular expressions
+	JSON        []JSONAssertion `json:"json,omitempty"`
+	MaxBodySize int64           `json:"maxBodySize,omitempty"` // bytes
+}
+
+// JSONAssertion checks the value at a JSONPath such as "$.data.items[0].id".
+// Exactly one of Equals and Exists is set.
+type JSONAssertion struct {
+	Path   string          `json:"path"`
+	Equals json.RawMessage `json:"equals,omitempty"`
+	Exists *bool           `json:"exists,omitempty"`
+}
+
+func (s *HTTPSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.httpURL("url", s.URL)
+	errs.oneOf("method", s.Method,
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions)
+	if s.ExpectedStatusCode != 0 {
+		errs.between("expectedStatusCode", s.ExpectedStatusCode, 100, 599)
+	}
+	if s.Assertions != nil {
+		s.Assertions.validate(&errs)
+	}
+	return errs
+}
+
+func (a *HTTPAssertions) validate(errs *errorList) {
+	for i, pattern := range a.Matches {
+		errs.regexp(fmt.Sprintf("assertions.matches[%d]", i), pattern)
+	}
+	for i, pattern := range a.NotMatches {
+		errs.regexp(fmt.Sprintf("assertions.notMatches[%d]", i), pattern)
+	}
+	for i, assertion := range a.JSON {
+		assertion.validate(errs, fmt.Sprintf("assertions.json[%d]", i))
+	}
+	if a.MaxBodySize < 0 {
+		errs.add("assertions.maxBodySize", "must not be negative")
+	}
+}
+
+func (j JSONAssertion) validate(errs *errorList, field string) {
+	errs.jsonPath(field+".path", j.Path)
+	if (len(j.Equals) > 0) == (j.Exists != nil) {
+		errs.add(field, "must set exactly one of equals or exists")
+	}
+}
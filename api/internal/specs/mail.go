@@ -0,0 +1,65 @@
+package specs
+
+import "strings"
+
+func init() {
+	Register(CheckKind{Type: "mail", Kind: "MailCheck", New: func() Spec { return &MailSpec{} }})
+}
+
+// MailSpec configures a MailCheck, which sends a tagged message over SMTP and
+// polls the recipient's IMAP or POP3 mailbox until it arrives
+type MailSpec struct {
+	CommonSpec
+	SMTP         MailServer  `json:"smtp"`
+	From         string      `json:"from"`
+	To           string      `json:"to"`
+	Mailbox      MailboxSpec `json:"mailbox"`
+	PollInterval string      `json:"pollInterval,omitempty"` // default: 5s
+	Keep         bool        `json:"keep,omitempty"`         // leave the message in the mailbox
+}
+
+// MailServer is a server the check logs in to
+type MailServer struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port,omitempty"`     // default: depends on the protocol and security
+	Security           string `json:"security,omitempty"` // starttls, tls or none; default: starttls for smtp, tls for mailboxes
+	Username           string `json:"username,omitempty"`
+	Password           string `json:"password,omitempty"`
+	PasswordEnv        string `json:"passwordEnv,omitempty"` // runner environment variable holding the password
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// MailboxSpec is the IMAP or POP3 mailbox the message is delivered to
+type MailboxSpec struct {
+	MailServer
+	Protocol string `json:"protocol,omitempty"` // imap (default) or pop3
+	Folder   string `json:"folder,omitempty"`   // IMAP folder, default: INBOX
+}
+
+func (s *MailSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	s.SMTP.validate(&errs, "smtp")
+	for _, field := range []struct{ name, value string }{{"from", s.From}, {"to", s.To}} {
+		if errs.required(field.name, field.value) && !strings.Contains(field.value, "@") {
+			errs.add(field.name, "must be an email address")
+		}
+	}
+	s.Mailbox.validate(&errs, "mailbox")
+	errs.required("mailbox.username", s.Mailbox.Username)
+	errs.oneOf("mailbox.protocol", s.Mailbox.Protocol, "imap", "pop3")
+	if s.Mailbox.Protocol == "pop3" && s.Mailbox.Folder != "" {
+		errs.add("mailbox.folder", "is only supported for imap")
+	}
+	errs.duration("pollInterval", s.PollInterval)
+	return errs
+}
+
+func (m *MailServer) validate(errs *errorList, prefix string) {
+	errs.host(prefix+".host", m.Host)
+	errs.port(prefix+".port", m.Port, false)
+	errs.oneOf(prefix+".security", m.Security, "starttls", "tls", "none")
+	if m.Password != "" && m.PasswordEnv != "" {
+		errs.add(prefix+".passwordEnv", "cannot be set together with password")
+	}
+}
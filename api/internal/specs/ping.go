@@ -0,0 +1,28 @@
+package specs
+
+func init() {
+	Register(CheckKind{Type: "ping", Kind: "PingCheck", New: func() Spec { return &PingSpec{} }})
+}
+
+// PingSpec configures a PingCheck
+type PingSpec struct {
+	CommonSpec
+	Host                string  `json:"host"`
+	Count               int     `json:"count,omitempty"`    // default: 4
+	Interval            string  `json:"interval,omitempty"` // default: 1s
+	PacketLossThreshold float64 `json:"packetLossThreshold,omitempty"`
+}
+
+func (s *PingSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.host("host", s.Host)
+	if s.Count != 0 {
+		errs.between("count", s.Count, 1, 100)
+	}
+	errs.duration("interval", s.Interval)
+	if s.PacketLossThreshold < 0 || s.PacketLossThreshold > 100 {
+		errs.add("packetLossThreshold", "must be between 0 and 100")
+	}
+	return errs
+}
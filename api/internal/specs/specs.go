@@ -0,0 +1,175 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// APIVersion is the apiVersion of every check config
+const APIVersion = "moogie.io/v1"
+
+// Spec is the typed, kind-specific part of a check config
+type Spec interface {
+	// Validate returns one error per invalid field, using paths relative to the spec
+	Validate() []FieldError
+}
+
+// CheckKind registers a job type together with its manifest kind and spec type
+type CheckKind struct {
+	Type string      // job type, e.g. "http"
+	Kind string      // manifest kind, e.g. "HttpCheck"
+	New  func() Spec // returns a pointer to an empty spec
+}
+
+var (
+	kindsByType = make(map[string]CheckKind)
+	kindsByKind = make(map[string]CheckKind)
+)
+
+// Register adds a check kind to the registry
+func Register(k CheckKind) {
+	kindsByType[k.Type] = k
+	kindsByKind[k.Kind] = k
+}
+
+// Lookup returns the check kind registered for a job type
+func Lookup(jobType string) (CheckKind, bool) {
+	k, ok := kindsByType[jobType]
+	return k, ok
+}
+
+// LookupKind returns the check kind registered for a manifest kind
+func LookupKind(kind string) (CheckKind, bool) {
+	k, ok := kindsByKind[kind]
+	return k, ok
+}
+
+// Types returns the registered job types in alphabetical order
+func Types() []string {
+	types := make([]string, 0, len(kindsByType))
+	for t := range kindsByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Document is the stored layout of Job.Config, the same shape as a config/checks manifest
+type Document struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Metadata   Metadata        `json:"metadata"`
+	Spec       json.RawMessage `json:"spec"`
+}
+
+// Metadata holds the identifying fields of a check config
+type Metadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ParseDocument decodes a job config without validating it
+func ParseDocument(config []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(config, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// NormalizeConfig validates a job config against the spec registered for jobType and
+// returns it with apiVersion, kind and metadata.name filled in. metadata.name always
+// mirrors the job name. Invalid configs return a *ValidationError.
+func NormalizeConfig(jobType, name string, config []byte) (json.RawMessage, Spec, error) {
+	k, ok := Lookup(jobType)
+	if !ok {
+		return nil, nil, NewValidationError("type", "must be one of "+strings.Join(Types(), ", "))
+	}
+
+	var doc Document
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, NewValidationError("config", describeDecodeError(err))
+	}
+
+	var errs errorList
+	if doc.APIVersion == "" {
+		doc.APIVersion = APIVersion
+	} else if doc.APIVersion != APIVersion {
+		errs.add("config.apiVersion", "must be %q", APIVersion)
+	}
+
+	if doc.Kind == "" {
+		doc.Kind = k.Kind
+	} else if doc.Kind != k.Kind {
+		errs.add("config.kind", "must be %q for type %q", k.Kind, jobType)
+	}
+
+	doc.Metadata.Name = name
+
+	spec := k.New()
+	if len(doc.Spec) == 0 || string(doc.Spec) == "null" {
+		errs.add("config.spec", "is required")
+	} else {
+		specDecoder := json.NewDecoder(bytes.NewReader(doc.Spec))
+		specDecoder.DisallowUnknownFields()
+		if err := specDecoder.Decode(spec); err != nil {
+			errs.add("config.spec", "%s", describeDecodeError(err))
+		} else {
+			for _, fieldErr := range spec.Validate() {
+				errs.add("config.spec."+fieldErr.Field, "%s", fieldErr.Message)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, &ValidationError{Errors: errs}
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return normalized, spec, nil
+}
+
+// describeDecodeError turns encoding/json errors into messages that name the offending field
+func describeDecodeError(err error) string {
+	switch e := err.(type) {
+	case *json.UnmarshalTypeError:
+		return fmt.Sprintf("field %q must be a %s", e.Field, jsonTypeName(e.Type.Kind().String()))
+	case *json.SyntaxError:
+		return fmt.Sprintf("invalid JSON at offset %d", e.Offset)
+	default:
+		// Unknown fields are reported as `json: unknown field "x"`
+		return trimJSONPrefix(err.Error())
+	}
+}
+
+func jsonTypeName(goKind string) string {
+	switch goKind {
+	case "int", "int64", "uint16", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "slice":
+		return "list"
+	case "map", "struct":
+		return "object"
+	default:
+		return goKind
+	}
+}
+
+func trimJSONPrefix(message string) string {
+	const prefix = "json: "
+	if len(message) > len(prefix) && message[:len(prefix)] == prefix {
+		return message[len(prefix):]
+	}
+	return message
+}
@@ -0,0 +1,156 @@
+package specs
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		jobType string
+		config  string
+		want    string
+		errs    map[string]string
+	}{
+		{
+			name:    "fills in the envelope",
+			jobType: "http",
+			config:  `{"spec":{"schedule":"*/5 * * * *","url":"https://example.com/health"}}`,
+			want:    `{"apiVersion":"moogie.io/v1","kind":"HttpCheck","metadata":{"name":"api-health"},"spec":{"schedule":"*/5 * * * *","url":"https://example.com/health"}}`,
+		},
+		{
+			name:    "metadata.name follows the job name",
+			jobType: "ping",
+			config:  `{"apiVersion":"moogie.io/v1","kind":"PingCheck","metadata":{"name":"old-name","labels":{"team":"infra"}},"spec":{"schedule":"* * * * *","host":"10.0.0.1"}}`,
+			want:    `{"apiVersion":"moogie.io/v1","kind":"PingCheck","metadata":{"name":"api-health","labels":{"team":"infra"}},"spec":{"schedule":"* * * * *","host":"10.0.0.1"}}`,
+		},
+		{
+			name:    "unknown type",
+			jobType: "ftp",
+			config:  `{}`,
+			errs:    map[string]string{"type": "must be one of " + strings.Join(Types(), ", ")},
+		},
+		{
+			name:    "invalid JSON",
+			jobType: "http",
+			config:  `{"spec":`,
+			errs:    map[string]string{"config": "unexpected EOF"},
+		},
+		{
+			name:    "unknown envelope field",
+			jobType: "http",
+			config:  `{"specs":{}}`,
+			errs:    map[string]string{"config": `unknown field "specs"`},
+		},
+		{
+			name:    "wrong apiVersion and kind",
+			jobType: "http",
+			config:  `{"apiVersion":"v1","kind":"PingCheck","spec":{"schedule":"* * * * *","url":"https://example.com"}}`,
+			errs: map[string]string{
+				"config.apiVersion": `must be "moogie.io/v1"`,
+				"config.kind":       `must be "HttpCheck" for type "http"`,
+			},
+		},
+		{
+			name:    "missing spec",
+			jobType: "http",
+			config:  `{"spec":null}`,
+			errs:    map[string]string{"config.spec": "is required"},
+		},
+		{
+			name:    "wrong field type",
+			jobType: "http",
+			config:  `{"spec":{"schedule":"* * * * *","url":"https://example.com","expectedStatusCode":"200"}}`,
+			errs:    map[string]string{"config.spec": `field "expectedStatusCode" must be a number`},
+		},
+		{
+			name:    "unknown spec field",
+			jobType: "http",
+			config:  `{"spec":{"schedule":"* * * * *","url":"https://example.com","verb":"GET"}}`,
+			errs:    map[string]string{"config.spec": `unknown field "verb"`},
+		},
+		{
+			name:    "invalid spec fields",
+			jobType: "http",
+			config:  `{"spec":{"schedule":"every minute","url":"example.com","method":"FETCH","timeout":"-1s"}}`,
+			errs: map[string]string{
+				"config.spec.schedule": `must be a cron expression such as "*/5 * * * *"`,
+				"config.spec.timeout":  "must be greater than zero",
+				"config.spec.url":      "must be an absolute http or https URL",
+				"config.spec.method":   "must be one of GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, spec, err := NormalizeConfig(tt.jobType, "api-health", []byte(tt.config))
+			if tt.errs != nil {
+				validationErr, ok := err.(*ValidationError)
+				if !ok {
+					t.Fatalf("error = %v, want a *ValidationError", err)
+				}
+				if details := validationErr.Details(); !reflect.DeepEqual(details, tt.errs) {
+					t.Errorf("details = %v, want %v", details, tt.errs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeConfig: %v", err)
+			}
+			if string(config) != tt.want {
+				t.Errorf("config = %s, want %s", config, tt.want)
+			}
+			if k, _ := Lookup(tt.jobType); reflect.TypeOf(spec) != reflect.TypeOf(k.New()) {
+				t.Errorf("spec = %T, want %T", spec, k.New())
+			}
+		})
+	}
+}
+
+func TestNormalizeConfigDecodesSpec(t *testing.T) {
+	config := `{"spec":{"schedule":"* * * * *","url":"https://example.com","retries":2,"assertions":{"json":[{"path":"$.status","equals":"ok"}]}}}`
+	_, spec, err := NormalizeConfig("http", "api-health", []byte(config))
+	if err != nil {
+		t.Fatalf("NormalizeConfig: %v", err)
+	}
+	httpSpec := spec.(*HTTPSpec)
+	if httpSpec.Retries != 2 || httpSpec.Assertions == nil || len(httpSpec.Assertions.JSON) != 1 {
+		t.Fatalf("spec = %+v", httpSpec)
+	}
+	if equals := string(httpSpec.Assertions.JSON[0].Equals); equals != `"ok"` {
+		t.Errorf("equals = %s", equals)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{Errors: []FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "config.spec.url", Message: "must be an absolute http or https URL"},
+		{Message: "manifest is empty"},
+	}}
+
+	want := "name: is required; config.spec.url: must be an absolute http or https URL; manifest is empty"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	details := err.Details()
+	wantDetails := map[string]string{
+		"name":            "is required",
+		"config.spec.url": "must be an absolute http or https URL",
+		"":                "manifest is empty",
+	}
+	if !reflect.DeepEqual(details, wantDetails) {
+		t.Errorf("Details() = %v, want %v", details, wantDetails)
+	}
+
+	// Details are returned as the body of 400 responses
+	body, _ := json.Marshal(NewValidationError("type", "must be one of http, ping").Details())
+	if string(body) != `{"type":"must be one of http, ping"}` {
+		t.Errorf("body = %s", body)
+	}
+}
@@ -0,0 +1,59 @@
+package specs
+
+import "strings"
+
+func init() {
+	Register(CheckKind{Type: "ssl", Kind: "SslCheck", New: func() Spec { return &SSLSpec{} }})
+}
+
+// SSLSpec configures an SslCheck
+type SSLSpec struct {
+	CommonSpec
+	Host             string     `json:"host"`
+	Port             int        `json:"port,omitempty"`             // default: 443, or the starttls protocol's port
+	DaysBeforeExpiry int        `json:"daysBeforeExpiry,omitempty"` // default: 30
+	CheckChain       *bool      `json:"checkChain,omitempty"`       // default: true
+	CheckHostname    *bool      `json:"checkHostname,omitempty"`    // default: true
+	CAFile           string     `json:"caFile,omitempty"`           // PEM bundle on the runner, default: system roots
+	CABundle         string     `json:"caBundle,omitempty"`         // PEM certificates, as an alternative to caFile
+	OCSP             *OCSP      `json:"ocsp,omitempty"`
+	Policy           *TLSPolicy `json:"policy,omitempty"`
+	StartTLS         string     `json:"starttls,omitempty"` // smtp, imap, pop3, ftp, ldap or postgres
+}
+
+// OCSP configures revocation checking. A stapled response is used when the
+// server sends one; otherwise the responder is queried.
+type OCSP struct {
+	Responder       string `json:"responder,omitempty"` // default: the certificate's OCSP URL
+	RequireStapling bool   `json:"requireStapling,omitempty"`
+}
+
+// TLSPolicy enables probing of the protocol versions and cipher suites a
+// server accepts, and the rules they must meet
+type TLSPolicy struct {
+	MinVersion    string   `json:"minVersion,omitempty"`    // 1.0, 1.1, 1.2 or 1.3
+	BannedCiphers []string `json:"bannedCiphers,omitempty"` // suite names or fragments such as "CBC"
+	RequiredALPN  []string `json:"requiredAlpn,omitempty"`  // e.g. "h2"
+}
+
+func (s *SSLSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.host("host", s.Host)
+	errs.port("port", s.Port, false)
+	errs.between("daysBeforeExpiry", s.DaysBeforeExpiry, 0, 3650)
+	errs.oneOf("starttls", s.StartTLS, "smtp", "imap", "pop3", "ftp", "ldap", "postgres")
+	if s.CAFile != "" && s.CABundle != "" {
+		errs.add("caBundle", "cannot be combined with caFile")
+	}
+	if s.CABundle != "" && !strings.Contains(s.CABundle, "-----BEGIN CERTIFICATE-----") {
+		errs.add("caBundle", "must contain PEM encoded certificates")
+	}
+	if s.OCSP != nil && s.OCSP.Responder != "" {
+		errs.httpURL("ocsp.responder", s.OCSP.Responder)
+	}
+	if s.Policy != nil {
+		errs.oneOf("policy.minVersion", s.Policy.MinVersion, "1.0", "1.1", "1.2", "1.3")
+	}
+	return errs
+}
@@ -0,0 +1,56 @@
+package specs
+
+import "encoding/hex"
+
+func init() {
+	Register(CheckKind{Type: "tcp", Kind: "TcpCheck", New: func() Spec { return &TCPSpec{} }})
+}
+
+// TCPSpec configures a TcpCheck
+type TCPSpec struct {
+	CommonSpec
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	Exchange
+}
+
+// Exchange is an optional payload sent to the server and the response
+// expected back, shared by TcpCheck, UdpCheck and WebSocketCheck
+type Exchange struct {
+	Send        string `json:"send,omitempty"`        // payload sent to the server
+	SendHex     string `json:"sendHex,omitempty"`     // hex-encoded payload, as an alternative to send
+	Expect      string `json:"expect,omitempty"`      // expected response
+	Match       string `json:"match,omitempty"`       // contains (default), regex or hex
+	ReadTimeout string `json:"readTimeout,omitempty"` // default: 5s
+}
+
+func (s *TCPSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.host("host", s.Host)
+	errs.port("port", s.Port, true)
+	s.Exchange.validate(&errs)
+	return errs
+}
+
+func (e *Exchange) validate(errs *errorList) {
+	if e.Send != "" && e.SendHex != "" {
+		errs.add("sendHex", "cannot be combined with send")
+	}
+	if _, err := hex.DecodeString(e.SendHex); err != nil {
+		errs.add("sendHex", "must be hex-encoded bytes")
+	}
+	errs.oneOf("match", e.Match, "contains", "regex", "hex")
+	if e.Match != "" && e.Expect == "" {
+		errs.add("expect", "is required when match is set")
+	}
+	switch e.Match {
+	case "regex":
+		errs.regexp("expect", e.Expect)
+	case "hex":
+		if _, err := hex.DecodeString(e.Expect); err != nil {
+			errs.add("expect", "must be hex-encoded bytes")
+		}
+	}
+	errs.duration("readTimeout", e.ReadTimeout)
+}
@@ -0,0 +1,99 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	Register(CheckKind{Type: "transaction", Kind: "TransactionCheck", New: func() Spec { return &TransactionSpec{} }})
+}
+
+// TransactionSpec configures a TransactionCheck: an ordered list of HTTP
+// steps sharing cookies and variables. URLs, header values and bodies may
+// reference variables as {{name}}.
+type TransactionSpec struct {
+	CommonSpec
+	Variables map[string]string `json:"variables,omitempty"` // initial variables, e.g. a base URL
+	Steps     []TransactionStep `json:"steps"`
+}
+
+// TransactionStep is one request of a TransactionCheck
+type TransactionStep struct {
+	Name               string            `json:"name,omitempty"`   // default: "step N"
+	Method             string            `json:"method,omitempty"` // default: GET
+	URL                string            `json:"url"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	Body               string            `json:"body,omitempty"`
+	ExpectedStatusCode int               `json:"expectedStatusCode,omitempty"` // default: 200
+	Assertions         *HTTPAssertions   `json:"assertions,omitempty"`
+	Extract            []Extraction      `json:"extract,omitempty"`
+}
+
+// Extraction stores a value from a step's response in a variable. Exactly
+// one of JSONPath, Header and Regex is set.
+type Extraction struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"jsonPath,omitempty"`
+	Header   string `json:"header,omitempty"`
+	Regex    string `json:"regex,omitempty"` // first capture group, or the whole match
+}
+
+func (s *TransactionSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+
+	if len(s.Steps) == 0 {
+		errs.add("steps", "is required")
+	} else if len(s.Steps) > 20 {
+		errs.add("steps", "must have at most 20 steps")
+	}
+
+	for i, step := range s.Steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		// URLs built from variables can only be checked once they are substituted
+		if strings.Contains(step.URL, "{{") {
+			errs.required(field+".url", step.URL)
+		} else {
+			errs.httpURL(field+".url", step.URL)
+		}
+		errs.oneOf(field+".method", step.Method,
+			http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions)
+		if step.ExpectedStatusCode != 0 {
+			errs.between(field+".expectedStatusCode", step.ExpectedStatusCode, 100, 599)
+		}
+		if step.Assertions != nil {
+			var assertionErrs errorList
+			step.Assertions.validate(&assertionErrs)
+			for _, e := range assertionErrs {
+				errs.add(field+"."+e.Field, "%s", e.Message)
+			}
+		}
+		for j, extraction := range step.Extract {
+			extraction.validate(&errs, fmt.Sprintf("%s.extract[%d]", field, j))
+		}
+	}
+	return errs
+}
+
+func (e *Extraction) validate(errs *errorList, field string) {
+	errs.required(field+".name", e.Name)
+
+	set := 0
+	for _, source := range []string{e.JSONPath, e.Header, e.Regex} {
+		if source != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		errs.add(field, "must set exactly one of jsonPath, header or regex")
+	}
+	if e.JSONPath != "" {
+		errs.jsonPath(field+".jsonPath", e.JSONPath)
+	}
+	if e.Regex != "" {
+		errs.regexp(field+".regex", e.Regex)
+	}
+}
@@ -0,0 +1,44 @@
+package specs
+
+func init() {
+	Register(CheckKind{Type: "udp", Kind: "UdpCheck", New: func() Spec { return &UDPSpec{} }})
+}
+
+// UDPSpec configures a UdpCheck
+type UDPSpec struct {
+	CommonSpec
+	Host string `json:"host"`
+	Port int    `json:"port,omitempty"` // required unless ntp is set, which defaults to 123
+	Exchange
+	NTP *NTP `json:"ntp,omitempty"`
+}
+
+// NTP queries the server with NTP and checks its clock instead of sending a payload
+type NTP struct {
+	MaxOffset  string `json:"maxOffset,omitempty"`  // default: 1s
+	MaxStratum int    `json:"maxStratum,omitempty"` // default: 15
+}
+
+func (s *UDPSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	errs.host("host", s.Host)
+	errs.port("port", s.Port, s.NTP == nil)
+	s.Exchange.validate(&errs)
+	if s.NTP != nil {
+		if s.Send != "" || s.SendHex != "" || s.Expect != "" {
+			errs.add("ntp", "cannot be combined with send or expect")
+		}
+		s.NTP.validate(&errs)
+	} else if s.Send == "" && s.SendHex == "" {
+		errs.add("send", "is required unless ntp is set")
+	}
+	return errs
+}
+
+func (n *NTP) validate(errs *errorList) {
+	errs.duration("ntp.maxOffset", n.MaxOffset)
+	if n.MaxStratum != 0 {
+		errs.between("ntp.maxStratum", n.MaxStratum, 1, 15)
+	}
+}
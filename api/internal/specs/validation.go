@@ -0,0 +1,159 @@
+package specs
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// FieldError describes a single invalid field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a job or its config is invalid
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		if fieldErr.Field == "" {
+			messages = append(messages, fieldErr.Message)
+		} else {
+			messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Details returns the errors keyed by field for API responses
+func (e *ValidationError) Details() map[string]string {
+	details := make(map[string]string, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		details[fieldErr.Field] = fieldErr.Message
+	}
+	return details
+}
+
+// NewValidationError returns a validation error for a single field
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Errors: []FieldError{{Field: field, Message: message}}}
+}
+
+// errorList accumulates field errors while validating a spec
+type errorList []FieldError
+
+func (l *errorList) add(field, format string, args ...interface{}) {
+	*l = append(*l, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *errorList) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		l.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+func (l *errorList) duration(field, value string) {
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		l.add(field, "must be a duration such as \"30s\" or \"500ms\"")
+		return
+	}
+	if d <= 0 {
+		l.add(field, "must be greater than zero")
+	}
+}
+
+func (l *errorList) oneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	l.add(field, "must be one of %s", strings.Join(allowed, ", "))
+}
+
+func (l *errorList) between(field string, value, min, max int) {
+	if value < min || value > max {
+		l.add(field, "must be between %d and %d", min, max)
+	}
+}
+
+func (l *errorList) port(field string, value int, required bool) {
+	if value == 0 && !required {
+		return
+	}
+	l.between(field, value, 1, 65535)
+}
+
+func (l *errorList) host(field, value string) {
+	if !l.required(field, value) {
+		return
+	}
+	if strings.ContainsAny(value, " /") {
+		l.add(field, "must be a hostname or IP address")
+	}
+}
+
+func (l *errorList) ip(field, value string) {
+	if value != "" && net.ParseIP(value) == nil {
+		l.add(field, "must be an IP address")
+	}
+}
+
+func (l *errorList) httpURL(field, value string) {
+	if !l.required(field, value) {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		l.add(field, "must be an absolute http or https URL")
+	}
+}
+
+func (l *errorList) schedule(field, value string) {
+	if !l.required(field, value) {
+		return
+	}
+	if _, err := cron.ParseStandard(value); err != nil {
+		l.add(field, "must be a cron expression such as \"*/5 * * * *\"")
+	}
+}
+
+func (l *errorList) regexp(field, value string) {
+	if !l.required(field, value) {
+		return
+	}
+	if _, err := regexp.Compile(value); err != nil {
+		l.add(field, "must be a valid regular expression: %v", err)
+	}
+}
+
+// jsonPath accepts the JSONPath subset supported by the runner: "$" followed
+// by ".key", "['key']" or "[index]" segments
+func (l *errorList) jsonPath(field, value string) {
+	if !l.required(field, value) {
+		return
+	}
+	if !jsonPathPattern.MatchString(value) {
+		l.add(field, "must be a JSONPath such as \"$.data.items[0].id\"")
+	}
+}
+
+var jsonPathPattern = regexp.MustCompile(`^\$(\.[^.\[\]]+|\['[^']*'\]|\[[0-9]+\])*$`)
@@ -0,0 +1,43 @@
+package specs
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func init() {
+	Register(CheckKind{Type: "websocket", Kind: "WebSocketCheck", New: func() Spec { return &WebSocketSpec{} }})
+}
+
+// WebSocketSpec configures a WebSocketCheck, which opens a connection and
+// optionally sends a message and waits for an expected one
+type WebSocketSpec struct {
+	CommonSpec
+	URL                string            `json:"url"` // ws:// or wss://
+	Headers            map[string]string `json:"headers,omitempty"`
+	Subprotocols       []string          `json:"subprotocols,omitempty"` // the server must accept one
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty"`
+	Exchange
+	ExpectJSON []JSONAssertion `json:"expectJson,omitempty"` // assertions the expected message must satisfy
+}
+
+func (s *WebSocketSpec) Validate() []FieldError {
+	var errs errorList
+	s.CommonSpec.validate(&errs)
+	if errs.required("url", s.URL) {
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			errs.add("url", "must be an absolute ws or wss URL")
+		} else if s.InsecureSkipVerify && u.Scheme != "wss" {
+			errs.add("insecureSkipVerify", "requires a wss url")
+		}
+	}
+	for i, protocol := range s.Subprotocols {
+		errs.required(fmt.Sprintf("subprotocols[%d]", i), protocol)
+	}
+	s.Exchange.validate(&errs)
+	for i, assertion := range s.ExpectJSON {
+		assertion.validate(&errs, fmt.Sprintf("expectJson[%d]", i))
+	}
+	return errs
+}
@@ -45,7 +45,7 @@ type Hub struct {
 // NewHub creates a new WebSocket hub
 func NewHub() *Hub {
 	return &Hub{
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan []byte, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
@@ -91,6 +91,24 @@ func (h *Hub) BroadcastExecutionCreated(execution *models.Execution) {
 	h.broadcastMessage(message)
 }
 
+// BroadcastStatusChanged broadcasts a job status transition (e.g. success to degraded) to all connected clients
+func (h *Hub) BroadcastStatusChanged(change *models.StatusChange) {
+	message := models.WebSocketMessage{
+		Type: "status_changed",
+		Data: change,
+	}
+	h.broadcastMessage(message)
+}
+
+// BroadcastCertificateReplaced broadcasts that a job started seeing a new certificate in place of an old one
+func (h *Hub) BroadcastCertificateReplaced(replaced *models.CertificateReplaced) {
+	message := models.WebSocketMessage{
+		Type: "certificate_replaced",
+		Data: replaced,
+	}
+	h.broadcastMessage(message)
+}
+
 // BroadcastJobUpdated broadcasts a job update to all connected clients
 func (h *Hub) BroadcastJobUpdated(job *models.Job) {
 	message := models.WebSocketMessage{
@@ -2,6 +2,9 @@
 -- Creates tables and inserts sample data
 
 -- Drop existing tables if they exist
+DROP TABLE IF EXISTS heartbeats CASCADE;
+DROP TABLE IF EXISTS job_certificates CASCADE;
+DROP TABLE IF EXISTS certificates CASCADE;
 DROP TABLE IF EXISTS executions CASCADE;
 DROP TABLE IF EXISTS jobs CASCADE;
 
@@ -12,6 +15,7 @@ CREATE TABLE jobs (
     type VARCHAR(100) NOT NULL,
     config JSONB NOT NULL,
     enabled BOOLEAN DEFAULT true,
+    managed_by VARCHAR(50),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -31,102 +35,148 @@ CREATE INDEX idx_executions_job_id ON executions(job_id);
 CREATE INDEX idx_executions_timestamp ON executions(timestamp);
 CREATE INDEX idx_executions_job_timestamp ON executions(job_id, timestamp DESC);
 
+-- Create certificates table, the inventory of TLS certificates seen by SSL checks
+CREATE TABLE certificates (
+    id SERIAL PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL UNIQUE,
+    subject TEXT,
+    issuer TEXT,
+    serial_number VARCHAR(100),
+    sans JSONB,
+    not_before TIMESTAMP WITH TIME ZONE,
+    not_after TIMESTAMP WITH TIME ZONE,
+    first_seen TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create job_certificates table, linking certificates to the jobs that see them
+CREATE TABLE job_certificates (
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    certificate_id INTEGER NOT NULL REFERENCES certificates(id) ON DELETE CASCADE,
+    host VARCHAR(255) NOT NULL,
+    first_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (job_id, certificate_id)
+);
+
+CREATE INDEX idx_certificates_not_after ON certificates(not_after);
+CREATE INDEX idx_job_certificates_job_last_seen ON job_certificates(job_id, last_seen DESC);
+
+-- Create heartbeats table, tracking the pings of push-based heartbeat jobs
+CREATE TABLE heartbeats (
+    job_id INTEGER PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    last_ping TIMESTAMP WITH TIME ZONE,
+    last_status VARCHAR(50),
+    expected_by TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_heartbeats_expected_by ON heartbeats(expected_by);
+
 -- Insert sample jobs with variety across different services and environments
 INSERT INTO jobs (name, type, config, enabled) VALUES 
 -- API Health Checks
 (
     'api-health-check-production',
-    'api-health',
-    '{"url": "https://api.example.com/health", "method": "GET", "expected_status": 200, "timeout": 30, "metadata": {"labels": {"service": "api", "environment": "production", "team": "backend"}}}'::jsonb,
+    'http',
+    '{"apiVersion": "moogie.io/v1", "kind": "HttpCheck", "metadata": {"name": "api-health-check-production", "labels": {"service": "api", "environment": "production", "team": "backend"}}, "spec": {"url": "https://api.example.com/health", "method": "GET", "expectedStatusCode": 200, "timeout": "30s", "schedule": "*/5 * * * *"}}'::jsonb,
     true
 ),
 (
     'api-health-check-staging',
-    'api-health',
-    '{"url": "https://staging-api.example.com/health", "method": "GET", "expected_status": 200, "timeout": 30, "metadata": {"labels": {"service": "api", "environment": "staging", "team": "backend"}}}'::jsonb,
+    'http',
+    '{"apiVersion": "moogie.io/v1", "kind": "HttpCheck", "metadata": {"name": "api-health-check-staging", "labels": {"service": "api", "environment": "staging", "team": "backend"}}, "spec": {"url": "https://staging-api.example.com/health", "method": "GET", "expectedStatusCode": 200, "timeout": "30s", "schedule": "*/5 * * * *"}}'::jsonb,
     true
 ),
 (
     'api-users-endpoint',
-    'api-health',
-    '{"url": "https://api.example.com/users", "method": "GET", "expected_status": 200, "timeout": 30, "metadata": {"labels": {"service": "api", "environment": "production", "team": "backend"}}}'::jsonb,
+    'http',
+    '{"apiVersion": "moogie.io/v1", "kind": "HttpCheck", "metadata": {"name": "api-users-endpoint", "labels": {"service": "api", "environment": "production", "team": "backend"}}, "spec": {"url": "https://api.example.com/users", "method": "GET", "expectedStatusCode": 200, "timeout": "30s", "schedule": "*/5 * * * *"}}'::jsonb,
     true
 ),
 (
     'payment-gateway-health',
-    'api-health',
-    '{"url": "https://payments.example.com/health", "method": "GET", "expected_status": 200, "timeout": 30, "metadata": {"labels": {"service": "payments", "environment": "production", "team": "backend"}}}'::jsonb,
+    'http',
+    '{"apiVersion": "moogie.io/v1", "kind": "HttpCheck", "metadata": {"name": "payment-gateway-health", "labels": {"service": "payments", "environment": "production", "team": "backend"}}, "spec": {"url": "https://payments.example.com/health", "method": "GET", "expectedStatusCode": 200, "timeout": "30s", "schedule": "*/5 * * * *"}}'::jsonb,
     true
 ),
 -- Database Checks
 (
     'database-primary-check',
-    'tcp',
-    '{"host": "db-primary.example.com", "port": 5432, "timeout": 10, "metadata": {"labels": {"service": "database", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    'database',
+    '{"apiVersion": "moogie.io/v1", "kind": "DatabaseCheck", "metadata": {"name": "database-primary-check", "labels": {"service": "database", "environment": "production", "team": "infrastructure"}}, "spec": {"engine": "postgres", "host": "db-primary.example.com", "username": "monitor", "passwordEnv": "DB_MONITOR_PASSWORD", "database": "app", "query": "SELECT 1", "timeout": "10s", "schedule": "*/2 * * * *"}}'::jsonb,
     true
 ),
 (
     'database-replica-check',
-    'tcp',
-    '{"host": "db-replica.example.com", "port": 5432, "timeout": 10, "metadata": {"labels": {"service": "database", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    'database',
+    '{"apiVersion": "moogie.io/v1", "kind": "DatabaseCheck", "metadata": {"name": "database-replica-check", "labels": {"service": "database", "environment": "production", "team": "infrastructure"}}, "spec": {"engine": "postgres", "host": "db-replica.example.com", "username": "monitor", "passwordEnv": "DB_MONITOR_PASSWORD", "database": "app", "query": "SELECT COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) AS lag_seconds", "assertions": [{"column": "lag_seconds", "operator": "<", "value": "30"}], "timeout": "10s", "schedule": "*/2 * * * *"}}'::jsonb,
     true
 ),
 (
     'redis-cache-check',
-    'tcp',
-    '{"host": "redis.example.com", "port": 6379, "timeout": 10, "metadata": {"labels": {"service": "cache", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    'database',
+    '{"apiVersion": "moogie.io/v1", "kind": "DatabaseCheck", "metadata": {"name": "redis-cache-check", "labels": {"service": "cache", "environment": "production", "team": "infrastructure"}}, "spec": {"engine": "redis", "host": "redis.example.com", "query": "INFO replication", "assertions": [{"column": "role", "operator": "==", "value": "master"}], "timeout": "10s", "schedule": "*/2 * * * *"}}'::jsonb,
     true
 ),
 -- DNS Checks
 (
     'dns-primary-domain',
     'dns',
-    '{"hostname": "example.com", "record_type": "A", "expected_ip": "93.184.216.34", "metadata": {"labels": {"service": "dns", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "DnsCheck", "metadata": {"name": "dns-primary-domain", "labels": {"service": "dns", "environment": "production", "team": "infrastructure"}}, "spec": {"domain": "example.com", "recordType": "A", "expectedIp": "93.184.216.34", "timeout": "5s", "schedule": "*/10 * * * *"}}'::jsonb,
     true
 ),
 (
     'dns-api-subdomain',
     'dns',
-    '{"hostname": "api.example.com", "record_type": "A", "expected_ip": "93.184.216.35", "metadata": {"labels": {"service": "dns", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "DnsCheck", "metadata": {"name": "dns-api-subdomain", "labels": {"service": "dns", "environment": "production", "team": "infrastructure"}}, "spec": {"domain": "api.example.com", "recordType": "A", "expectedIp": "93.184.216.35", "timeout": "5s", "schedule": "*/10 * * * *"}}'::jsonb,
     true
 ),
 -- Ping/Network Checks
 (
     'ping-production-server',
     'ping',
-    '{"host": "server1.example.com", "count": 4, "timeout": 5, "metadata": {"labels": {"service": "network", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "PingCheck", "metadata": {"name": "ping-production-server", "labels": {"service": "network", "environment": "production", "team": "infrastructure"}}, "spec": {"host": "server1.example.com", "count": 4, "timeout": "5s", "schedule": "*/1 * * * *"}}'::jsonb,
     true
 ),
 (
     'ping-load-balancer',
     'ping',
-    '{"host": "lb.example.com", "count": 4, "timeout": 5, "metadata": {"labels": {"service": "network", "environment": "production", "team": "infrastructure"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "PingCheck", "metadata": {"name": "ping-load-balancer", "labels": {"service": "network", "environment": "production", "team": "infrastructure"}}, "spec": {"host": "lb.example.com", "count": 4, "timeout": "5s", "schedule": "*/1 * * * *"}}'::jsonb,
     true
 ),
 -- SSL Certificate Checks
 (
     'ssl-main-domain',
     'ssl',
-    '{"hostname": "example.com", "port": 443, "warning_days": 30, "critical_days": 7, "metadata": {"labels": {"service": "ssl", "environment": "production", "team": "security"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "SslCheck", "metadata": {"name": "ssl-main-domain", "labels": {"service": "ssl", "environment": "production", "team": "security"}}, "spec": {"host": "example.com", "port": 443, "daysBeforeExpiry": 30, "schedule": "0 8 * * *"}}'::jsonb,
     true
 ),
 (
     'ssl-api-domain',
     'ssl',
-    '{"hostname": "api.example.com", "port": 443, "warning_days": 30, "critical_days": 7, "metadata": {"labels": {"service": "ssl", "environment": "production", "team": "security"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "SslCheck", "metadata": {"name": "ssl-api-domain", "labels": {"service": "ssl", "environment": "production", "team": "security"}}, "spec": {"host": "api.example.com", "port": 443, "daysBeforeExpiry": 30, "schedule": "0 8 * * *"}}'::jsonb,
     true
 ),
 (
     'ssl-payment-gateway',
     'ssl',
-    '{"hostname": "payments.example.com", "port": 443, "warning_days": 30, "critical_days": 7, "metadata": {"labels": {"service": "ssl", "environment": "production", "team": "security"}}}'::jsonb,
+    '{"apiVersion": "moogie.io/v1", "kind": "SslCheck", "metadata": {"name": "ssl-payment-gateway", "labels": {"service": "ssl", "environment": "production", "team": "security"}}, "spec": {"host": "payments.example.com", "port": 443, "daysBeforeExpiry": 30, "schedule": "0 8 * * *"}}'::jsonb,
+    true
+),
+-- Heartbeat Jobs (pinged by the jobs themselves)
+(
+    'nightly-backup-heartbeat',
+    'heartbeat',
+    '{"apiVersion": "moogie.io/v1", "kind": "HeartbeatCheck", "metadata": {"name": "nightly-backup-heartbeat", "labels": {"service": "backups", "environment": "production", "team": "infrastructure"}}, "spec": {"schedule": "0 2 * * *", "grace": "1h"}}'::jsonb,
     true
 ),
 -- Some disabled checks for variety
 (
     'api-health-check-development',
-    'api-health',
-    '{"url": "https://dev-api.example.com/health", "method": "GET", "expected_status": 200, "timeout": 30, "metadata": {"labels": {"service": "api", "environment": "development", "team": "backend"}}}'::jsonb,
+    'http',
+    '{"apiVersion": "moogie.io/v1", "kind": "HttpCheck", "metadata": {"name": "api-health-check-development", "labels": {"service": "api", "environment": "development", "team": "backend"}}, "spec": {"url": "https://dev-api.example.com/health", "method": "GET", "expectedStatusCode": 200, "timeout": "30s", "schedule": "*/5 * * * *"}}'::jsonb,
     false
 );
 
@@ -146,16 +196,18 @@ SELECT
     END as status,
     -- Response time varies by check type
     CASE j.type
-        WHEN 'api-health' THEN (random() * 800 + 100)::int  -- 100-900ms
+        WHEN 'http' THEN (random() * 800 + 100)::int  -- 100-900ms
         WHEN 'tcp' THEN (random() * 100 + 10)::int          -- 10-110ms
         WHEN 'dns' THEN (random() * 50 + 5)::int            -- 5-55ms
         WHEN 'ping' THEN (random() * 200 + 20)::int         -- 20-220ms
         WHEN 'ssl' THEN (random() * 300 + 50)::int          -- 50-350ms
+        WHEN 'database' THEN (random() * 40 + 5)::int       -- 5-45ms
+        WHEN 'heartbeat' THEN (random() * 600000 + 60000)::int -- 1-11min job runs
         ELSE (random() * 500 + 50)::int
     END as response_time,
     -- Details vary by check type
     CASE j.type
-        WHEN 'api-health' THEN jsonb_build_object(
+        WHEN 'http' THEN jsonb_build_object(
             'status_code', CASE WHEN random() < 0.90 THEN 200 ELSE 500 END,
             'body_size', (random() * 1000 + 100)::int
         )
@@ -163,6 +215,14 @@ SELECT
             'connection_time', (random() * 100)::int,
             'port_open', random() < 0.95
         )
+        WHEN 'database' THEN jsonb_build_object(
+            'connect_time_ms', (random() * 30 + 2)::int,
+            'query_time_ms', (random() * 10 + 1)::int,
+            'row_count', 1
+        )
+        WHEN 'heartbeat' THEN jsonb_build_object(
+            'message', 'Backup completed'
+        )
         WHEN 'dns' THEN jsonb_build_object(
             'resolved_ip', '93.184.216.' || (30 + (random() * 10)::int)::text,
             'query_time', (random() * 50)::int
@@ -186,6 +246,17 @@ CROSS JOIN generate_series(1, 1000) -- Generate 1000 executions per job over 180
 WHERE j.enabled = true  -- Only generate executions for enabled jobs
 ORDER BY random();
 
+-- Give heartbeat jobs a token and a deadline, as the API does when they are created
+INSERT INTO heartbeats (job_id, token, last_ping, last_status, expected_by)
+SELECT
+    j.id,
+    md5(random()::text),
+    CURRENT_TIMESTAMP - interval '1 hour',
+    'success',
+    CURRENT_TIMESTAMP + interval '1 day'
+FROM jobs j
+WHERE j.type = 'heartbeat';
+
 -- Add specific recent executions to ensure we have failures visible in the UI
 -- Add recent failed execution for first job (api-health-check-production) to show at top of dashboard
 INSERT INTO executions (job_id, status, response_time, details, timestamp) VALUES
@@ -193,7 +264,7 @@ INSERT INTO executions (job_id, status, response_time, details, timestamp) VALUE
 (1, 'success', 120, '{"status_code": 200, "body_size": 450}'::jsonb, CURRENT_TIMESTAMP - interval '10 minutes'),
 (1, 'failed', 920, '{"status_code": 503, "body_size": 180, "error": "Service Unavailable"}'::jsonb, CURRENT_TIMESTAMP - interval '15 minutes'),
 (1, 'success', 95, '{"status_code": 200, "body_size": 430}'::jsonb, CURRENT_TIMESTAMP - interval '20 minutes'),
-(1, 'warning', 780, '{"status_code": 200, "body_size": 450, "warning": "Slow response"}'::jsonb, CURRENT_TIMESTAMP - interval '25 minutes');
+(1, 'degraded', 780, '{"status_code": 200, "body_size": 450, "warning": "Slow response"}'::jsonb, CURRENT_TIMESTAMP - interval '25 minutes');
 
 -- Add recent executions with some failures for second job to show variety
 INSERT INTO executions (job_id, status, response_time, details, timestamp) VALUES
@@ -206,7 +277,7 @@ INSERT INTO executions (job_id, status, response_time, details, timestamp) VALUE
 -- Add recent executions for third job with mixed statuses
 INSERT INTO executions (job_id, status, response_time, details, timestamp) VALUES
 (3, 'success', 145, '{"status_code": 200, "body_size": 520}'::jsonb, CURRENT_TIMESTAMP - interval '4 minutes'),
-(3, 'warning', 650, '{"status_code": 200, "body_size": 510, "warning": "High response time"}'::jsonb, CURRENT_TIMESTAMP - interval '9 minutes'),
+(3, 'degraded', 650, '{"status_code": 200, "body_size": 510, "warning": "High response time"}'::jsonb, CURRENT_TIMESTAMP - interval '9 minutes'),
 (3, 'success', 132, '{"status_code": 200, "body_size": 530}'::jsonb, CURRENT_TIMESTAMP - interval '14 minutes'),
 (3, 'failed', 980, '{"status_code": 404, "body_size": 95, "error": "Not Found"}'::jsonb, CURRENT_TIMESTAMP - interval '19 minutes'),
 (3, 'success', 128, '{"status_code": 200, "body_size": 525}'::jsonb, CURRENT_TIMESTAMP - interval '24 minutes');
@@ -0,0 +1,250 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HTTPAssertions are checks made against the response body of an HTTP check.
+// JSON field names match the HttpCheck spec's assertions block.
+type HTTPAssertions struct {
+	Contains    []string        `json:"contains"`
+	NotContains []string        `json:"notContains"`
+	Matches     []string        `json:"matches"`    // Regular expressions the body must match
+	NotMatches  []string        `json:"notMatches"` // Regular expressions the body must not match
+	JSON        []JSONAssertion `json:"json"`
+	MaxBodySize int64           `json:"maxBodySize"` // Maximum body size in bytes (0 = no limit)
+}
+
+// JSONAssertion checks the value at a JSONPath such as "$.data.items[0].id".
+// Exactly one of Equals and Exists is set.
+type JSONAssertion struct {
+	Path   string          `json:"path"`
+	Equals json.RawMessage `json:"equals"`
+	Exists *bool           `json:"exists"`
+}
+
+// validate compiles the regular expressions and JSONPaths so that mistakes
+// are reported as configuration errors rather than check failures
+func (a *HTTPAssertions) validate() error {
+	for _, pattern := range append(append([]string{}, a.Matches...), a.NotMatches...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid body assertion pattern %q: %w", pattern, err)
+		}
+	}
+	if err := validateJSONAssertions(a.JSON); err != nil {
+		return err
+	}
+	if a.MaxBodySize < 0 {
+		return fmt.Errorf("maxBodySize must not be negative")
+	}
+	return nil
+}
+
+// check evaluates the assertions in order and describes the first one that fails
+func (a *HTTPAssertions) check(body []byte) error {
+	text := string(body)
+
+	for _, s := range a.Contains {
+		if !strings.Contains(text, s) {
+			return fmt.Errorf("body does not contain %q", s)
+		}
+	}
+	for _, s := range a.NotContains {
+		if strings.Contains(text, s) {
+			return fmt.Errorf("body contains %q", s)
+		}
+	}
+	for _, pattern := range a.Matches {
+		if !regexp.MustCompile(pattern).Match(body) {
+			return fmt.Errorf("body does not match /%s/", pattern)
+		}
+	}
+	for _, pattern := range a.NotMatches {
+		if regexp.MustCompile(pattern).Match(body) {
+			return fmt.Errorf("body matches /%s/", pattern)
+		}
+	}
+
+	if len(a.JSON) == 0 {
+		return nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("body is not valid JSON: %v", err)
+	}
+	for _, assertion := range a.JSON {
+		if err := assertion.check(document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateJSONAssertions parses the JSONPaths and checks that each assertion
+// sets exactly one of equals or exists
+func validateJSONAssertions(assertions []JSONAssertion) error {
+	for _, assertion := range assertions {
+		if _, err := parseJSONPath(assertion.Path); err != nil {
+			return err
+		}
+		if (len(assertion.Equals) > 0) == (assertion.Exists != nil) {
+			return fmt.Errorf("JSON assertion %s must set exactly one of equals or exists", assertion.Path)
+		}
+	}
+	return nil
+}
+
+// loadJSONAssertionsEnv appends the assertions in <prefix>JSON_EQUALS and
+// <prefix>JSON_EXISTS to target, e.g. HTTP_JSON_EQUALS="$.status=ok,$.count=3".
+// Values that are not valid JSON are compared as strings.
+func loadJSONAssertionsEnv(prefix string, target *[]JSONAssertion) error {
+	var pairs []string
+	envList(prefix+"JSON_EQUALS", &pairs)
+	for _, pair := range pairs {
+		path, expected, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid %sJSON_EQUALS entry %q: expected path=value", prefix, pair)
+		}
+		raw := json.RawMessage(expected)
+		if !json.Valid(raw) {
+			raw, _ = json.Marshal(expected)
+		}
+		*target = append(*target, JSONAssertion{Path: strings.TrimSpace(path), Equals: raw})
+	}
+
+	var paths []string
+	envList(prefix+"JSON_EXISTS", &paths)
+	for _, path := range paths {
+		exists := true
+		*target = append(*target, JSONAssertion{Path: path, Exists: &exists})
+	}
+	return nil
+}
+
+func (j JSONAssertion) check(document interface{}) error {
+	path, _ := parseJSONPath(j.Path)
+	value, found := path.lookup(document)
+
+	if j.Exists != nil {
+		switch {
+		case *j.Exists && !found:
+			return fmt.Errorf("%s does not exist", j.Path)
+		case !*j.Exists && found:
+			return fmt.Errorf("%s exists", j.Path)
+		}
+		return nil
+	}
+
+	if !found {
+		return fmt.Errorf("%s does not exist, expected %s", j.Path, compactJSON(j.Equals))
+	}
+	var expected interface{}
+	if err := json.Unmarshal(j.Equals, &expected); err != nil {
+		return fmt.Errorf("invalid expected value for %s: %v", j.Path, err)
+	}
+	if !reflect.DeepEqual(value, expected) {
+		actual, _ := json.Marshal(value)
+		return fmt.Errorf("%s is %s, expected %s", j.Path, actual, compactJSON(j.Equals))
+	}
+	return nil
+}
+
+func compactJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// jsonPath is a parsed JSONPath. Segments are either object keys (string)
+// or array indexes (int).
+type jsonPath []interface{}
+
+var jsonPathSegment = regexp.MustCompile(`^(?:\.([^.\[\]]+)|\['([^']*)'\]|\[([0-9]+)\])`)
+
+// parseJSONPath parses the supported JSONPath subset: "$" followed by
+// ".key", "['key']" or "[index]" segments
+func parseJSONPath(expr string) (jsonPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+
+	var path jsonPath
+	rest := expr[1:]
+	for rest != "" {
+		m := jsonPathSegment.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid JSONPath %q at %q", expr, rest)
+		}
+		switch {
+		case m[1] != "":
+			path = append(path, m[1])
+		case m[3] != "":
+			index, _ := strconv.Atoi(m[3])
+			path = append(path, index)
+		default:
+			path = append(path, m[2])
+		}
+		rest = rest[len(m[0]):]
+	}
+	return path, nil
+}
+
+// lookup returns the value at the path and whether it exists
+func (p jsonPath) lookup(document interface{}) (interface{}, bool) {
+	current := document
+	for _, segment := range p {
+		switch key := segment.(type) {
+		case string:
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[key]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := current.([]interface{})
+			if !ok || key >= len(array) {
+				return nil, false
+			}
+			current = array[key]
+		}
+	}
+	return current, true
+}
+
+// lookupJSONPath returns the value at a JSONPath in a JSON body as a string:
+// strings as they are, other values as JSON
+func lookupJSONPath(body []byte, expr string) (string, error) {
+	path, err := parseJSONPath(expr)
+	if err != nil {
+		return "", err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return "", fmt.Errorf("body is not valid JSON: %v", err)
+	}
+
+	value, found := path.lookup(document)
+	if !found {
+		return "", fmt.Errorf("%s does not exist", expr)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,226 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// startAssertionServer serves a JSON document, a text page, a large body, a
+// 503 and a page that requires an Authorization header
+func startAssertionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok","count":3,"ready":true,"data":{"items":[{"id":"a1"},{"id":"b2"}]},"owner":null}`))
+	})
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello from moogie v1.2.3"))
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 2000)))
+	})
+	mux.HandleFunc("/unavailable", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("maintenance"))
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("welcome"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func jsonEquals(path, value string) JSONAssertion {
+	return JSONAssertion{Path: path, Equals: json.RawMessage(value)}
+}
+
+func jsonExists(path string, exists bool) JSONAssertion {
+	return JSONAssertion{Path: path, Exists: &exists}
+}
+
+func TestHTTPAssertions(t *testing.T) {
+	server := startAssertionServer(t)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		headers        map[string]string
+		assertions     HTTPAssertions
+		wantErr        string // prefix of the error message, empty for success
+	}{
+		{name: "status", path: "/text"},
+		{name: "unexpected status", path: "/unavailable", wantErr: "Expected status 200, got 503"},
+		{name: "expected non-200 status", path: "/unavailable", expectedStatus: 503},
+		{
+			name: "status is checked before the body", path: "/unavailable",
+			assertions: HTTPAssertions{Contains: []string{"online"}},
+			wantErr:    "Expected status 200, got 503",
+		},
+		{name: "request headers are sent", path: "/private", headers: map[string]string{"Authorization": "Bearer s3cret"}},
+		{name: "missing request header", path: "/private", wantErr: "Expected status 200, got 401"},
+
+		{name: "contains", path: "/text", assertions: HTTPAssertions{Contains: []string{"moogie", "Hello"}}},
+		{name: "does not contain", path: "/text", assertions: HTTPAssertions{Contains: []string{"goodbye"}}, wantErr: `Assertion failed: body does not contain "goodbye"`},
+		{name: "not contains", path: "/text", assertions: HTTPAssertions{NotContains: []string{"error"}}},
+		{name: "contains forbidden text", path: "/text", assertions: HTTPAssertions{NotContains: []string{"moogie"}}, wantErr: `Assertion failed: body contains "moogie"`},
+		{name: "matches", path: "/text", assertions: HTTPAssertions{Matches: []string{`v\d+\.\d+\.\d+$`}}},
+		{name: "does not match", path: "/text", assertions: HTTPAssertions{Matches: []string{`^Goodbye`}}, wantErr: "Assertion failed: body does not match /^Goodbye/"},
+		{name: "not matches", path: "/text", assertions: HTTPAssertions{NotMatches: []string{`v2\.`}}},
+		{name: "matches forbidden pattern", path: "/text", assertions: HTTPAssertions{NotMatches: []string{`v1\.\d`}}, wantErr: `Assertion failed: body matches /v1\.\d/`},
+
+		{
+			name: "JSON equals", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{
+				jsonEquals("$.status", `"ok"`),
+				jsonEquals("$.count", `3`),
+				jsonEquals("$.ready", `true`),
+				jsonEquals("$.owner", `null`),
+				jsonEquals("$.data.items[1].id", `"b2"`),
+				jsonEquals("$['data']['items'][0]", `{"id": "a1"}`),
+			}},
+		},
+		{
+			name: "JSON value differs", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonEquals("$.status", `"degraded"`)}},
+			wantErr:    `Assertion failed: $.status is "ok", expected "degraded"`,
+		},
+		{
+			name: "JSON number differs", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonEquals("$.count", `"3"`)}},
+			wantErr:    `Assertion failed: $.count is 3, expected "3"`,
+		},
+		{
+			name: "JSON value missing", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonEquals("$.data.items[5].id", `"z9"`)}},
+			wantErr:    `Assertion failed: $.data.items[5].id does not exist, expected "z9"`,
+		},
+		{
+			name: "JSON exists", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonExists("$.data.items[0]", true), jsonExists("$.error", false)}},
+		},
+		{
+			name: "JSON path missing", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonExists("$.data.total", true)}},
+			wantErr:    "Assertion failed: $.data.total does not exist",
+		},
+		{
+			name: "JSON path present", path: "/json",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonExists("$.owner", false)}},
+			wantErr:    "Assertion failed: $.owner exists",
+		},
+		{
+			name: "JSON assertion on a text body", path: "/text",
+			assertions: HTTPAssertions{JSON: []JSONAssertion{jsonExists("$.status", true)}},
+			wantErr:    "Assertion failed: body is not valid JSON",
+		},
+
+		{name: "within maximum size", path: "/big", assertions: HTTPAssertions{MaxBodySize: 2000}},
+		{name: "exceeds maximum size", path: "/big", assertions: HTTPAssertions{MaxBodySize: 1000}, wantErr: "Assertion failed: body exceeds maximum size of 1000 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewHTTPConfig()
+			cfg.URL = server.URL + tt.path
+			cfg.Headers = tt.headers
+			cfg.Assertions = tt.assertions
+			if tt.expectedStatus != 0 {
+				cfg.ExpectedStatus = tt.expectedStatus
+			}
+
+			result, err := RunHTTPCheck(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("RunHTTPCheck: %v", err)
+			}
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Errorf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+				return
+			}
+			if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, tt.wantErr) {
+				t.Errorf("status = %q (%s), want an error starting with %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPAssertionsBodyMetadata(t *testing.T) {
+	server := startAssertionServer(t)
+
+	cfg := NewHTTPConfig()
+	cfg.URL = server.URL + "/big"
+	cfg.Assertions.MaxBodySize = 1000
+	result, err := RunHTTPCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunHTTPCheck: %v", err)
+	}
+	if result.Metadata["body_size"] != 1000 || result.Metadata["body_truncated"] != true {
+		t.Errorf("body_size = %v, body_truncated = %v, want the body cut at 1000 bytes",
+			result.Metadata["body_size"], result.Metadata["body_truncated"])
+	}
+}
+
+func TestHTTPAssertionsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		assertions HTTPAssertions
+		want       string
+	}{
+		{"bad pattern", HTTPAssertions{Matches: []string{"("}}, "invalid body assertion pattern"},
+		{"bad negative pattern", HTTPAssertions{NotMatches: []string{"[a-"}}, "invalid body assertion pattern"},
+		{"path without $", HTTPAssertions{JSON: []JSONAssertion{jsonExists("status", true)}}, "must start with $"},
+		{"bad path segment", HTTPAssertions{JSON: []JSONAssertion{jsonExists("$.items[x]", true)}}, "invalid JSONPath"},
+		{"equals and exists", HTTPAssertions{JSON: []JSONAssertion{{Path: "$.a", Equals: json.RawMessage(`1`), Exists: new(bool)}}}, "exactly one of equals or exists"},
+		{"neither equals nor exists", HTTPAssertions{JSON: []JSONAssertion{{Path: "$.a"}}}, "exactly one of equals or exists"},
+		{"negative size", HTTPAssertions{MaxBodySize: -1}, "maxBodySize must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.assertions.validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadJSONAssertionsEnv(t *testing.T) {
+	t.Setenv("HTTP_JSON_EQUALS", `$.status=ok,$.count=3,$.ready=true`)
+	t.Setenv("HTTP_JSON_EXISTS", "$.data")
+
+	var assertions []JSONAssertion
+	if err := loadJSONAssertionsEnv("HTTP_", &assertions); err != nil {
+		t.Fatalf("loadJSONAssertionsEnv: %v", err)
+	}
+	want := []string{`$.status="ok"`, `$.count=3`, `$.ready=true`, `$.data exists`}
+	if len(assertions) != len(want) {
+		t.Fatalf("assertions = %+v, want %v", assertions, want)
+	}
+	for i, assertion := range assertions {
+		got := assertion.Path + "=" + string(assertion.Equals)
+		if assertion.Exists != nil {
+			got = assertion.Path + " exists"
+		}
+		if got != want[i] {
+			t.Errorf("assertion %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	t.Setenv("HTTP_JSON_EQUALS", "$.status")
+	if err := loadJSONAssertionsEnv("HTTP_", &assertions); err == nil || !strings.Contains(err.Error(), "expected path=value") {
+		t.Errorf("entry without a value: error = %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Config is the typed configuration of a check. Configs are created with
+// defaults applied by Checker.NewConfig and then populated from environment
+// variables, a JSON file or a job spec fetched from the API.
+type Config interface {
+	// LoadEnv reads the configuration from environment variables
+	LoadEnv() error
+	// Validate reports whether the configuration is complete
+	Validate() error
+}
+
+// Checker runs one type of synthetic check. Implementations register
+// themselves with Register so they can be looked up by job type.
+type Checker interface {
+	// Name is the job type handled by the checker, e.g. "http"
+	Name() string
+	// NewConfig returns a config for the checker with defaults applied
+	NewConfig() Config
+	// Run executes the check. A failing check is reported through the
+	// result's Status; an error means the check could not be run at all.
+	Run(ctx context.Context, cfg Config) (*CheckResult, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Checker)
+)
+
+// Register makes a checker available by name. It panics if a checker
+// with the same name is already registered.
+func Register(c Checker) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := c.Name()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("checks: checker %q registered twice", name))
+	}
+	registry[name] = c
+}
+
+// Lookup returns the checker registered for a job type
+func Lookup(name string) (Checker, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	c, ok := registry[name]
+	return c, ok
+}
+
+// Names returns the registered checker names in sorted order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configAs asserts that cfg is the config type expected by a checker
+func configAs[T Config](checker string, cfg Config) (T, error) {
+	typed, ok := cfg.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%s check: unexpected config type %T", checker, cfg)
+	}
+	return typed, nil
+}
@@ -0,0 +1,134 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// stubChecker is a minimal checker for exercising the registry
+type stubChecker struct{ name string }
+
+func (s stubChecker) Name() string      { return s.name }
+func (s stubChecker) NewConfig() Config { return NewTCPConfig() }
+func (s stubChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	return NewCheckResult(), nil
+}
+
+func TestLookupBuiltinCheckers(t *testing.T) {
+	for _, name := range []string{"http", "ssl", "dns", "tcp"} {
+		c, ok := Lookup(name)
+		if !ok {
+			t.Errorf("Lookup(%q): not registered", name)
+			continue
+		}
+		if c.Name() != name {
+			t.Errorf("Lookup(%q).Name() = %q", name, c.Name())
+		}
+	}
+
+	if _, ok := Lookup("no-such-check"); ok {
+		t.Error("Lookup of an unknown type succeeded")
+	}
+}
+
+func TestNamesSorted(t *testing.T) {
+	names := Names()
+	if !slices.IsSorted(names) {
+		t.Errorf("Names() not sorted: %v", names)
+	}
+	if !slices.Contains(names, "http") {
+		t.Errorf("Names() = %v, missing http", names)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	name := "test-duplicate"
+	Register(stubChecker{name: name})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, name)
+		registryMu.Unlock()
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a checker twice did not panic")
+		}
+	}()
+	Register(stubChecker{name: name})
+}
+
+func TestConfigAs(t *testing.T) {
+	tcp, err := configAs[*TCPConfig]("tcp", NewTCPConfig())
+	if err != nil || tcp == nil {
+		t.Fatalf("configAs with matching type: %v, %v", tcp, err)
+	}
+
+	_, err = configAs[*TCPConfig]("tcp", NewHTTPConfig())
+	if err == nil || !strings.Contains(err.Error(), "*checks.HTTPConfig") {
+		t.Errorf("configAs with wrong type: error = %v", err)
+	}
+}
+
+func TestRunWithWrongConfigType(t *testing.T) {
+	c, _ := Lookup("tcp")
+	if _, err := c.Run(context.Background(), NewHTTPConfig()); err == nil {
+		t.Error("Run accepted another checker's config")
+	}
+}
+
+func TestTCPCheckThroughChecker(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("+PONG\r\n"))
+			conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	c, ok := Lookup("tcp")
+	if !ok {
+		t.Fatal("tcp checker not registered")
+	}
+
+	doc := `{"apiVersion":"moogie.io/v1","kind":"TcpCheck","spec":{"host":"127.0.0.1","port":` +
+		strconv.Itoa(addr.Port) + `,"send":"PING\r\n","expect":"+PONG"}}`
+	cfg, err := ConfigFromJSON(c, []byte(doc))
+	if err != nil {
+		t.Fatalf("ConfigFromJSON: %v", err)
+	}
+
+	result, err := c.Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["port"] != addr.Port {
+		t.Errorf("metadata port = %v, want %d", result.Metadata["port"], addr.Port)
+	}
+
+	// Nothing listens once the listener is closed
+	ln.Close()
+	result, err = c.Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != StatusError {
+		t.Errorf("status against closed port = %q, want error", result.Status)
+	}
+}
@@ -0,0 +1,152 @@
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration that can be decoded from a duration string
+// such as "30s" (the format used in job specs) or a plain number of seconds
+type Duration time.Duration
+
+// Std returns the value as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string or number of seconds")
+	}
+
+	parsed, err := parseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// parseDuration accepts "30s" style durations or a plain number of seconds
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// envString sets target to the value of key if it is set
+func envString(key string, target *string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
+}
+
+// envInt sets target to the integer value of key if it is set
+func envInt(key string, target *int) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
+
+// envDuration sets target to the duration value of key if it is set
+func envDuration(key string, target *Duration) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := parseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	*target = Duration(parsed)
+	return nil
+}
+
+// envFloat sets target to the floating point value of key if it is set
+func envFloat(key string, target *float64) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
+
+// envBool sets target to the boolean value of key if it is set
+func envBool(key string, target *bool) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
+
+// envList sets target to the comma-separated values of key if it is set
+func envList(key string, target *[]string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*target = items
+}
+
+// envPairs adds the comma-separated key:value pairs of key to target if it is set
+func envPairs(key string, target *map[string]string) {
+	var pairs []string
+	envList(key, &pairs)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		if *target == nil {
+			*target = make(map[string]string)
+		}
+		(*target)[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+}
+
+// envAppend appends the value of key to target if it is set
+func envAppend(key string, target *[]string) {
+	if value := os.Getenv(key); value != "" {
+		*target = append(*target, value)
+	}
+}
@@ -0,0 +1,611 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// DatabaseConfig configures a database check. JSON field names match the DatabaseCheck spec.
+type DatabaseConfig struct {
+	Engine             string              `json:"engine"` // postgres, mysql or redis
+	Host               string              `json:"host"`
+	Port               int                 `json:"port"` // Default: the engine's standard port
+	Timeout            Duration            `json:"timeout"`
+	Username           string              `json:"username"`
+	Password           string              `json:"password"`
+	PasswordEnv        string              `json:"passwordEnv"` // Runner environment variable holding the password
+	Database           string              `json:"database"`    // Database name, or the database number for Redis
+	TLS                bool                `json:"tls"`
+	InsecureSkipVerify bool                `json:"insecureSkipVerify"`
+	Query              string              `json:"query"` // SQL query, or a Redis command such as "INFO replication"
+	Assertions         []DatabaseAssertion `json:"assertions"`
+}
+
+// DatabaseAssertion compares a column of the query's first row (or a field of
+// a Redis reply) with a value, e.g. {"column": "lag_seconds", "operator": "<", "value": "30"}
+type DatabaseAssertion struct {
+	Column   string `json:"column"`   // Default: the first column
+	Operator string `json:"operator"` // ==, !=, <, <=, >, >= or contains
+	Value    string `json:"value"`
+}
+
+// Supported database engines
+const (
+	EnginePostgres = "postgres"
+	EngineMySQL    = "mysql"
+	EngineRedis    = "redis"
+)
+
+// databasePorts are the standard ports of each engine
+var databasePorts = map[string]int{
+	EnginePostgres: 5432,
+	EngineMySQL:    3306,
+	EngineRedis:    6379,
+}
+
+// databaseOperators are the comparisons an assertion can make
+var databaseOperators = []string{"==", "!=", "<=", ">=", "<", ">", "contains"}
+
+// maxRecordedColumns limits how much of a wide row, such as the output of Redis INFO, is recorded
+const maxRecordedColumns = 50
+
+// NewDatabaseConfig returns a DatabaseConfig with defaults applied
+func NewDatabaseConfig() *DatabaseConfig {
+	return &DatabaseConfig{
+		Timeout: Duration(10 * time.Second),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
+//   - DATABASE_ENGINE: postgres, mysql or redis (required)
+//   - DATABASE_HOST: Target host (required)
+//   - DATABASE_PORT: Target port (default: 5432, 3306 or 6379)
+//   - DATABASE_TIMEOUT: Timeout in seconds (default: 10)
+//   - DATABASE_USERNAME: User to log in as (required for postgres and mysql)
+//   - DATABASE_PASSWORD: Password to log in with
+//   - DATABASE_NAME: Database name, or the database number for Redis
+//   - DATABASE_TLS: Set to true to connect with TLS
+//   - DATABASE_INSECURE_SKIP_VERIFY: Set to true to skip verifying the server's certificate
+//   - DATABASE_QUERY: Query to run (default: SELECT 1, or PING for Redis)
+//   - DATABASE_ASSERTIONS: Comma-separated assertions on the first row, e.g. "lag_seconds<30,role==master"
+func (c *DatabaseConfig) LoadEnv() error {
+	envString("DATABASE_ENGINE", &c.Engine)
+	if c.Engine == "" {
+		return fmt.Errorf("DATABASE_ENGINE environment variable is required")
+	}
+	envString("DATABASE_HOST", &c.Host)
+	if c.Host == "" {
+		return fmt.Errorf("DATABASE_HOST environment variable is required")
+	}
+
+	if err := envInt("DATABASE_PORT", &c.Port); err != nil {
+		return err
+	}
+	if err := envDuration("DATABASE_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	envString("DATABASE_USERNAME", &c.Username)
+	envString("DATABASE_PASSWORD", &c.Password)
+	envString("DATABASE_NAME", &c.Database)
+	envString("DATABASE_QUERY", &c.Query)
+	if err := envBool("DATABASE_TLS", &c.TLS); err != nil {
+		return err
+	}
+	if err := envBool("DATABASE_INSECURE_SKIP_VERIFY", &c.InsecureSkipVerify); err != nil {
+		return err
+	}
+
+	var assertions []string
+	envList("DATABASE_ASSERTIONS", &assertions)
+	for _, entry := range assertions {
+		assertion, ok := parseDatabaseAssertion(entry)
+		if !ok {
+			return fmt.Errorf("invalid DATABASE_ASSERTIONS entry %q: expected column, operator and value", entry)
+		}
+		c.Assertions = append(c.Assertions, assertion)
+	}
+	return nil
+}
+
+// parseDatabaseAssertion splits an assertion such as "lag_seconds<30" at its operator
+func parseDatabaseAssertion(entry string) (DatabaseAssertion, bool) {
+	if column, value, ok := strings.Cut(entry, " contains "); ok {
+		return DatabaseAssertion{Column: strings.TrimSpace(column), Operator: "contains", Value: strings.TrimSpace(value)}, true
+	}
+	// Two-character operators are listed first so "<=" is not read as "<"
+	for _, operator := range databaseOperators {
+		if column, value, ok := strings.Cut(entry, operator); ok {
+			return DatabaseAssertion{Column: strings.TrimSpace(column), Operator: operator, Value: strings.TrimSpace(value)}, true
+		}
+	}
+	return DatabaseAssertion{}, false
+}
+
+// Validate reports whether the configuration is complete
+func (c *DatabaseConfig) Validate() error {
+	if _, ok := databasePorts[c.Engine]; !ok {
+		return fmt.Errorf("engine must be one of postgres, mysql or redis")
+	}
+	if c.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if c.Engine != EngineRedis && c.Username == "" {
+		return fmt.Errorf("username is required for %s", c.Engine)
+	}
+	if c.Password != "" && c.PasswordEnv != "" {
+		return fmt.Errorf("password and passwordEnv cannot both be set")
+	}
+	if c.Engine == EngineRedis && c.Database != "" {
+		if _, err := strconv.Atoi(c.Database); err != nil {
+			return fmt.Errorf("database must be a number for redis")
+		}
+	}
+	if c.InsecureSkipVerify && !c.TLS {
+		return fmt.Errorf("insecureSkipVerify requires tls")
+	}
+	for i, assertion := range c.Assertions {
+		if !isDatabaseOperator(assertion.Operator) {
+			return fmt.Errorf("assertion %d: operator must be one of %s", i+1, strings.Join(databaseOperators, ", "))
+		}
+	}
+	return nil
+}
+
+func isDatabaseOperator(operator string) bool {
+	for _, candidate := range databaseOperators {
+		if operator == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// port returns the configured port, or the engine's standard port
+func (c *DatabaseConfig) port() int {
+	if c.Port != 0 {
+		return c.Port
+	}
+	return databasePorts[c.Engine]
+}
+
+// query returns the configured query, or a trivial one that proves the login worked
+func (c *DatabaseConfig) query() string {
+	if c.Query != "" {
+		return c.Query
+	}
+	if c.Engine == EngineRedis {
+		return "PING"
+	}
+	return "SELECT 1"
+}
+
+// password returns the configured password, reading it from the runner's environment if passwordEnv is set
+func (c *DatabaseConfig) password() string {
+	if c.PasswordEnv != "" {
+		return os.Getenv(c.PasswordEnv)
+	}
+	return c.Password
+}
+
+// databaseResult is the first row returned by a query. Values are in text form
+// and nil for NULL.
+type databaseResult struct {
+	Columns       []string
+	Row           map[string]*string // nil when the query returned no rows
+	RowCount      int
+	ServerVersion string
+}
+
+// databaseChecker runs database checks
+type databaseChecker struct{}
+
+func init() {
+	Register(databaseChecker{})
+}
+
+func (databaseChecker) Name() string {
+	return "database"
+}
+
+func (databaseChecker) NewConfig() Config {
+	return NewDatabaseConfig()
+}
+
+func (databaseChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*DatabaseConfig]("database", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunDatabaseCheck(ctx, c)
+}
+
+// RunDatabaseCheck logs in to a database, runs the query and checks the first
+// row against the assertions
+func RunDatabaseCheck(ctx context.Context, cfg *DatabaseConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	result.Metadata["engine"] = cfg.Engine
+	result.Metadata["host"] = cfg.Host
+	result.Metadata["port"] = cfg.port()
+	if cfg.Database != "" {
+		result.Metadata["database"] = cfg.Database
+	}
+	result.Metadata["query"] = cfg.query()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout.Std())
+	defer cancel()
+
+	var (
+		res *databaseResult
+		err error
+	)
+	start := time.Now()
+	switch cfg.Engine {
+	case EnginePostgres:
+		res, err = queryPostgres(ctx, cfg, result)
+	case EngineMySQL:
+		res, err = queryMySQL(ctx, cfg, result)
+	case EngineRedis:
+		res, err = queryRedis(ctx, cfg, result)
+	}
+	result.ResponseTimeMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	recordDatabaseResult(result, cfg, res)
+
+	for _, assertion := range cfg.Assertions {
+		if err := assertion.check(res); err != nil {
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Assertion failed: %v", err)
+			return result, nil
+		}
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// queryPostgres runs the query with the simple protocol, so checks also work
+// through transaction-pooling proxies such as PgBouncer
+func queryPostgres(ctx context.Context, cfg *DatabaseConfig, result *CheckResult) (*databaseResult, error) {
+	sslMode := "disable"
+	if cfg.TLS {
+		sslMode = "verify-full"
+		if cfg.InsecureSkipVerify {
+			sslMode = "require"
+		}
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.password()),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.port())),
+		Path:     "/" + cfg.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}, "application_name": {"moogie-runner"}}.Encode(),
+	}
+	connConfig, err := pgx.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %v", err)
+	}
+	connConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+
+	start := time.Now()
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return nil, fmt.Errorf("PostgreSQL connection failed: %v", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+	result.Metadata["connect_time_ms"] = time.Since(start).Milliseconds()
+
+	start = time.Now()
+	rows, err := conn.Query(ctx, cfg.query())
+	if err != nil {
+		return nil, fmt.Errorf("Query failed: %v", err)
+	}
+	defer rows.Close()
+
+	res := &databaseResult{ServerVersion: conn.PgConn().ParameterStatus("server_version")}
+	for _, field := range rows.FieldDescriptions() {
+		res.Columns = append(res.Columns, field.Name)
+	}
+	for rows.Next() {
+		res.RowCount++
+		if res.Row == nil {
+			// The simple protocol returns every value in text form
+			values := make([]*string, len(res.Columns))
+			for i, raw := range rows.RawValues() {
+				if raw != nil {
+					value := string(raw)
+					values[i] = &value
+				}
+			}
+			res.Row = makeRow(res.Columns, values)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Query failed: %v", err)
+	}
+	result.Metadata["query_time_ms"] = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// queryMySQL runs the query over the text protocol
+func queryMySQL(ctx context.Context, cfg *DatabaseConfig, result *CheckResult) (*databaseResult, error) {
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = cfg.Username
+	mysqlConfig.Passwd = cfg.password()
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.port()))
+	mysqlConfig.DBName = cfg.Database
+	mysqlConfig.Timeout = cfg.Timeout.Std()
+	if cfg.TLS {
+		mysqlConfig.TLS = &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+	}
+
+	connector, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %v", err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	start := time.Now()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("MySQL connection failed: %v", err)
+	}
+	defer conn.Close()
+	result.Metadata["connect_time_ms"] = time.Since(start).Milliseconds()
+
+	// The driver does not expose the version sent in the handshake. It is
+	// only recorded, so a failure is left for the check's own query to report.
+	res := &databaseResult{}
+	if err := conn.QueryRowContext(ctx, "SELECT VERSION()").Scan(&res.ServerVersion); err != nil {
+		res.ServerVersion = ""
+	}
+
+	start = time.Now()
+	rows, err := conn.QueryContext(ctx, cfg.query())
+	if err != nil {
+		return nil, fmt.Errorf("Query failed: %v", err)
+	}
+	defer rows.Close()
+
+	if res.Columns, err = rows.Columns(); err != nil {
+		return nil, fmt.Errorf("Query failed: %v", err)
+	}
+	for rows.Next() {
+		res.RowCount++
+		if res.Row != nil {
+			continue
+		}
+		scanned := make([]sql.NullString, len(res.Columns))
+		targets := make([]any, len(scanned))
+		for i := range scanned {
+			targets[i] = &scanned[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("Failed to read row: %v", err)
+		}
+		values := make([]*string, len(scanned))
+		for i, value := range scanned {
+			if value.Valid {
+				values[i] = &value.String
+			}
+		}
+		res.Row = makeRow(res.Columns, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Query failed: %v", err)
+	}
+	result.Metadata["query_time_ms"] = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// queryRedis runs a command split on whitespace. Replies made of "field:value"
+// lines, such as INFO, become one column per field; other replies are a single
+// "value" column.
+func queryRedis(ctx context.Context, cfg *DatabaseConfig, result *CheckResult) (*databaseResult, error) {
+	options := &redis.Options{
+		Addr:            net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.port())),
+		Username:        cfg.Username,
+		Password:        cfg.password(),
+		Protocol:        2,
+		MaxRetries:      -1, // Retries are handled by the check's retry policy
+		PoolSize:        1,
+		DialTimeout:     cfg.Timeout.Std(),
+		DisableIdentity: true,
+	}
+	if cfg.Database != "" {
+		options.DB, _ = strconv.Atoi(cfg.Database)
+	}
+	if cfg.TLS {
+		options.TLSConfig = &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+	}
+	client := redis.NewClient(options)
+	defer client.Close()
+
+	// Connecting also authenticates and selects the database
+	start := time.Now()
+	conn := client.Conn()
+	defer conn.Close()
+	if err := conn.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("Redis connection failed: %v", err)
+	}
+	result.Metadata["connect_time_ms"] = time.Since(start).Milliseconds()
+
+	var args []any
+	for _, field := range strings.Fields(cfg.query()) {
+		args = append(args, field)
+	}
+
+	start = time.Now()
+	reply, err := conn.Do(ctx, args...).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("Command failed: %v", err)
+	}
+	result.Metadata["query_time_ms"] = time.Since(start).Milliseconds()
+
+	res := &databaseResult{RowCount: 1}
+	if text, ok := reply.(string); ok && strings.Contains(text, "\n") {
+		columns, values := parseRedisFields(text)
+		res.Columns = columns
+		res.Row = makeRow(columns, values)
+		if version := res.Row["redis_version"]; version != nil {
+			res.ServerVersion = *version
+		}
+		return res, nil
+	}
+
+	res.Columns = []string{"value"}
+	var value *string
+	if reply != nil {
+		text := fmt.Sprint(reply)
+		value = &text
+	}
+	res.Row = makeRow(res.Columns, []*string{value})
+	return res, nil
+}
+
+// parseRedisFields parses "field:value" lines, skipping "# Section" headers
+func parseRedisFields(text string) ([]string, []*string) {
+	var columns []string
+	var values []*string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if field, value, ok := strings.Cut(line, ":"); ok {
+			columns = append(columns, field)
+			values = append(values, &value)
+		}
+	}
+	return columns, values
+}
+
+func makeRow(columns []string, values []*string) map[string]*string {
+	row := make(map[string]*string, len(columns))
+	for i, column := range columns {
+		row[column] = values[i]
+	}
+	return row
+}
+
+// recordDatabaseResult adds the row to the result. Wide rows only record the
+// asserted columns.
+func recordDatabaseResult(result *CheckResult, cfg *DatabaseConfig, res *databaseResult) {
+	result.Metadata["row_count"] = res.RowCount
+	if res.ServerVersion != "" {
+		result.Metadata["server_version"] = res.ServerVersion
+	}
+	if res.Row == nil {
+		return
+	}
+
+	columns := res.Columns
+	if len(columns) > maxRecordedColumns {
+		columns = nil
+		for _, assertion := range cfg.Assertions {
+			columns = append(columns, assertion.column(res))
+		}
+		sort.Strings(columns)
+	}
+	result.Metadata["columns"] = res.Columns[:min(len(res.Columns), maxRecordedColumns)]
+
+	row := make(map[string]any, len(columns))
+	for _, column := range columns {
+		if value, ok := res.Row[column]; ok {
+			if value == nil {
+				row[column] = nil
+			} else {
+				row[column] = string(truncateBytes([]byte(*value), maxRecordedResponse))
+			}
+		}
+	}
+	result.Metadata["row"] = row
+}
+
+// column returns the column the assertion applies to
+func (a DatabaseAssertion) column(res *databaseResult) string {
+	if a.Column == "" && len(res.Columns) > 0 {
+		return res.Columns[0]
+	}
+	return a.Column
+}
+
+// check compares the asserted column of the first row with the value. Values
+// that both parse as numbers are compared numerically.
+func (a DatabaseAssertion) check(res *databaseResult) error {
+	if res.Row == nil {
+		return fmt.Errorf("query returned no rows")
+	}
+	column := a.column(res)
+	value, ok := res.Row[column]
+	if !ok {
+		return fmt.Errorf("column %q is not in the result", column)
+	}
+	if value == nil {
+		return fmt.Errorf("%s is NULL, expected %s %s", column, a.Operator, a.Value)
+	}
+
+	actual := *value
+	if a.Operator == "contains" {
+		if !strings.Contains(actual, a.Value) {
+			return fmt.Errorf("%s is %q, expected it to contain %q", column, actual, a.Value)
+		}
+		return nil
+	}
+
+	actualNumber, actualErr := strconv.ParseFloat(strings.TrimSpace(actual), 64)
+	expectedNumber, expectedErr := strconv.ParseFloat(a.Value, 64)
+	numeric := actualErr == nil && expectedErr == nil
+
+	var passed bool
+	switch a.Operator {
+	case "==":
+		passed = actual == a.Value || numeric && actualNumber == expectedNumber
+	case "!=":
+		passed = actual != a.Value && !(numeric && actualNumber == expectedNumber)
+	default:
+		if !numeric {
+			return fmt.Errorf("%s is %q, which cannot be compared with %s %s", column, actual, a.Operator, a.Value)
+		}
+		switch a.Operator {
+		case "<":
+			passed = actualNumber < expectedNumber
+		case "<=":
+			passed = actualNumber <= expectedNumber
+		case ">":
+			passed = actualNumber > expectedNumber
+		case ">=":
+			passed = actualNumber >= expectedNumber
+		}
+	}
+	if !passed {
+		return fmt.Errorf("%s is %q, expected %s %s", column, actual, a.Operator, a.Value)
+	}
+	return nil
+}
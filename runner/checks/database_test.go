@@ -0,0 +1,777 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestDatabaseAssertionCheck(t *testing.T) {
+	res := &databaseResult{
+		Columns: []string{"lag_seconds", "role", "version", "padded", "deleted_at"},
+		Row: map[string]*string{
+			"lag_seconds": stringPtr("12"),
+			"role":        stringPtr("master"),
+			"version":     stringPtr("16.2"),
+			"padded":      stringPtr(" 7 "),
+			"deleted_at":  nil,
+		},
+		RowCount: 1,
+	}
+
+	tests := []struct {
+		name      string
+		assertion DatabaseAssertion
+		wantErr   string
+	}{
+		{"numeric less than", DatabaseAssertion{"lag_seconds", "<", "30"}, ""},
+		{"numeric not less than", DatabaseAssertion{"lag_seconds", "<", "10"}, `lag_seconds is "12", expected < 10`},
+		{"numeric, not lexical, comparison", DatabaseAssertion{"lag_seconds", ">", "9"}, ""},
+		{"less or equal at the boundary", DatabaseAssertion{"lag_seconds", "<=", "12"}, ""},
+		{"greater or equal at the boundary", DatabaseAssertion{"lag_seconds", ">=", "12"}, ""},
+		{"numeric equality across formats", DatabaseAssertion{"lag_seconds", "==", "12.0"}, ""},
+		{"numeric inequality across formats", DatabaseAssertion{"lag_seconds", "!=", "1.2e1"}, `expected != 1.2e1`},
+		{"whitespace around numbers", DatabaseAssertion{"padded", "==", "7"}, ""},
+		{"decimal comparison", DatabaseAssertion{"version", ">=", "16.10"}, ""},
+		{"string equality", DatabaseAssertion{"role", "==", "master"}, ""},
+		{"string equality is exact", DatabaseAssertion{"role", "==", "Master"}, `role is "master", expected == Master`},
+		{"string inequality", DatabaseAssertion{"role", "!=", "replica"}, ""},
+		{"contains", DatabaseAssertion{"role", "contains", "ast"}, ""},
+		{"contains missing", DatabaseAssertion{"role", "contains", "slave"}, "expected it to contain"},
+		{"ordering needs numbers", DatabaseAssertion{"role", "<", "z"}, "cannot be compared"},
+		{"default column is the first", DatabaseAssertion{"", "==", "12"}, ""},
+		{"null", DatabaseAssertion{"deleted_at", "==", ""}, "deleted_at is NULL"},
+		{"null is not different from a value", DatabaseAssertion{"deleted_at", "!=", "x"}, "is NULL"},
+		{"unknown column", DatabaseAssertion{"missing", "==", "1"}, `column "missing" is not in the result`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.assertion.check(res)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("check() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("check() = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatabaseAssertionCheckNoRows(t *testing.T) {
+	err := DatabaseAssertion{"count", "==", "0"}.check(&databaseResult{Columns: []string{"count"}})
+	if err == nil || !strings.Contains(err.Error(), "no rows") {
+		t.Errorf("check() = %v, want a no rows error", err)
+	}
+}
+
+func TestParseDatabaseAssertion(t *testing.T) {
+	tests := []struct {
+		entry  string
+		want   DatabaseAssertion
+		wantOK bool
+	}{
+		{"lag_seconds<30", DatabaseAssertion{"lag_seconds", "<", "30"}, true},
+		{"lag_seconds<=30", DatabaseAssertion{"lag_seconds", "<=", "30"}, true},
+		{"lag_seconds>30", DatabaseAssertion{"lag_seconds", ">", "30"}, true},
+		{"lag_seconds>=30", DatabaseAssertion{"lag_seconds", ">=", "30"}, true},
+		{"role==master", DatabaseAssertion{"role", "==", "master"}, true},
+		{"role!=replica", DatabaseAssertion{"role", "!=", "replica"}, true},
+		{" role == master ", DatabaseAssertion{"role", "==", "master"}, true},
+		{"==1", DatabaseAssertion{"", "==", "1"}, true},
+		{"version contains 16.", DatabaseAssertion{"version", "contains", "16."}, true},
+		{"note contains a<=b", DatabaseAssertion{"note", "contains", "a<=b"}, true},
+		{"role=master", DatabaseAssertion{}, false},
+		{"role", DatabaseAssertion{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got, ok := parseDatabaseAssertion(tt.entry)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseDatabaseAssertion(%q) = %+v, %v, want %+v, %v", tt.entry, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseRedisFields(t *testing.T) {
+	text := "# Replication\r\nrole:master\r\nconnected_slaves:1\r\nslave0:ip=10.0.0.2,port=6379,state=online\r\n\r\n# Server\r\nredis_version:7.2.4\r\nempty:\r\nnot a field\r\n"
+
+	columns, values := parseRedisFields(text)
+
+	wantColumns := []string{"role", "connected_slaves", "slave0", "redis_version", "empty"}
+	wantValues := []string{"master", "1", "ip=10.0.0.2,port=6379,state=online", "7.2.4", ""}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Fatalf("columns = %q, want %q", columns, wantColumns)
+	}
+	for i, value := range values {
+		if value == nil || *value != wantValues[i] {
+			t.Errorf("%s = %v, want %q", columns[i], value, wantValues[i])
+		}
+	}
+}
+
+// fakeRedis is a minimal RESP2 server. It rejects HELLO as Redis 5 does, so
+// clients fall back to AUTH and SELECT.
+type fakeRedis struct {
+	password string
+	data     map[string]string
+	info     string
+}
+
+// start serves connections until the test ends and returns the port
+func (f *fakeRedis) start(t *testing.T) int {
+	return serveFakeDatabase(t, f.serve)
+}
+
+// serveFakeDatabase accepts connections on a loopback port until the test
+// ends, handling each with serve, and returns the port
+func serveFakeDatabase(t *testing.T, serve func(net.Conn)) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authenticated := f.password == ""
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(args[0])
+		var reply string
+		switch {
+		case command == "HELLO":
+			reply = "-ERR unknown command 'HELLO'\r\n"
+		case command == "AUTH":
+			if args[len(args)-1] != f.password {
+				reply = "-WRONGPASS invalid username-password pair\r\n"
+			} else {
+				authenticated = true
+				reply = "+OK\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case command == "SELECT":
+			reply = "+OK\r\n"
+		case command == "PING":
+			reply = "+PONG\r\n"
+		case command == "INFO":
+			reply = bulkString(f.info)
+		case command == "DBSIZE":
+			reply = fmt.Sprintf(":%d\r\n", len(f.data))
+		case command == "GET" && len(args) == 2:
+			if value, ok := f.data[args[1]]; ok {
+				reply = bulkString(value)
+			} else {
+				reply = "$-1\r\n"
+			}
+		default:
+			reply = fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func bulkString(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+// readRESPCommand reads a command sent as an array of bulk strings
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("bad array length %q", line)
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("bad bulk length %q", header)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisCheck(t *testing.T) {
+	server := &fakeRedis{
+		password: "s3cret",
+		data:     map[string]string{"queue:depth": "42"},
+		info:     "# Server\r\nredis_version:7.2.4\r\n# Replication\r\nrole:master\r\nmaster_repl_offset:1000\r\n",
+	}
+	port := server.start(t)
+
+	tests := []struct {
+		name       string
+		password   string
+		database   string
+		query      string
+		assertions []DatabaseAssertion
+		wantErr    string
+		wantRow    map[string]any
+	}{
+		{name: "default ping", password: "s3cret", wantRow: map[string]any{"value": "PONG"}},
+		{name: "selects the database", password: "s3cret", database: "2", query: "DBSIZE", wantRow: map[string]any{"value": "1"}},
+		{
+			name: "info fields", password: "s3cret", query: "INFO replication",
+			assertions: []DatabaseAssertion{{"role", "==", "master"}, {"master_repl_offset", ">", "500"}},
+			wantRow:    map[string]any{"role": "master", "master_repl_offset": "1000", "redis_version": "7.2.4"},
+		},
+		{
+			name: "get", password: "s3cret", query: "GET queue:depth",
+			assertions: []DatabaseAssertion{{"", "<", "100"}},
+			wantRow:    map[string]any{"value": "42"},
+		},
+		{
+			name: "missing key is null", password: "s3cret", query: "GET nothing",
+			assertions: []DatabaseAssertion{{"value", "==", "0"}},
+			wantErr:    "value is NULL", wantRow: map[string]any{"value": nil},
+		},
+		{
+			name: "failed assertion", password: "s3cret", query: "GET queue:depth",
+			assertions: []DatabaseAssertion{{"value", "<", "10"}},
+			wantErr:    `Assertion failed: value is "42", expected < 10`,
+		},
+		{name: "wrong password", password: "wrong", wantErr: "Redis connection failed"},
+		{name: "unknown command", password: "s3cret", query: "FLUSHALL", wantErr: "Command failed: ERR unknown command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDatabaseConfig()
+			cfg.Engine = EngineRedis
+			cfg.Host = "127.0.0.1"
+			cfg.Port = port
+			cfg.Password = tt.password
+			cfg.Database = tt.database
+			cfg.Query = tt.query
+			cfg.Assertions = tt.assertions
+
+			result, err := RunDatabaseCheck(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("RunDatabaseCheck: %v", err)
+			}
+
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+			} else if result.Status != StatusError || !strings.Contains(result.ErrorMessage, tt.wantErr) {
+				t.Fatalf("status = %q (%s), want an error containing %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+
+			if tt.wantRow != nil {
+				row, _ := result.Metadata["row"].(map[string]any)
+				if !reflect.DeepEqual(row, tt.wantRow) {
+					t.Errorf("row = %v, want %v", row, tt.wantRow)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisCheckServerVersion(t *testing.T) {
+	server := &fakeRedis{info: "# Server\r\nredis_version:6.2.14\r\n"}
+	port := server.start(t)
+
+	cfg := NewDatabaseConfig()
+	cfg.Engine = EngineRedis
+	cfg.Host = "127.0.0.1"
+	cfg.Port = port
+	cfg.Query = "INFO server"
+
+	result, err := RunDatabaseCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunDatabaseCheck: %v", err)
+	}
+	if result.Metadata["server_version"] != "6.2.14" {
+		t.Errorf("server_version = %v, want 6.2.14", result.Metadata["server_version"])
+	}
+}
+
+// fakeSQLResult is the result a fake SQL server returns for a query. Nil
+// values are NULL.
+type fakeSQLResult struct {
+	columns []string
+	rows    [][]*string
+	err     string
+}
+
+// fakeSQLResults are served by both fakePostgres and fakeMySQL
+var fakeSQLResults = map[string]fakeSQLResult{
+	"SELECT 1": {columns: []string{"1"}, rows: [][]*string{{stringPtr("1")}}},
+	"SELECT lag_seconds, role, note FROM replication": {
+		columns: []string{"lag_seconds", "role", "note"},
+		rows: [][]*string{
+			{stringPtr("12"), stringPtr("primary"), nil},
+			{stringPtr("40"), stringPtr("replica"), stringPtr("lagging")},
+		},
+	},
+	"SELECT id FROM empty":   {columns: []string{"id"}},
+	"SELECT id FROM missing": {err: `relation "missing" does not exist`},
+}
+
+// fakePostgres is a minimal PostgreSQL server speaking the simple query
+// protocol, with cleartext password authentication
+type fakePostgres struct {
+	password string
+	version  string
+}
+
+func (f *fakePostgres) start(t *testing.T) int {
+	return serveFakeDatabase(t, f.serve)
+}
+
+func (f *fakePostgres) serve(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+
+	msg, err := backend.ReceiveStartupMessage()
+	if err != nil {
+		return
+	}
+	startup, ok := msg.(*pgproto3.StartupMessage)
+	if !ok {
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationCleartextPassword{})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	if err := backend.SetAuthType(pgproto3.AuthTypeCleartextPassword); err != nil {
+		return
+	}
+	msg, err = backend.Receive()
+	if err != nil {
+		return
+	}
+	if password, ok := msg.(*pgproto3.PasswordMessage); !ok || password.Password != f.password {
+		backend.Send(&pgproto3.ErrorResponse{
+			Severity: "FATAL",
+			Code:     "28P01",
+			Message:  fmt.Sprintf("password authentication failed for user %q", startup.Parameters["user"]),
+		})
+		backend.Flush()
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "server_version", Value: f.version})
+	backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		query, ok := msg.(*pgproto3.Query)
+		if !ok {
+			return
+		}
+
+		result, ok := fakeSQLResults[query.String]
+		switch {
+		case !ok:
+			backend.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "42601", Message: "syntax error"})
+		case result.err != "":
+			backend.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "42P01", Message: result.err})
+		default:
+			description := &pgproto3.RowDescription{}
+			for _, column := range result.columns {
+				description.Fields = append(description.Fields, pgproto3.FieldDescription{
+					Name:         []byte(column),
+					DataTypeOID:  25, // text
+					DataTypeSize: -1,
+					TypeModifier: -1,
+				})
+			}
+			backend.Send(description)
+			for _, row := range result.rows {
+				values := make([][]byte, len(row))
+				for i, value := range row {
+					if value != nil {
+						values[i] = []byte(*value)
+					}
+				}
+				backend.Send(&pgproto3.DataRow{Values: values})
+			}
+			backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("SELECT %d", len(result.rows)))})
+		}
+		backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		if err := backend.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// fakeMySQL is a minimal MySQL server speaking the text protocol, with
+// mysql_native_password authentication
+type fakeMySQL struct {
+	password string
+	version  string
+}
+
+func (f *fakeMySQL) start(t *testing.T) int {
+	return serveFakeDatabase(t, f.serve)
+}
+
+// mysqlCapabilities are the capability flags the fake announces: long
+// passwords, protocol 4.1, transactions, secure connections, multiple
+// results and pluggable authentication
+const mysqlCapabilities uint32 = 0x00000001 | 0x00000200 | 0x00002000 | 0x00008000 | 0x00020000 | 0x00080000
+
+const (
+	mysqlTypeVarString    = 0xfd
+	mysqlStatusAutocommit = 0x02
+)
+
+// mysqlPackets reads and writes packets, tracking the sequence number
+type mysqlPackets struct {
+	conn net.Conn
+	seq  byte
+}
+
+func (p *mysqlPackets) read() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(p.conn, header[:]); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	if _, err := io.ReadFull(p.conn, payload); err != nil {
+		return nil, err
+	}
+	p.seq = header[3] + 1
+	return payload, nil
+}
+
+func (p *mysqlPackets) write(payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), p.seq}
+	p.seq++
+	_, err := p.conn.Write(append(header, payload...))
+	return err
+}
+
+func (p *mysqlPackets) writeOK() error {
+	return p.write([]byte{0x00, 0, 0, mysqlStatusAutocommit, 0, 0, 0})
+}
+
+func (p *mysqlPackets) writeEOF() error {
+	return p.write([]byte{0xfe, 0, 0, mysqlStatusAutocommit, 0})
+}
+
+func (p *mysqlPackets) writeError(code uint16, state, message string) error {
+	payload := []byte{0xff, byte(code), byte(code >> 8), '#'}
+	payload = append(payload, state...)
+	return p.write(append(payload, message...))
+}
+
+func appendMySQLString(b []byte, s string) []byte {
+	if len(s) < 251 {
+		b = append(b, byte(len(s)))
+	} else {
+		b = append(b, 0xfc, byte(len(s)), byte(len(s)>>8))
+	}
+	return append(b, s...)
+}
+
+// mysqlNativePassword scrambles the password with the server's nonce as
+// mysql_native_password does
+func mysqlNativePassword(nonce []byte, password string) []byte {
+	if password == "" {
+		return nil
+	}
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	scramble := sha1.Sum(append(append([]byte{}, nonce...), stage2[:]...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble[:]
+}
+
+func (f *fakeMySQL) serve(conn net.Conn) {
+	defer conn.Close()
+	packets := &mysqlPackets{conn: conn}
+
+	nonce := []byte("0123456789abcdefghij")
+	handshake := []byte{10}
+	handshake = append(handshake, f.version...)
+	handshake = append(handshake, 0, 1, 0, 0, 0)
+	handshake = append(handshake, nonce[:8]...)
+	capabilities := mysqlCapabilities
+	handshake = append(handshake, 0, byte(capabilities), byte(capabilities>>8))
+	handshake = append(handshake, 0x21, mysqlStatusAutocommit, 0)
+	handshake = append(handshake, byte(capabilities>>16), byte(capabilities>>24), 21)
+	handshake = append(handshake, make([]byte, 10)...)
+	handshake = append(handshake, nonce[8:]...)
+	handshake = append(handshake, 0)
+	handshake = append(handshake, "mysql_native_password\x00"...)
+	if err := packets.write(handshake); err != nil {
+		return
+	}
+
+	// Capabilities, max packet size, character set and filler come before the user
+	response, err := packets.read()
+	if err != nil || len(response) < 33 {
+		return
+	}
+	user, rest, _ := bytes.Cut(response[32:], []byte{0})
+	if len(rest) == 0 || len(rest) < 1+int(rest[0]) {
+		return
+	}
+	if !bytes.Equal(rest[1:1+int(rest[0])], mysqlNativePassword(nonce, f.password)) {
+		packets.writeError(1045, "28000", fmt.Sprintf("Access denied for user '%s'", user))
+		return
+	}
+	if err := packets.writeOK(); err != nil {
+		return
+	}
+
+	for {
+		packets.seq = 0
+		command, err := packets.read()
+		if err != nil || len(command) == 0 {
+			return
+		}
+		switch command[0] {
+		case 0x01: // COM_QUIT
+			return
+		case 0x03: // COM_QUERY
+			err = f.query(packets, string(command[1:]))
+		default:
+			err = packets.writeError(1047, "08S01", "Unknown command")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeMySQL) query(packets *mysqlPackets, query string) error {
+	result, ok := fakeSQLResults[query]
+	if query == "SELECT VERSION()" {
+		result, ok = fakeSQLResult{columns: []string{"VERSION()"}, rows: [][]*string{{&f.version}}}, true
+	}
+	switch {
+	case !ok:
+		return packets.writeError(1064, "42000", "You have an error in your SQL syntax")
+	case result.err != "":
+		return packets.writeError(1146, "42S02", result.err)
+	}
+
+	if err := packets.write([]byte{byte(len(result.columns))}); err != nil {
+		return err
+	}
+	for _, column := range result.columns {
+		definition := appendMySQLString(nil, "def")
+		for _, name := range []string{"", "", "", column, column} {
+			definition = appendMySQLString(definition, name)
+		}
+		definition = append(definition, 0x0c, 0x21, 0, 0, 1, 0, 0, mysqlTypeVarString, 0, 0, 0, 0, 0)
+		if err := packets.write(definition); err != nil {
+			return err
+		}
+	}
+	if err := packets.writeEOF(); err != nil {
+		return err
+	}
+	for _, row := range result.rows {
+		var payload []byte
+		for _, value := range row {
+			if value == nil {
+				payload = append(payload, 0xfb)
+			} else {
+				payload = appendMySQLString(payload, *value)
+			}
+		}
+		if err := packets.write(payload); err != nil {
+			return err
+		}
+	}
+	return packets.writeEOF()
+}
+
+// closedPort returns a loopback port nothing listens on
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestSQLChecks(t *testing.T) {
+	engines := []struct {
+		engine      string
+		port        int
+		version     string
+		connectFail string
+	}{
+		{EnginePostgres, (&fakePostgres{password: "s3cret", version: "16.2"}).start(t), "16.2", "PostgreSQL connection failed"},
+		{EngineMySQL, (&fakeMySQL{password: "s3cret", version: "8.0.36"}).start(t), "8.0.36", "MySQL connection failed"},
+	}
+
+	tests := []struct {
+		name         string
+		password     string
+		query        string
+		assertions   []DatabaseAssertion
+		wantErr      string
+		wantRowCount int
+		wantRow      map[string]any
+	}{
+		{name: "default select", wantRowCount: 1, wantRow: map[string]any{"1": "1"}},
+		{
+			name: "first row", query: "SELECT lag_seconds, role, note FROM replication",
+			assertions:   []DatabaseAssertion{{"lag_seconds", "<", "30"}, {"role", "==", "primary"}},
+			wantRowCount: 2, wantRow: map[string]any{"lag_seconds": "12", "role": "primary", "note": nil},
+		},
+		{
+			name: "default column", query: "SELECT lag_seconds, role, note FROM replication",
+			assertions:   []DatabaseAssertion{{"", "<=", "12"}},
+			wantRowCount: 2,
+		},
+		{
+			name: "null value", query: "SELECT lag_seconds, role, note FROM replication",
+			assertions: []DatabaseAssertion{{"note", "contains", "lag"}},
+			wantErr:    "Assertion failed: note is NULL",
+		},
+		{
+			name: "failed assertion", query: "SELECT lag_seconds, role, note FROM replication",
+			assertions: []DatabaseAssertion{{"lag_seconds", "<", "10"}},
+			wantErr:    `Assertion failed: lag_seconds is "12", expected < 10`,
+		},
+		{
+			name: "no rows", query: "SELECT id FROM empty",
+			assertions: []DatabaseAssertion{{"id", "==", "1"}},
+			wantErr:    "Assertion failed: query returned no rows",
+		},
+		{name: "no rows without assertions", query: "SELECT id FROM empty", wantRowCount: 0},
+		{name: "query error", query: "SELECT id FROM missing", wantErr: `relation "missing" does not exist`},
+		{name: "wrong password", password: "wrong", wantErr: "connection failed"},
+	}
+
+	for _, engine := range engines {
+		for _, tt := range tests {
+			t.Run(engine.engine+"/"+tt.name, func(t *testing.T) {
+				cfg := NewDatabaseConfig()
+				cfg.Engine = engine.engine
+				cfg.Host = "127.0.0.1"
+				cfg.Port = engine.port
+				cfg.Username = "monitor"
+				cfg.Password = "s3cret"
+				if tt.password != "" {
+					cfg.Password = tt.password
+				}
+				cfg.Database = "app"
+				cfg.Query = tt.query
+				cfg.Assertions = tt.assertions
+
+				result, err := RunDatabaseCheck(context.Background(), cfg)
+				if err != nil {
+					t.Fatalf("RunDatabaseCheck: %v", err)
+				}
+
+				if tt.wantErr == "" {
+					if result.Status != StatusSuccess {
+						t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+					}
+					if result.Metadata["row_count"] != tt.wantRowCount {
+						t.Errorf("row_count = %v, want %d", result.Metadata["row_count"], tt.wantRowCount)
+					}
+					if result.Metadata["server_version"] != engine.version {
+						t.Errorf("server_version = %v, want %s", result.Metadata["server_version"], engine.version)
+					}
+				} else if result.Status != StatusError || !strings.Contains(result.ErrorMessage, tt.wantErr) {
+					t.Fatalf("status = %q (%s), want an error containing %q", result.Status, result.ErrorMessage, tt.wantErr)
+				}
+
+				if tt.wantRow != nil {
+					row, _ := result.Metadata["row"].(map[string]any)
+					if !reflect.DeepEqual(row, tt.wantRow) {
+						t.Errorf("row = %v, want %v", row, tt.wantRow)
+					}
+				}
+			})
+		}
+
+		t.Run(engine.engine+"/connection refused", func(t *testing.T) {
+			cfg := NewDatabaseConfig()
+			cfg.Engine = engine.engine
+			cfg.Host = "127.0.0.1"
+			cfg.Port = closedPort(t)
+			cfg.Username = "monitor"
+			cfg.Timeout = Duration(2 * time.Second)
+
+			result, err := RunDatabaseCheck(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("RunDatabaseCheck: %v", err)
+			}
+			if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, engine.connectFail) {
+				t.Errorf("status = %q (%s), want an error starting with %q", result.Status, result.ErrorMessage, engine.connectFail)
+			}
+		})
+	}
+}
@@ -4,84 +4,282 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"os"
-	"strconv"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSConfig configures a DNS check. JSON field names match the DnsCheck spec.
+type DNSConfig struct {
+	Hostname       string   `json:"domain"`
+	RecordType     string   `json:"recordType"`
+	ExpectedIP     string   `json:"expectedIp"`
+	ExpectedIPs    []string `json:"expectedIps"`
+	ExpectedValues []string `json:"expectedValues"` // Each must match at least one record
+	Match          string   `json:"match"`          // How expectedValues are compared: exact, contains or regex
+	Timeout        Duration `json:"timeout"`
+	Server         string   `json:"nameserver"`
+
+	Consistency *DNSConsistency `json:"consistency"` // Compare the answers of several nameservers
+	DNSSEC      *DNSSECConfig   `json:"dnssec"`      // Validate signatures up to a trust anchor
+}
+
+// Supported ways of comparing expected values with records
+const (
+	MatchExact    = "exact"
+	MatchContains = "contains"
+	MatchRegex    = "regex"
 )
 
-// RunDNSCheck performs a DNS resolution check
-// Configuration via environment variables:
+// dnsRecordTypes are the record types a DNS check can query
+var dnsRecordTypes = map[string]uint16{
+	"A":     dns.TypeA,
+	"AAAA":  dns.TypeAAAA,
+	"CNAME": dns.TypeCNAME,
+	"MX":    dns.TypeMX,
+	"TXT":   dns.TypeTXT,
+	"NS":    dns.TypeNS,
+	"SRV":   dns.TypeSRV,
+	"CAA":   dns.TypeCAA,
+	"SOA":   dns.TypeSOA,
+}
+
+// NewDNSConfig returns a DNSConfig with defaults applied
+func NewDNSConfig() *DNSConfig {
+	return &DNSConfig{
+		RecordType: "A",
+		Match:      MatchExact,
+		Timeout:    Duration(10 * time.Second),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
 //   - DNS_HOSTNAME: Hostname to resolve (required)
+//   - DNS_RECORD_TYPE: A, AAAA, CNAME, MX, TXT, NS, SRV, CAA or SOA (default: A)
 //   - DNS_EXPECTED_IPS: Comma-separated list of expected IPs (optional)
+//   - DNS_EXPECTED_VALUES: Comma-separated list of values that must each match a record (optional)
+//   - DNS_MATCH: How expected values are compared: exact, contains or regex (default: exact)
 //   - DNS_TIMEOUT: Timeout in seconds (default: 10)
 //   - DNS_SERVER: Custom DNS server to use (optional, e.g., "8.8.8.8:53")
-func RunDNSCheck() (*CheckResult, error) {
-	result := NewCheckResult()
+//   - DNS_NAMESERVERS: Comma-separated nameservers whose answers must agree (optional)
+//   - DNS_AUTHORITATIVE: Compare the answers of every authoritative nameserver for the zone (optional)
+//   - DNS_ZONE: Zone whose nameservers and SOA serial are checked (default: the hostname's zone)
+//   - DNS_DNSSEC: Validate the answer's DNSSEC signatures (optional)
+//   - DNS_TRUST_ANCHORS: Comma-separated DS or DNSKEY records to validate up to (default: the root zone KSKs)
+//   - DNS_DNSSEC_EXPIRY_WARNING: Fail when a signature expires within this duration (default: 24h)
+func (c *DNSConfig) LoadEnv() error {
+	envString("DNS_HOSTNAME", &c.Hostname)
+	if c.Hostname == "" {
+		return fmt.Errorf("DNS_HOSTNAME environment variable is required")
+	}
+
+	envString("DNS_RECORD_TYPE", &c.RecordType)
+	envList("DNS_EXPECTED_IPS", &c.ExpectedIPs)
+	envList("DNS_EXPECTED_VALUES", &c.ExpectedValues)
+	envString("DNS_MATCH", &c.Match)
+	envString("DNS_SERVER", &c.Server)
 
-	// Parse configuration
-	hostname := os.Getenv("DNS_HOSTNAME")
-	if hostname == "" {
-		return nil, fmt.Errorf("DNS_HOSTNAME environment variable is required")
+	consistency := &DNSConsistency{}
+	envList("DNS_NAMESERVERS", &consistency.Nameservers)
+	envString("DNS_ZONE", &consistency.Zone)
+	if err := envBool("DNS_AUTHORITATIVE", &consistency.Authoritative); err != nil {
+		return err
+	}
+	if len(consistency.Nameservers) > 0 || consistency.Authoritative {
+		c.Consistency = consistency
 	}
 
-	timeout := 10
-	if timeoutStr := os.Getenv("DNS_TIMEOUT"); timeoutStr != "" {
-		if t, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = t
-		}
+	dnssec := NewDNSSECConfig()
+	enabled := false
+	if err := envBool("DNS_DNSSEC", &enabled); err != nil {
+		return err
+	}
+	envList("DNS_TRUST_ANCHORS", &dnssec.TrustAnchors)
+	if err := envDuration("DNS_DNSSEC_EXPIRY_WARNING", &dnssec.ExpiryWarning); err != nil {
+		return err
+	}
+	if enabled {
+		c.DNSSEC = dnssec
 	}
 
-	// Create resolver
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{
-				Timeout: time.Duration(timeout) * time.Second,
-			}
-			// Use custom DNS server if specified
-			if dnsServer := os.Getenv("DNS_SERVER"); dnsServer != "" {
-				address = dnsServer
+	return envDuration("DNS_TIMEOUT", &c.Timeout)
+}
+
+// expectedIPs merges the single and list forms of the expected IPs
+func (c *DNSConfig) expectedIPs() []string {
+	if c.ExpectedIP == "" {
+		return c.ExpectedIPs
+	}
+	return append([]string{c.ExpectedIP}, c.ExpectedIPs...)
+}
+
+// Validate reports whether the configuration is complete
+func (c *DNSConfig) Validate() error {
+	if c.Hostname == "" {
+		return fmt.Errorf("domain is required")
+	}
+	if _, ok := dnsRecordTypes[strings.ToUpper(c.RecordType)]; !ok {
+		return fmt.Errorf("unsupported record type: %s", c.RecordType)
+	}
+	if len(c.expectedIPs()) > 0 && c.qtype() != dns.TypeA && c.qtype() != dns.TypeAAAA {
+		return fmt.Errorf("expected IPs only apply to A and AAAA records")
+	}
+	switch c.Match {
+	case MatchExact, MatchContains:
+	case MatchRegex:
+		for _, pattern := range c.ExpectedValues {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid expected value pattern %q: %w", pattern, err)
 			}
-			return d.Dial(network, address)
-		},
+		}
+	default:
+		return fmt.Errorf("match must be one of exact, contains or regex")
+	}
+	if c.Consistency != nil {
+		if c.DNSSEC != nil {
+			return fmt.Errorf("dnssec cannot be combined with consistency checks")
+		}
+		return c.Consistency.validate()
+	}
+	if c.DNSSEC != nil {
+		return c.DNSSEC.validate()
+	}
+	return nil
+}
+
+// qtype returns the DNS query type for the configured record type
+func (c *DNSConfig) qtype() uint16 {
+	return dnsRecordTypes[strings.ToUpper(c.RecordType)]
+}
+
+// dnsChecker runs DNS resolution checks
+type dnsChecker struct{}
+
+func init() {
+	Register(dnsChecker{})
+}
+
+func (dnsChecker) Name() string {
+	return "dns"
+}
+
+func (dnsChecker) NewConfig() Config {
+	return NewDNSConfig()
+}
+
+func (dnsChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*DNSConfig]("dns", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunDNSCheck(ctx, c)
+}
+
+// DNSRecord is a record returned by a DNS check, with its value in
+// presentation format (e.g. "10 mail.example.com" for an MX record)
+type DNSRecord struct {
+	Value string `json:"value"`
+	TTL   uint32 `json:"ttl"`
+}
+
+// RunDNSCheck queries a record type and compares the answers with the expected values
+func RunDNSCheck(ctx context.Context, cfg *DNSConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	servers, err := dnsServers(cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	recordType := strings.ToUpper(cfg.RecordType)
+	result.Metadata["hostname"] = cfg.Hostname
+	result.Metadata["record_type"] = recordType
+
+	if cfg.Consistency != nil {
+		runDNSConsistencyCheck(ctx, cfg, servers, result)
+		return result, nil
 	}
 
 	// Perform DNS lookup with timing
-	ctx := context.Background()
 	start := time.Now()
-	ips, err := resolver.LookupHost(ctx, hostname)
+	query := newDNSQuery(cfg.Hostname, cfg.qtype(), cfg.DNSSEC != nil)
+	response, server, err := dnsExchange(ctx, servers, query, cfg.Timeout.Std())
 	elapsed := time.Since(start)
 
 	result.ResponseTimeMs = elapsed.Milliseconds()
-	result.Metadata["hostname"] = hostname
 
 	if err != nil {
-		result.Status = "error"
+		result.Status = StatusError
 		result.ErrorMessage = fmt.Sprintf("DNS lookup failed: %v", err)
 		return result, nil
 	}
 
-	if len(ips) == 0 {
-		result.Status = "error"
-		result.ErrorMessage = "No IP addresses resolved"
+	result.Metadata["server"] = server
+	result.Metadata["rcode"] = dns.RcodeToString[response.Rcode]
+
+	if response.Rcode != dns.RcodeSuccess {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("DNS lookup failed: %s", dns.RcodeToString[response.Rcode])
+		return result, nil
+	}
+
+	records := dnsRecords(response.Answer, cfg.qtype())
+	if len(records) == 0 {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("No %s records found", recordType)
 		return result, nil
 	}
 
-	result.Metadata["resolved_ips"] = ips
-	result.Metadata["ip_count"] = len(ips)
+	values := make([]string, len(records))
+	minTTL := records[0].TTL
+	for i, record := range records {
+		values[i] = record.Value
+		if record.TTL < minTTL {
+			minTTL = record.TTL
+		}
+	}
+
+	result.Metadata["records"] = records
+	result.Metadata["record_count"] = len(records)
+	result.Metadata["min_ttl"] = minTTL
+	if recordType == "A" || recordType == "AAAA" {
+		result.Metadata["resolved_ips"] = values
+		result.Metadata["ip_count"] = len(values)
+	}
 
+	if err := cfg.checkExpected(values); err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	if cfg.DNSSEC != nil {
+		runDNSSECValidation(ctx, cfg, server, response, result)
+		return result, nil
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// checkExpected compares record values with the expected IPs and values
+func (c *DNSConfig) checkExpected(values []string) error {
 	// Validate expected IPs if provided
-	if expectedIPsStr := os.Getenv("DNS_EXPECTED_IPS"); expectedIPsStr != "" {
-		expectedIPs := strings.Split(expectedIPsStr, ",")
+	if expectedIPs := c.expectedIPs(); len(expectedIPs) > 0 {
 		expectedMap := make(map[string]bool)
 		for _, ip := range expectedIPs {
-			expectedMap[strings.TrimSpace(ip)] = true
+			expectedMap[ip] = true
 		}
 
 		// Check if at least one expected IP was resolved
 		foundExpected := false
-		for _, ip := range ips {
+		for _, ip := range values {
 			if expectedMap[ip] {
 				foundExpected = true
 				break
@@ -89,12 +287,149 @@ func RunDNSCheck() (*CheckResult, error) {
 		}
 
 		if !foundExpected {
-			result.Status = "error"
-			result.ErrorMessage = fmt.Sprintf("None of the expected IPs found. Expected: %v, Got: %v", expectedIPs, ips)
-			return result, nil
+			return fmt.Errorf("None of the expected IPs found. Expected: %v, Got: %v", expectedIPs, values)
 		}
 	}
 
-	result.Status = "success"
-	return result, nil
+	for _, expected := range c.ExpectedValues {
+		if !matchAnyRecord(c.Match, expected, values) {
+			return fmt.Errorf("No %s record matches %q (%s). Got: %v", strings.ToUpper(c.RecordType), expected, c.Match, values)
+		}
+	}
+	return nil
+}
+
+// dnsServers returns the nameservers to query: the configured one, or the
+// system resolvers from /etc/resolv.conf
+func dnsServers(server string) ([]string, error) {
+	if server != "" {
+		return []string{withDefaultPort(server, "53")}, nil
+	}
+
+	conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil {
+		return nil, fmt.Errorf("no nameserver configured and failed to read system resolvers: %w", err)
+	}
+	servers := make([]string, len(conf.Servers))
+	for i, s := range conf.Servers {
+		servers[i] = net.JoinHostPort(s, conf.Port)
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no nameserver configured and no system resolvers found")
+	}
+	return servers, nil
+}
+
+// newDNSQuery builds a recursive query. DNSSEC queries ask for signatures and
+// disable validation by the resolver so that bogus answers can be reported.
+func newDNSQuery(name string, qtype uint16, dnssec bool) *dns.Msg {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	if dnssec {
+		msg.SetEdns0(4096, true)
+		msg.CheckingDisabled = true
+	}
+	return msg
+}
+
+// dnsExchange sends the query to each server in turn until one responds,
+// returning the response and the server that sent it
+func dnsExchange(ctx context.Context, servers []string, msg *dns.Msg, timeout time.Duration) (*dns.Msg, string, error) {
+	var lastErr error
+	for _, server := range servers {
+		response, err := dnsQuery(ctx, msg, server, timeout)
+		if err == nil {
+			return response, server, nil
+		}
+		lastErr = err
+	}
+	return nil, "", lastErr
+}
+
+// dnsQuery sends a query over UDP, retrying over TCP if the response was truncated
+func dnsQuery(ctx context.Context, msg *dns.Msg, server string, timeout time.Duration) (*dns.Msg, error) {
+	client := &dns.Client{Timeout: timeout}
+	response, _, err := client.ExchangeContext(ctx, msg, server)
+	if err != nil {
+		return nil, err
+	}
+	if response.Truncated {
+		client.Net = "tcp"
+		response, _, err = client.ExchangeContext(ctx, msg, server)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// dnsRecords returns the answers of the queried type. Answers to A and AAAA
+// queries may also contain the CNAME chain that led to them, which is skipped.
+func dnsRecords(answers []dns.RR, qtype uint16) []DNSRecord {
+	var records []DNSRecord
+	for _, rr := range answers {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		records = append(records, DNSRecord{Value: dnsRecordValue(rr), TTL: rr.Header().Ttl})
+	}
+	return records
+}
+
+// dnsRecordValue formats a record's data without its name, class and TTL
+func dnsRecordValue(rr dns.RR) string {
+	switch r := rr.(type) {
+	case *dns.A:
+		return r.A.String()
+	case *dns.AAAA:
+		return r.AAAA.String()
+	case *dns.CNAME:
+		return strings.TrimSuffix(r.Target, ".")
+	case *dns.NS:
+		return strings.TrimSuffix(r.Ns, ".")
+	case *dns.MX:
+		return fmt.Sprintf("%d %s", r.Preference, strings.TrimSuffix(r.Mx, "."))
+	case *dns.TXT:
+		return strings.Join(r.Txt, "")
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, strings.TrimSuffix(r.Target, "."))
+	case *dns.CAA:
+		return fmt.Sprintf("%d %s %q", r.Flag, r.Tag, r.Value)
+	case *dns.SOA:
+		return fmt.Sprintf("%s %s %d %d %d %d %d", strings.TrimSuffix(r.Ns, "."), strings.TrimSuffix(r.Mbox, "."),
+			r.Serial, r.Refresh, r.Retry, r.Expire, r.Minttl)
+	}
+	// Fall back to the presentation format with the header stripped
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// matchAnyRecord reports whether any value matches the expectation. Exact and
+// contains comparisons ignore case and a trailing dot, as DNS names do.
+func matchAnyRecord(match, expected string, values []string) bool {
+	normalized := strings.ToLower(strings.TrimSuffix(expected, "."))
+	for _, value := range values {
+		switch match {
+		case MatchRegex:
+			if regexp.MustCompile(expected).MatchString(value) {
+				return true
+			}
+		case MatchContains:
+			if strings.Contains(strings.ToLower(value), normalized) {
+				return true
+			}
+		default:
+			if strings.ToLower(value) == normalized {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withDefaultPort appends port to address if it does not already have one
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(address, port)
 }
@@ -0,0 +1,247 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSConsistency configures a DNS check that queries several nameservers and
+// fails if their answers or SOA serials disagree, e.g. after a zone change
+// that has not reached every nameserver
+type DNSConsistency struct {
+	Nameservers   []string `json:"nameservers"`   // Nameservers to compare, e.g. "ns1.example.com" or "192.0.2.53:53"
+	Authoritative bool     `json:"authoritative"` // Also compare every NS listed for the zone
+	Zone          string   `json:"zone"`          // Zone whose SOA serial is compared (default: the zone containing the hostname)
+}
+
+func (c *DNSConsistency) validate() error {
+	if !c.Authoritative && len(c.Nameservers) < 2 {
+		return fmt.Errorf("consistency checks need at least two nameservers or authoritative set")
+	}
+	return nil
+}
+
+// NameserverAnswer is one nameserver's answer in a consistency check
+type NameserverAnswer struct {
+	Server         string   `json:"server"`
+	Records        []string `json:"records"`
+	SOASerial      uint32   `json:"soa_serial,omitempty"`
+	Rcode          string   `json:"rcode,omitempty"`
+	ResponseTimeMs int64    `json:"response_time"`
+	Error          string   `json:"error,omitempty"`
+
+	zone string // Owner of the SOA record, i.e. the zone apex
+}
+
+// runDNSConsistencyCheck queries every nameserver for the record and the
+// zone's SOA, and compares their answers. resolvers are used to look up the
+// zone's NS records in authoritative mode.
+func runDNSConsistencyCheck(ctx context.Context, cfg *DNSConfig, resolvers []string, result *CheckResult) {
+	consistency := cfg.Consistency
+	timeout := cfg.Timeout.Std()
+	start := time.Now()
+
+	servers := make([]string, 0, len(consistency.Nameservers))
+	for _, server := range consistency.Nameservers {
+		servers = append(servers, withDefaultPort(server, "53"))
+	}
+
+	zone := consistency.Zone
+	if consistency.Authoritative {
+		found, nameservers, err := lookupZoneNameservers(ctx, resolvers, zoneOrHostname(zone, cfg.Hostname), timeout)
+		if err != nil {
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Failed to find authoritative nameservers: %v", err)
+			result.ResponseTimeMs = time.Since(start).Milliseconds()
+			return
+		}
+		zone = found
+		for _, ns := range nameservers {
+			servers = appendUnique(servers, net.JoinHostPort(ns, "53"))
+		}
+	}
+	zone = zoneOrHostname(zone, cfg.Hostname)
+
+	answers := make([]NameserverAnswer, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answers[i] = queryNameserver(ctx, server, cfg.Hostname, cfg.qtype(), zone, timeout)
+		}()
+	}
+	wg.Wait()
+
+	result.ResponseTimeMs = time.Since(start).Milliseconds()
+	result.Metadata["nameservers"] = answers
+	if answers[0].zone != "" {
+		zone = answers[0].zone
+	}
+	result.Metadata["zone"] = zone
+
+	for _, answer := range answers {
+		if answer.Error != "" {
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Nameserver %s failed: %s", answer.Server, answer.Error)
+			return
+		}
+	}
+
+	reference := answers[0]
+	for _, answer := range answers[1:] {
+		if !equalRecordSets(reference.Records, answer.Records) {
+			result.Metadata["consistent"] = false
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Nameservers disagree on %s records: %s", strings.ToUpper(cfg.RecordType), describeAnswers(answers, func(a NameserverAnswer) string {
+				return fmt.Sprintf("%v", a.Records)
+			}))
+			return
+		}
+	}
+	for _, answer := range answers[1:] {
+		if answer.SOASerial != reference.SOASerial {
+			result.Metadata["consistent"] = false
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Nameservers disagree on the SOA serial of %s: %s", zone, describeAnswers(answers, func(a NameserverAnswer) string {
+				return fmt.Sprintf("%d", a.SOASerial)
+			}))
+			return
+		}
+	}
+
+	result.Metadata["consistent"] = true
+	result.Metadata["soa_serial"] = reference.SOASerial
+	result.Metadata["records"] = reference.Records
+	result.Metadata["record_count"] = len(reference.Records)
+
+	if len(reference.Records) == 0 {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("No %s records found", strings.ToUpper(cfg.RecordType))
+		return
+	}
+	if err := cfg.checkExpected(reference.Records); err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return
+	}
+
+	result.Status = StatusSuccess
+}
+
+// queryNameserver asks one nameserver for the record and the zone's SOA serial
+func queryNameserver(ctx context.Context, server, name string, qtype uint16, zone string, timeout time.Duration) NameserverAnswer {
+	answer := NameserverAnswer{Server: server, Records: []string{}}
+
+	start := time.Now()
+	response, err := dnsQuery(ctx, newDNSQuery(name, qtype, false), server, timeout)
+	answer.ResponseTimeMs = time.Since(start).Milliseconds()
+	if err != nil {
+		answer.Error = err.Error()
+		return answer
+	}
+
+	answer.Rcode = dns.RcodeToString[response.Rcode]
+	if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
+		answer.Error = fmt.Sprintf("responded with %s", answer.Rcode)
+		return answer
+	}
+	for _, record := range dnsRecords(response.Answer, qtype) {
+		answer.Records = append(answer.Records, record.Value)
+	}
+	sort.Strings(answer.Records)
+
+	response, err = dnsQuery(ctx, newDNSQuery(zone, dns.TypeSOA, false), server, timeout)
+	if err != nil {
+		answer.Error = fmt.Sprintf("SOA query failed: %v", err)
+		return answer
+	}
+	record := soaRecord(response)
+	if record == nil {
+		answer.Error = fmt.Sprintf("no SOA record for %s", zone)
+		return answer
+	}
+	answer.SOASerial = record.Serial
+	answer.zone = strings.TrimSuffix(record.Hdr.Name, ".")
+
+	return answer
+}
+
+// lookupZoneNameservers finds the NS records of the zone containing name,
+// walking up one label at a time until NS records are found
+func lookupZoneNameservers(ctx context.Context, resolvers []string, name string, timeout time.Duration) (string, []string, error) {
+	labels := dns.SplitDomainName(name)
+	for i := range labels {
+		zone := strings.Join(labels[i:], ".")
+		response, _, err := dnsExchange(ctx, resolvers, newDNSQuery(zone, dns.TypeNS, false), timeout)
+		if err != nil {
+			return "", nil, err
+		}
+
+		var nameservers []string
+		for _, record := range dnsRecords(response.Answer, dns.TypeNS) {
+			nameservers = append(nameservers, record.Value)
+		}
+		if len(nameservers) > 0 {
+			sort.Strings(nameservers)
+			return zone, nameservers, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no NS records found for %s", name)
+}
+
+// soaRecord returns the SOA record in the answer or, for a name below the
+// zone apex, the authority section
+func soaRecord(response *dns.Msg) *dns.SOA {
+	for _, rr := range append(append([]dns.RR{}, response.Answer...), response.Ns...) {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa
+		}
+	}
+	return nil
+}
+
+func zoneOrHostname(zone, hostname string) string {
+	if zone != "" {
+		return strings.TrimSuffix(zone, ".")
+	}
+	return strings.TrimSuffix(hostname, ".")
+}
+
+// equalRecordSets compares two sorted lists of record values
+func equalRecordSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// describeAnswers lists each nameserver with a field of its answer
+func describeAnswers(answers []NameserverAnswer, field func(NameserverAnswer) string) string {
+	parts := make([]string, len(answers))
+	for i, answer := range answers {
+		parts[i] = fmt.Sprintf("%s=%s", answer.Server, field(answer))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,144 @@
+package checks
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// variantZone returns a copy of testZone with some answers replaced
+func variantZone(replace map[string][]string) map[string][]string {
+	zone := make(map[string][]string, len(testZone))
+	for key, records := range testZone {
+		zone[key] = records
+	}
+	for key, records := range replace {
+		zone[key] = records
+	}
+	return zone
+}
+
+// closedDNSAddress returns a loopback address nothing listens on
+func closedDNSAddress(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := pc.LocalAddr().String()
+	pc.Close()
+	return address
+}
+
+func runConsistency(t *testing.T, cfg DNSConfig, nameservers ...string) *CheckResult {
+	t.Helper()
+	cfg.Hostname = "example.test"
+	cfg.Server = nameservers[0]
+	cfg.Timeout = Duration(time.Second)
+	cfg.Consistency = &DNSConsistency{Nameservers: nameservers}
+	return runDNS(t, &cfg)
+}
+
+func TestDNSConsistencyAgreeing(t *testing.T) {
+	first := startDNSServer(t)
+	// The same records in another order still agree
+	second := startZoneServer(t, variantZone(map[string][]string{
+		"example.test. A": {"example.test. 60 IN A 192.0.2.2", "example.test. 300 IN A 192.0.2.1"},
+	}))
+
+	result := runConsistency(t, DNSConfig{RecordType: "A", ExpectedIP: "192.0.2.1"}, first, second)
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["consistent"] != true {
+		t.Errorf("consistent = %v", result.Metadata["consistent"])
+	}
+	if result.Metadata["soa_serial"] != uint32(2024010101) {
+		t.Errorf("soa_serial = %v", result.Metadata["soa_serial"])
+	}
+	if result.Metadata["zone"] != "example.test" {
+		t.Errorf("zone = %v", result.Metadata["zone"])
+	}
+	records := result.Metadata["records"].([]string)
+	if len(records) != 2 || records[0] != "192.0.2.1" || records[1] != "192.0.2.2" {
+		t.Errorf("records = %v", records)
+	}
+	answers := result.Metadata["nameservers"].([]NameserverAnswer)
+	if len(answers) != 2 || answers[0].Server != first || answers[1].Server != second {
+		t.Errorf("nameservers = %+v", answers)
+	}
+}
+
+func TestDNSConsistencyFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		second func(t *testing.T) string
+		want   string
+	}{
+		{
+			name: "different answers",
+			second: func(t *testing.T) string {
+				return startZoneServer(t, variantZone(map[string][]string{
+					"example.test. A": {"example.test. 300 IN A 192.0.2.1"},
+				}))
+			},
+			want: "Nameservers disagree on A records",
+		},
+		{
+			name: "different SOA serials",
+			second: func(t *testing.T) string {
+				return startZoneServer(t, variantZone(map[string][]string{
+					"example.test. SOA": {"example.test. 3600 IN SOA ns1.example.test. hostmaster.example.test. 2024010102 7200 3600 1209600 300"},
+				}))
+			},
+			want: "Nameservers disagree on the SOA serial of example.test",
+		},
+		{
+			name: "no SOA record",
+			second: func(t *testing.T) string {
+				return startZoneServer(t, variantZone(map[string][]string{"example.test. SOA": {}}))
+			},
+			want: "no SOA record for example.test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second := tt.second(t)
+			result := runConsistency(t, DNSConfig{RecordType: "A"}, startDNSServer(t), second)
+			if result.Status != StatusError {
+				t.Fatalf("status = %q, want error", result.Status)
+			}
+			if !strings.Contains(result.ErrorMessage, tt.want) {
+				t.Errorf("error = %q, want it to contain %q", result.ErrorMessage, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSConsistencyNameserverError(t *testing.T) {
+	dead := closedDNSAddress(t)
+
+	result := runConsistency(t, DNSConfig{RecordType: "A"}, startDNSServer(t), dead)
+	if !strings.HasPrefix(result.ErrorMessage, "Nameserver "+dead+" failed") {
+		t.Errorf("error = %q, want it to name %s", result.ErrorMessage, dead)
+	}
+	answers := result.Metadata["nameservers"].([]NameserverAnswer)
+	if answers[0].Error != "" || answers[1].Error == "" {
+		t.Errorf("answer errors = %q, %q, want only the second", answers[0].Error, answers[1].Error)
+	}
+}
+
+func TestDNSConsistencyValidate(t *testing.T) {
+	cfg := DNSConfig{Hostname: "example.test", RecordType: "A", Match: MatchExact, Consistency: &DNSConsistency{Nameservers: []string{"192.0.2.53"}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "at least two nameservers") {
+		t.Errorf("one nameserver: Validate() = %v", err)
+	}
+
+	cfg.Consistency.Nameservers = append(cfg.Consistency.Nameservers, "192.0.2.54")
+	cfg.DNSSEC = NewDNSSECConfig()
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("with dnssec: Validate() = %v", err)
+	}
+}
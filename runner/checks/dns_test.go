@@ -0,0 +1,336 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// testZone is served by startDNSServer, keyed by question name and type
+var testZone = map[string][]string{
+	"example.test. A": {
+		"example.test. 300 IN A 192.0.2.1",
+		"example.test. 60 IN A 192.0.2.2",
+	},
+	"example.test. AAAA":          {"example.test. 300 IN AAAA 2001:db8::1"},
+	"www.example.test. A":         {"www.example.test. 120 IN CNAME example.test.", "example.test. 300 IN A 192.0.2.1"},
+	"www.example.test. CNAME":     {"www.example.test. 120 IN CNAME example.test."},
+	"example.test. MX":            {"example.test. 3600 IN MX 10 mail.example.test.", "example.test. 3600 IN MX 20 backup.example.test."},
+	"example.test. TXT":           {`example.test. 300 IN TXT "v=spf1 " "include:_spf.example.test ~all"`},
+	"example.test. NS":            {"example.test. 86400 IN NS ns1.example.test.", "example.test. 86400 IN NS ns2.example.test."},
+	"_sip._tcp.example.test. SRV": {"_sip._tcp.example.test. 600 IN SRV 10 60 5060 sip.example.test."},
+	"example.test. CAA":           {`example.test. 3600 IN CAA 0 issue "letsencrypt.org"`},
+	"example.test. SOA":           {"example.test. 3600 IN SOA ns1.example.test. hostmaster.example.test. 2024010101 7200 3600 1209600 300"},
+	"big.example.test. TXT":       bigTXTRecords(),
+}
+
+// bigTXTRecords returns an answer too large for a UDP response without EDNS
+func bigTXTRecords() []string {
+	var records []string
+	for i := 0; i < 20; i++ {
+		records = append(records, `big.example.test. 300 IN TXT "`+strings.Repeat("x", 40)+`"`)
+	}
+	return records
+}
+
+// startDNSServer serves testZone over UDP and TCP on the same loopback port
+// and returns its address
+func startDNSServer(t *testing.T) string {
+	return startZoneServer(t, testZone)
+}
+
+// startZoneServer serves a zone keyed by question name and type. Questions
+// missing from the zone get NXDOMAIN; keys with no records get an empty answer.
+func startZoneServer(t *testing.T, zone map[string][]string) string {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg)
+		msg.SetReply(req)
+
+		q := req.Question[0]
+		records, ok := zone[q.Name+" "+dns.TypeToString[q.Qtype]]
+		if !ok {
+			msg.Rcode = dns.RcodeNameError
+		}
+		for _, record := range records {
+			rr, err := dns.NewRR(record)
+			if err != nil {
+				t.Errorf("bad test record %q: %v", record, err)
+				continue
+			}
+			msg.Answer = append(msg.Answer, rr)
+		}
+
+		if _, isUDP := w.RemoteAddr().(*net.UDPAddr); isUDP {
+			msg.Truncate(dns.MinMsgSize)
+		}
+		w.WriteMsg(msg)
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		pc.Close()
+		t.Skipf("TCP port matching the UDP one unavailable: %v", err)
+	}
+
+	for _, server := range []*dns.Server{
+		{PacketConn: pc, Handler: handler},
+		{Listener: ln, Handler: handler},
+	} {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go server.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { server.Shutdown() })
+	}
+	return pc.LocalAddr().String()
+}
+
+func runDNS(t *testing.T, cfg *DNSConfig) *CheckResult {
+	t.Helper()
+	if cfg.Match == "" {
+		cfg.Match = MatchExact
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = Duration(2 * time.Second)
+	}
+	result, err := RunDNSCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunDNSCheck: %v", err)
+	}
+	return result
+}
+
+func TestDNSRecordTypes(t *testing.T) {
+	server := startDNSServer(t)
+
+	tests := []struct {
+		domain     string
+		recordType string
+		want       []string
+	}{
+		{"example.test", "A", []string{"192.0.2.1", "192.0.2.2"}},
+		{"example.test", "aaaa", []string{"2001:db8::1"}},
+		{"www.example.test", "CNAME", []string{"example.test"}},
+		{"example.test", "MX", []string{"10 mail.example.test", "20 backup.example.test"}},
+		{"example.test", "TXT", []string{"v=spf1 include:_spf.example.test ~all"}},
+		{"example.test", "NS", []string{"ns1.example.test", "ns2.example.test"}},
+		{"_sip._tcp.example.test", "SRV", []string{"10 60 5060 sip.example.test"}},
+		{"example.test", "CAA", []string{`0 issue "letsencrypt.org"`}},
+		{"example.test", "SOA", []string{"ns1.example.test hostmaster.example.test 2024010101 7200 3600 1209600 300"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType, func(t *testing.T) {
+			result := runDNS(t, &DNSConfig{Hostname: tt.domain, RecordType: tt.recordType, Server: server})
+			if result.Status != StatusSuccess {
+				t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+			}
+
+			records := result.Metadata["records"].([]DNSRecord)
+			if len(records) != len(tt.want) {
+				t.Fatalf("records = %v, want %v", records, tt.want)
+			}
+			for i, record := range records {
+				if record.Value != tt.want[i] {
+					t.Errorf("record %d = %q, want %q", i, record.Value, tt.want[i])
+				}
+			}
+			if result.Metadata["record_type"] != strings.ToUpper(tt.recordType) {
+				t.Errorf("record_type = %v", result.Metadata["record_type"])
+			}
+		})
+	}
+}
+
+func TestDNSSkipsCNAMEChain(t *testing.T) {
+	server := startDNSServer(t)
+
+	result := runDNS(t, &DNSConfig{Hostname: "www.example.test", RecordType: "A", Server: server})
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	ips := result.Metadata["resolved_ips"].([]string)
+	if len(ips) != 1 || ips[0] != "192.0.2.1" {
+		t.Errorf("resolved_ips = %v, want only the A record", ips)
+	}
+}
+
+func TestDNSMatchModes(t *testing.T) {
+	server := startDNSServer(t)
+
+	tests := []struct {
+		name       string
+		recordType string
+		match      string
+		expected   []string
+		wantOK     bool
+	}{
+		{"exact", "MX", MatchExact, []string{"10 mail.example.test"}, true},
+		{"exact ignores case and trailing dot", "MX", MatchExact, []string{"10 MAIL.example.test."}, true},
+		{"exact needs the whole value", "MX", MatchExact, []string{"mail.example.test"}, false},
+		{"every value must match", "MX", MatchExact, []string{"10 mail.example.test", "30 other.example.test"}, false},
+		{"contains", "TXT", MatchContains, []string{"include:_spf.example.test"}, true},
+		{"contains ignores case", "TXT", MatchContains, []string{"V=SPF1"}, true},
+		{"contains missing", "TXT", MatchContains, []string{"-all"}, false},
+		{"regex", "MX", MatchRegex, []string{`^20 backup\.`}, true},
+		{"regex is case sensitive", "MX", MatchRegex, []string{`^20 BACKUP\.`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runDNS(t, &DNSConfig{
+				Hostname:       "example.test",
+				RecordType:     tt.recordType,
+				Match:          tt.match,
+				ExpectedValues: tt.expected,
+				Server:         server,
+			})
+			if ok := result.Status == StatusSuccess; ok != tt.wantOK {
+				t.Errorf("status = %q (%s), want success %v", result.Status, result.ErrorMessage, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDNSExpectedIPs(t *testing.T) {
+	server := startDNSServer(t)
+
+	tests := []struct {
+		name   string
+		cfg    DNSConfig
+		wantOK bool
+	}{
+		{"single expected ip", DNSConfig{ExpectedIP: "192.0.2.2"}, true},
+		{"any of the expected ips", DNSConfig{ExpectedIPs: []string{"198.51.100.1", "192.0.2.1"}}, true},
+		{"none of the expected ips", DNSConfig{ExpectedIPs: []string{"198.51.100.1"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Hostname = "example.test"
+			cfg.RecordType = "A"
+			cfg.Server = server
+			result := runDNS(t, &cfg)
+			if ok := result.Status == StatusSuccess; ok != tt.wantOK {
+				t.Errorf("status = %q (%s), want success %v", result.Status, result.ErrorMessage, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDNSTTL(t *testing.T) {
+	server := startDNSServer(t)
+
+	result := runDNS(t, &DNSConfig{Hostname: "example.test", RecordType: "A", Server: server})
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+
+	records := result.Metadata["records"].([]DNSRecord)
+	if records[0].TTL != 300 || records[1].TTL != 60 {
+		t.Errorf("record TTLs = %d, %d, want 300, 60", records[0].TTL, records[1].TTL)
+	}
+	if result.Metadata["min_ttl"] != uint32(60) {
+		t.Errorf("min_ttl = %v, want 60", result.Metadata["min_ttl"])
+	}
+}
+
+func TestDNSReportsRespondingServer(t *testing.T) {
+	server := startDNSServer(t)
+
+	result := runDNS(t, &DNSConfig{Hostname: "example.test", RecordType: "A", Server: server})
+	if result.Metadata["server"] != server {
+		t.Errorf("server = %v, want %s", result.Metadata["server"], server)
+	}
+
+	// The first server refuses the query, so the answer comes from the second
+	dead, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadAddr := dead.LocalAddr().String()
+	dead.Close()
+
+	query := newDNSQuery("example.test", dns.TypeA, false)
+	_, responder, err := dnsExchange(context.Background(), []string{deadAddr, server}, query, time.Second)
+	if err != nil {
+		t.Fatalf("dnsExchange: %v", err)
+	}
+	if responder != server {
+		t.Errorf("responding server = %s, want %s", responder, server)
+	}
+}
+
+func TestDNSTruncatedRetriesOverTCP(t *testing.T) {
+	server := startDNSServer(t)
+
+	result := runDNS(t, &DNSConfig{Hostname: "big.example.test", RecordType: "TXT", Server: server})
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["record_count"] != 20 {
+		t.Errorf("record_count = %v, want all 20 records from the TCP retry", result.Metadata["record_count"])
+	}
+}
+
+func TestDNSFailures(t *testing.T) {
+	server := startDNSServer(t)
+
+	result := runDNS(t, &DNSConfig{Hostname: "missing.example.test", RecordType: "A", Server: server})
+	if result.Status != StatusError || result.Metadata["rcode"] != "NXDOMAIN" {
+		t.Errorf("missing name: status %q rcode %v", result.Status, result.Metadata["rcode"])
+	}
+
+	result = runDNS(t, &DNSConfig{Hostname: "www.example.test", RecordType: "AAAA", Server: server})
+	if result.Status != StatusError {
+		t.Errorf("no records: status %q", result.Status)
+	}
+}
+
+func TestDNSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DNSConfig
+		want string
+	}{
+		{"missing domain", DNSConfig{RecordType: "A", Match: MatchExact}, "domain is required"},
+		{"unsupported type", DNSConfig{Hostname: "example.test", RecordType: "PTR", Match: MatchExact}, "unsupported record type"},
+		{"expected ips for mx", DNSConfig{Hostname: "example.test", RecordType: "MX", Match: MatchExact, ExpectedIP: "192.0.2.1"}, "only apply to A and AAAA"},
+		{"unknown match", DNSConfig{Hostname: "example.test", RecordType: "A", Match: "glob"}, "match must be one of"},
+		{"bad regex", DNSConfig{Hostname: "example.test", RecordType: "TXT", Match: MatchRegex, ExpectedValues: []string{"("}}, "invalid expected value pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	tests := map[string]string{
+		"8.8.8.8":             "8.8.8.8:53",
+		"8.8.8.8:5353":        "8.8.8.8:5353",
+		"2001:db8::53":        "[2001:db8::53]:53",
+		"[2001:db8::53]:5353": "[2001:db8::53]:5353",
+	}
+	for address, want := range tests {
+		if got := withDefaultPort(address, "53"); got != want {
+			t.Errorf("withDefaultPort(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// rootTrustAnchors are the DS records of the root zone's key signing keys,
+// as published by IANA
+var rootTrustAnchors = []string{
+	". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+// DNSSECConfig configures DNSSEC validation of a DNS check's answer
+type DNSSECConfig struct {
+	TrustAnchors  []string `json:"trustAnchors"`  // DS or DNSKEY records in zone file format
+	ExpiryWarning Duration `json:"expiryWarning"` // Fail when a signature expires within this duration
+}
+
+// NewDNSSECConfig returns a DNSSECConfig with defaults applied
+func NewDNSSECConfig() *DNSSECConfig {
+	return &DNSSECConfig{
+		ExpiryWarning: Duration(24 * time.Hour),
+	}
+}
+
+func (c *DNSSECConfig) validate() error {
+	_, err := c.anchors()
+	return err
+}
+
+// anchors parses the trust anchors, keyed by zone. The root zone KSKs are
+// used when none are configured.
+func (c *DNSSECConfig) anchors() (map[string][]dns.RR, error) {
+	records := c.TrustAnchors
+	if len(records) == 0 {
+		records = rootTrustAnchors
+	}
+
+	anchors := make(map[string][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust anchor %q: %w", record, err)
+		}
+		switch rr.(type) {
+		case *dns.DS, *dns.DNSKEY:
+		default:
+			return nil, fmt.Errorf("trust anchor %q must be a DS or DNSKEY record", record)
+		}
+		zone := strings.ToLower(rr.Header().Name)
+		anchors[zone] = append(anchors[zone], rr)
+	}
+	return anchors, nil
+}
+
+// DNSSignature describes an RRSIG verified while validating an answer
+type DNSSignature struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Signer     string    `json:"signer"`
+	KeyTag     uint16    `json:"key_tag"`
+	Algorithm  string    `json:"algorithm"`
+	Inception  time.Time `json:"inception"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// runDNSSECValidation validates the signatures on the answer up to a trust
+// anchor and fails if any signature is invalid or about to expire
+func runDNSSECValidation(ctx context.Context, cfg *DNSConfig, server string, response *dns.Msg, result *CheckResult) {
+	anchors, err := cfg.DNSSEC.anchors()
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return
+	}
+
+	v := &dnssecValidator{
+		ctx:     ctx,
+		server:  server,
+		timeout: cfg.Timeout.Std(),
+		anchors: anchors,
+		keys:    make(map[string][]*dns.DNSKEY),
+		pending: make(map[string]bool),
+		now:     time.Now(),
+	}
+
+	err = v.validateAnswer(response)
+	result.Metadata["dnssec_signatures"] = v.signatures
+	result.Metadata["dnssec_chain"] = v.chain
+	if err != nil {
+		result.Metadata["dnssec_valid"] = false
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("DNSSEC validation failed: %v", err)
+		return
+	}
+	result.Metadata["dnssec_valid"] = true
+
+	earliest := v.signatures[0]
+	for _, signature := range v.signatures[1:] {
+		if signature.Expiration.Before(earliest.Expiration) {
+			earliest = signature
+		}
+	}
+	remaining := earliest.Expiration.Sub(v.now)
+	result.Metadata["signature_expiration"] = earliest.Expiration
+	result.Metadata["signature_hours_until_expiry"] = int(remaining.Hours())
+
+	if warning := cfg.DNSSEC.ExpiryWarning.Std(); remaining <= warning {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("RRSIG for %s %s expires in %s (warning threshold: %s)",
+			earliest.Name, earliest.Type, remaining.Round(time.Minute), warning)
+		return
+	}
+
+	result.Status = StatusSuccess
+}
+
+// dnssecValidator authenticates RRsets by following DS records from the
+// signing zone up to a trust anchor
+type dnssecValidator struct {
+	ctx     context.Context
+	server  string
+	timeout time.Duration
+	anchors map[string][]dns.RR
+	keys    map[string][]*dns.DNSKEY // authenticated DNSKEY sets, keyed by zone
+	pending map[string]bool          // zones whose DNSKEY sets are being authenticated
+	now     time.Time
+
+	chain      []string // zones authenticated, from the trust anchor down to the answer's zone
+	signatures []DNSSignature
+}
+
+// validateAnswer verifies every RRset in the answer section, including any
+// CNAMEs that led to the queried records
+func (v *dnssecValidator) validateAnswer(response *dns.Msg) error {
+	rrsets, sigs := groupRRsets(response.Answer)
+	if len(rrsets) == 0 {
+		return fmt.Errorf("no records to validate")
+	}
+	for key, rrset := range rrsets {
+		if err := v.verify(rrset, sigs[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify checks an RRset against its signatures using the signer's authenticated keys
+func (v *dnssecValidator) verify(rrset []dns.RR, sigs []*dns.RRSIG) error {
+	header := rrset[0].Header()
+	name := fmt.Sprintf("%s %s", header.Name, dns.TypeToString[header.Rrtype])
+	if len(sigs) == 0 {
+		return fmt.Errorf("%s is not signed", name)
+	}
+
+	var lastErr error
+	for _, sig := range sigs {
+		if !dns.IsSubDomain(sig.SignerName, header.Name) {
+			lastErr = fmt.Errorf("%s is signed by %s, which is not a parent zone", name, sig.SignerName)
+			continue
+		}
+		keys, err := v.zoneKeys(sig.SignerName)
+		if err != nil {
+			return err
+		}
+		if err := v.verifyWith(sig, keys, rrset); err != nil {
+			lastErr = fmt.Errorf("%s: %w", name, err)
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// verifyWith checks one signature with whichever key it names
+func (v *dnssecValidator) verifyWith(sig *dns.RRSIG, keys []*dns.DNSKEY, rrset []dns.RR) error {
+	for _, key := range keys {
+		if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm {
+			continue
+		}
+		if err := sig.Verify(key, rrset); err != nil {
+			return fmt.Errorf("signature by key %d is invalid: %v", sig.KeyTag, err)
+		}
+		if !sig.ValidityPeriod(v.now) {
+			return fmt.Errorf("signature by key %d is only valid from %s to %s", sig.KeyTag,
+				rrsigTime(sig.Inception).Format(time.RFC3339), rrsigTime(sig.Expiration).Format(time.RFC3339))
+		}
+
+		v.signatures = append(v.signatures, DNSSignature{
+			Name:       sig.Header().Name,
+			Type:       dns.TypeToString[sig.TypeCovered],
+			Signer:     sig.SignerName,
+			KeyTag:     sig.KeyTag,
+			Algorithm:  dns.AlgorithmToString[sig.Algorithm],
+			Inception:  rrsigTime(sig.Inception),
+			Expiration: rrsigTime(sig.Expiration),
+		})
+		return nil
+	}
+	return fmt.Errorf("no DNSKEY matches key tag %d", sig.KeyTag)
+}
+
+// zoneKeys returns a zone's DNSKEY set once it has been authenticated, either
+// directly by a trust anchor or by the DS records in the parent zone
+func (v *dnssecValidator) zoneKeys(zone string) ([]*dns.DNSKEY, error) {
+	zone = strings.ToLower(dns.Fqdn(zone))
+	if keys, ok := v.keys[zone]; ok {
+		return keys, nil
+	}
+	// A chain that leads back to a zone being authenticated would never end
+	if v.pending[zone] {
+		return nil, fmt.Errorf("DNSKEY set for %s depends on itself", zone)
+	}
+	v.pending[zone] = true
+	defer delete(v.pending, zone)
+
+	response, err := v.query(zone, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+	rrset, sigs := filterRRset(response.Answer, zone, dns.TypeDNSKEY)
+	if len(rrset) == 0 {
+		return nil, fmt.Errorf("no DNSKEY records for %s", zone)
+	}
+	var keys []*dns.DNSKEY
+	for _, rr := range rrset {
+		keys = append(keys, rr.(*dns.DNSKEY))
+	}
+
+	// Find the key signing keys vouched for by the trust anchor or the parent's DS records
+	var trusted []dns.RR
+	if anchors, ok := v.anchors[zone]; ok {
+		trusted = anchors
+	} else if zone == "." {
+		return nil, fmt.Errorf("no trust anchor found for the chain")
+	} else {
+		trusted, err = v.delegation(zone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ksks := matchingKeys(keys, trusted)
+	if len(ksks) == 0 {
+		return nil, fmt.Errorf("no DNSKEY for %s matches its DS records or trust anchor", zone)
+	}
+
+	err = fmt.Errorf("DNSKEY set for %s is not signed by a trusted key", zone)
+	for _, sig := range sigs {
+		if !signedBy(sig, ksks) {
+			continue
+		}
+		if verifyErr := v.verifyWith(sig, ksks, rrset); verifyErr != nil {
+			err = fmt.Errorf("DNSKEY set for %s: %w", zone, verifyErr)
+			continue
+		}
+		v.keys[zone] = keys
+		v.chain = append(v.chain, zone)
+		return keys, nil
+	}
+	return nil, err
+}
+
+// delegation returns the zone's DS records once they have been verified in the parent zone
+func (v *dnssecValidator) delegation(zone string) ([]dns.RR, error) {
+	response, err := v.query(zone, dns.TypeDS)
+	if err != nil {
+		return nil, err
+	}
+	rrset, sigs := filterRRset(response.Answer, zone, dns.TypeDS)
+	if len(rrset) == 0 {
+		return nil, fmt.Errorf("no DS records for %s, the delegation is insecure", zone)
+	}
+
+	// DS records are signed by the parent zone; a zone cannot vouch for its own keys
+	var parentSigs []*dns.RRSIG
+	for _, sig := range sigs {
+		if !strings.EqualFold(dns.Fqdn(sig.SignerName), zone) && dns.IsSubDomain(sig.SignerName, zone) {
+			parentSigs = append(parentSigs, sig)
+		}
+	}
+	if len(sigs) > 0 && len(parentSigs) == 0 {
+		return nil, fmt.Errorf("DS records for %s are not signed by a parent zone", zone)
+	}
+	if err := v.verify(rrset, parentSigs); err != nil {
+		return nil, err
+	}
+	return rrset, nil
+}
+
+func (v *dnssecValidator) query(name string, qtype uint16) (*dns.Msg, error) {
+	response, err := dnsQuery(v.ctx, newDNSQuery(name, qtype, true), v.server, v.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s query failed: %w", name, dns.TypeToString[qtype], err)
+	}
+	if response.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("%s %s query failed: %s", name, dns.TypeToString[qtype], dns.RcodeToString[response.Rcode])
+	}
+	return response, nil
+}
+
+// signedBy reports whether the signature names one of the keys
+func signedBy(sig *dns.RRSIG, keys []*dns.DNSKEY) bool {
+	for _, key := range keys {
+		if key.KeyTag() == sig.KeyTag && key.Algorithm == sig.Algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingKeys returns the keys that match a trusted DS or DNSKEY record
+func matchingKeys(keys []*dns.DNSKEY, trusted []dns.RR) []*dns.DNSKEY {
+	var matched []*dns.DNSKEY
+	for _, key := range keys {
+		for _, rr := range trusted {
+			switch anchor := rr.(type) {
+			case *dns.DS:
+				ds := key.ToDS(anchor.DigestType)
+				if ds != nil && ds.KeyTag == anchor.KeyTag && strings.EqualFold(ds.Digest, anchor.Digest) {
+					matched = append(matched, key)
+				}
+			case *dns.DNSKEY:
+				if key.Algorithm == anchor.Algorithm && key.PublicKey == anchor.PublicKey {
+					matched = append(matched, key)
+				}
+			}
+		}
+	}
+	return matched
+}
+
+// groupRRsets splits records into RRsets by owner and type, with the
+// signatures covering each
+func groupRRsets(records []dns.RR) (map[string][]dns.RR, map[string][]*dns.RRSIG) {
+	rrsets := make(map[string][]dns.RR)
+	sigs := make(map[string][]*dns.RRSIG)
+	key := func(name string, rrtype uint16) string {
+		return strings.ToLower(name) + "|" + dns.TypeToString[rrtype]
+	}
+	for _, rr := range records {
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			k := key(sig.Header().Name, sig.TypeCovered)
+			sigs[k] = append(sigs[k], sig)
+			continue
+		}
+		k := key(rr.Header().Name, rr.Header().Rrtype)
+		rrsets[k] = append(rrsets[k], rr)
+	}
+	return rrsets, sigs
+}
+
+// filterRRset returns the records of one owner and type with their signatures
+func filterRRset(records []dns.RR, name string, rrtype uint16) ([]dns.RR, []*dns.RRSIG) {
+	rrsets, sigs := groupRRsets(records)
+	key := strings.ToLower(dns.Fqdn(name)) + "|" + dns.TypeToString[rrtype]
+	return rrsets[key], sigs[key]
+}
+
+// rrsigTime converts an RRSIG timestamp, which uses serial number arithmetic
+// to wrap around every 136 years, to a time
+func rrsigTime(t uint32) time.Time {
+	now := time.Now().Unix()
+	mod := int64(t) - now%(1<<32)
+	if mod > 1<<31 {
+		mod -= 1 << 32
+	} else if mod < -(1 << 31) {
+		mod += 1 << 32
+	}
+	return time.Unix(now+mod, 0).UTC()
+}
@@ -0,0 +1,218 @@
+package checks
+
+import (
+	"crypto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// testZoneKey signs the records of one zone in a DNSSEC test zone
+type testZoneKey struct {
+	key    *dns.DNSKEY
+	signer crypto.Signer
+}
+
+func newTestZoneKey(t *testing.T, zone string) *testZoneKey {
+	t.Helper()
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	private, err := key.Generate(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testZoneKey{key: key, signer: private.(crypto.Signer)}
+}
+
+// anchor returns the DS record of the key in zone file format
+func (k *testZoneKey) anchor() string {
+	return k.key.ToDS(dns.SHA256).String()
+}
+
+// sign returns the records followed by their RRSIG, valid between the given times
+func (k *testZoneKey) sign(t *testing.T, records []dns.RR, inception, expiration time.Time) []string {
+	t.Helper()
+	sig := &dns.RRSIG{
+		KeyTag:     k.key.KeyTag(),
+		SignerName: k.key.Hdr.Name,
+		Algorithm:  k.key.Algorithm,
+		Inception:  uint32(inception.Unix()),
+		Expiration: uint32(expiration.Unix()),
+	}
+	if err := sig.Sign(k.signer, records); err != nil {
+		t.Fatal(err)
+	}
+
+	var signed []string
+	for _, rr := range records {
+		signed = append(signed, rr.String())
+	}
+	return append(signed, sig.String())
+}
+
+func mustRR(t *testing.T, record string) dns.RR {
+	t.Helper()
+	rr, err := dns.NewRR(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr
+}
+
+// signedTestZone returns example.test. delegated from test., both signed
+// with valid signatures, the key of example.test. and the trust anchor for test.
+func signedTestZone(t *testing.T) (zone map[string][]string, child *testZoneKey, anchor string) {
+	t.Helper()
+	parent := newTestZoneKey(t, "test.")
+	child = newTestZoneKey(t, "example.test.")
+	inception, expiration := time.Now().Add(-time.Hour), time.Now().Add(30*24*time.Hour)
+
+	zone = map[string][]string{
+		"test. DNSKEY":         parent.sign(t, []dns.RR{parent.key}, inception, expiration),
+		"example.test. DS":     parent.sign(t, []dns.RR{child.key.ToDS(dns.SHA256)}, inception, expiration),
+		"example.test. DNSKEY": child.sign(t, []dns.RR{child.key}, inception, expiration),
+		"example.test. A":      child.sign(t, []dns.RR{mustRR(t, "example.test. 300 IN A 192.0.2.1")}, inception, expiration),
+	}
+	return zone, child, parent.anchor()
+}
+
+func runDNSSEC(t *testing.T, zone map[string][]string, anchor string) *CheckResult {
+	t.Helper()
+	dnssec := NewDNSSECConfig()
+	dnssec.TrustAnchors = []string{anchor}
+	return runDNS(t, &DNSConfig{
+		Hostname:   "example.test",
+		RecordType: "A",
+		Server:     startZoneServer(t, zone),
+		DNSSEC:     dnssec,
+	})
+}
+
+func TestDNSSECValidChain(t *testing.T) {
+	zone, _, anchor := signedTestZone(t)
+
+	result := runDNSSEC(t, zone, anchor)
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["dnssec_valid"] != true {
+		t.Errorf("dnssec_valid = %v", result.Metadata["dnssec_valid"])
+	}
+	chain := result.Metadata["dnssec_chain"].([]string)
+	if len(chain) != 2 || chain[0] != "test." || chain[1] != "example.test." {
+		t.Errorf("dnssec_chain = %v, want [test. example.test.]", chain)
+	}
+	// The answer, both DNSKEY sets and the DS records
+	if signatures := result.Metadata["dnssec_signatures"].([]DNSSignature); len(signatures) != 4 {
+		t.Errorf("dnssec_signatures = %d, want 4", len(signatures))
+	}
+	if _, ok := result.Metadata["signature_expiration"]; !ok {
+		t.Error("signature_expiration missing")
+	}
+}
+
+func TestDNSSECFailures(t *testing.T) {
+	now := time.Now()
+	answer := func(t *testing.T) []dns.RR {
+		return []dns.RR{mustRR(t, "example.test. 300 IN A 192.0.2.1")}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, zone map[string][]string, child *testZoneKey) (anchor string)
+		want   string
+	}{
+		{
+			name: "tampered answer",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				signed := child.sign(t, answer(t), now.Add(-time.Hour), now.Add(30*24*time.Hour))
+				signed[0] = "example.test. 300 IN A 192.0.2.9"
+				zone["example.test. A"] = signed
+				return ""
+			},
+			want: "is invalid",
+		},
+		{
+			name: "expired signature",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				zone["example.test. A"] = child.sign(t, answer(t), now.Add(-48*time.Hour), now.Add(-time.Hour))
+				return ""
+			},
+			want: "is only valid from",
+		},
+		{
+			name: "signature about to expire",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				zone["example.test. A"] = child.sign(t, answer(t), now.Add(-time.Hour), now.Add(2*time.Hour))
+				return ""
+			},
+			want: "expires in",
+		},
+		{
+			name: "unsigned answer",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				zone["example.test. A"] = []string{"example.test. 300 IN A 192.0.2.1"}
+				return ""
+			},
+			want: "is not signed",
+		},
+		{
+			name: "missing DS",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				zone["example.test. DS"] = []string{}
+				return ""
+			},
+			want: "no DS records for example.test.",
+		},
+		{
+			name: "DS signed by the zone itself",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				ds := []dns.RR{child.key.ToDS(dns.SHA256)}
+				zone["example.test. DS"] = child.sign(t, ds, now.Add(-time.Hour), now.Add(30*24*time.Hour))
+				return ""
+			},
+			want: "DS records for example.test. are not signed by a parent zone",
+		},
+		{
+			name: "untrusted anchor",
+			modify: func(t *testing.T, zone map[string][]string, child *testZoneKey) string {
+				return newTestZoneKey(t, "test.").anchor()
+			},
+			want: "no DNSKEY for test. matches",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, child, anchor := signedTestZone(t)
+			// A non-empty return replaces the trust anchor
+			if replaced := tt.modify(t, zone, child); replaced != "" {
+				anchor = replaced
+			}
+
+			result := runDNSSEC(t, zone, anchor)
+			if result.Status != StatusError {
+				t.Fatalf("status = %q, want error", result.Status)
+			}
+			if !strings.Contains(result.ErrorMessage, tt.want) {
+				t.Errorf("error = %q, want it to contain %q", result.ErrorMessage, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSSECValidatorRejectsCycles(t *testing.T) {
+	v := &dnssecValidator{
+		keys:    make(map[string][]*dns.DNSKEY),
+		pending: map[string]bool{"example.test.": true},
+	}
+	if _, err := v.zoneKeys("example.test"); err == nil || !strings.Contains(err.Error(), "depends on itself") {
+		t.Errorf("zoneKeys while pending = %v, want a cycle error", err)
+	}
+}
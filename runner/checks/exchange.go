@@ -0,0 +1,134 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exchange is an optional payload sent to a server and the response expected
+// back, shared by TCP, UDP and WebSocket checks. JSON field names match their specs.
+type Exchange struct {
+	Send        string   `json:"send"`        // Payload written after connecting
+	SendHex     string   `json:"sendHex"`     // Hex-encoded payload, as an alternative to send
+	Expect      string   `json:"expect"`      // Expected response, compared according to match
+	Match       string   `json:"match"`       // contains, regex or hex
+	ReadTimeout Duration `json:"readTimeout"` // How long to wait for the expected response
+}
+
+// MatchHex compares a response with hex-encoded expected bytes
+const MatchHex = "hex"
+
+// maxRecordedResponse is how much of a response is recorded in the result
+const maxRecordedResponse = 512
+
+// newExchange returns an Exchange with defaults applied
+func newExchange() Exchange {
+	return Exchange{
+		Match:       MatchContains,
+		ReadTimeout: Duration(5 * time.Second),
+	}
+}
+
+// loadEnv reads the exchange from environment variables with the given prefix,
+// e.g. TCP_SEND. Escapes such as \r\n and \x00 in <prefix>SEND are interpreted.
+func (e *Exchange) loadEnv(prefix string) error {
+	if value := os.Getenv(prefix + "SEND"); value != "" {
+		send, err := strconv.Unquote(`"` + strings.ReplaceAll(value, `"`, `\"`) + `"`)
+		if err != nil {
+			return fmt.Errorf("invalid %sSEND: %w", prefix, err)
+		}
+		e.Send = send
+	}
+	envString(prefix+"SEND_HEX", &e.SendHex)
+	envString(prefix+"EXPECT", &e.Expect)
+	envString(prefix+"MATCH", &e.Match)
+	return envDuration(prefix+"READ_TIMEOUT", &e.ReadTimeout)
+}
+
+// validate reports whether the payload and expectation are well formed
+func (e *Exchange) validate() error {
+	if e.Send != "" && e.SendHex != "" {
+		return fmt.Errorf("send and sendHex cannot both be set")
+	}
+	if _, err := hex.DecodeString(e.SendHex); err != nil {
+		return fmt.Errorf("invalid sendHex: %w", err)
+	}
+	switch e.Match {
+	case MatchContains:
+	case MatchRegex:
+		if _, err := regexp.Compile(e.Expect); err != nil {
+			return fmt.Errorf("invalid expect pattern %q: %w", e.Expect, err)
+		}
+	case MatchHex:
+		if _, err := hex.DecodeString(e.Expect); err != nil {
+			return fmt.Errorf("invalid hex expect: %w", err)
+		}
+	default:
+		return fmt.Errorf("match must be one of contains, regex or hex")
+	}
+	if e.active() && e.ReadTimeout <= 0 {
+		return fmt.Errorf("read timeout must be positive")
+	}
+	return nil
+}
+
+// active reports whether there is anything to send or expect
+func (e *Exchange) active() bool {
+	return e.Send != "" || e.SendHex != "" || e.Expect != ""
+}
+
+// payload returns the bytes to send
+func (e *Exchange) payload() []byte {
+	if e.SendHex != "" {
+		payload, _ := hex.DecodeString(e.SendHex)
+		return payload
+	}
+	return []byte(e.Send)
+}
+
+// matches reports whether a response satisfies expect
+func (e *Exchange) matches(response []byte) bool {
+	switch e.Match {
+	case MatchRegex:
+		return regexp.MustCompile(e.Expect).Match(response)
+	case MatchHex:
+		expected, _ := hex.DecodeString(e.Expect)
+		return bytes.Contains(response, expected)
+	default:
+		return bytes.Contains(response, []byte(e.Expect))
+	}
+}
+
+// readDeadline is when waiting for a response stops: after the read timeout or
+// when the check's context expires, whichever is first
+func (e *Exchange) readDeadline(ctxDeadline time.Time, ok bool) time.Time {
+	deadline := time.Now().Add(e.ReadTimeout.Std())
+	if ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// recordResponse adds the received bytes, truncated, to the result
+func recordResponse(result *CheckResult, response []byte) {
+	recorded := truncateBytes(response, maxRecordedResponse)
+	result.Metadata["received_bytes"] = len(response)
+	result.Metadata["received"] = strings.ToValidUTF8(string(recorded), "�")
+	result.Metadata["received_hex"] = hex.EncodeToString(recorded)
+	if len(recorded) < len(response) {
+		result.Metadata["received_truncated"] = true
+	}
+}
+
+func truncateBytes(b []byte, max int) []byte {
+	if len(b) > max {
+		return b[:max]
+	}
+	return b
+}
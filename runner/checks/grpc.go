@@ -0,0 +1,278 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionalphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCConfig configures a gRPC health check. JSON field names match the GrpcCheck spec.
+type GRPCConfig struct {
+	Host               string            `json:"host"`
+	Port               int               `json:"port"`
+	Timeout            Duration          `json:"timeout"`
+	Service            string            `json:"service"`            // Service to check, empty for the server as a whole
+	TLS                bool              `json:"tls"`                // Connect with TLS instead of plaintext
+	InsecureSkipVerify bool              `json:"insecureSkipVerify"` // Do not verify the server's certificate
+	CAFile             string            `json:"caFile"`             // PEM bundle to verify against, default: system roots
+	Metadata           map[string]string `json:"metadata"`           // Request metadata, e.g. authorization
+	ExpectedStatus     string            `json:"expectedStatus"`     // SERVING, NOT_SERVING or SERVICE_UNKNOWN
+	Reflection         bool              `json:"reflection"`         // List the server's services with reflection
+}
+
+// NewGRPCConfig returns a GRPCConfig with defaults applied
+func NewGRPCConfig() *GRPCConfig {
+	return &GRPCConfig{
+		Timeout:        Duration(10 * time.Second),
+		ExpectedStatus: healthpb.HealthCheckResponse_SERVING.String(),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
+//   - GRPC_HOST: Target host (required)
+//   - GRPC_PORT: Target port (required)
+//   - GRPC_TIMEOUT: Timeout in seconds (default: 10)
+//   - GRPC_SERVICE: Service name to check (default: the whole server)
+//   - GRPC_TLS: Set to true to connect with TLS
+//   - GRPC_INSECURE_SKIP_VERIFY: Set to true to skip verifying the server's certificate
+//   - GRPC_CA_FILE: PEM bundle to verify the server's certificate against
+//   - GRPC_METADATA: Comma-separated key:value pairs (e.g., "authorization:Bearer token")
+//   - GRPC_EXPECTED_STATUS: SERVING, NOT_SERVING or SERVICE_UNKNOWN (default: SERVING)
+//   - GRPC_REFLECTION: Set to true to list the server's services
+func (c *GRPCConfig) LoadEnv() error {
+	envString("GRPC_HOST", &c.Host)
+	if c.Host == "" {
+		return fmt.Errorf("GRPC_HOST environment variable is required")
+	}
+
+	if err := envInt("GRPC_PORT", &c.Port); err != nil {
+		return err
+	}
+	if c.Port == 0 {
+		return fmt.Errorf("GRPC_PORT environment variable is required")
+	}
+
+	if err := envDuration("GRPC_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	envString("GRPC_SERVICE", &c.Service)
+	envString("GRPC_CA_FILE", &c.CAFile)
+	envString("GRPC_EXPECTED_STATUS", &c.ExpectedStatus)
+	if err := envBool("GRPC_TLS", &c.TLS); err != nil {
+		return err
+	}
+	if err := envBool("GRPC_INSECURE_SKIP_VERIFY", &c.InsecureSkipVerify); err != nil {
+		return err
+	}
+	if err := envBool("GRPC_REFLECTION", &c.Reflection); err != nil {
+		return err
+	}
+
+	envPairs("GRPC_METADATA", &c.Metadata)
+	return nil
+}
+
+// grpcStatuses are the serving statuses a check can expect
+var grpcStatuses = map[string]bool{
+	healthpb.HealthCheckResponse_SERVING.String():         true,
+	healthpb.HealthCheckResponse_NOT_SERVING.String():     true,
+	healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String(): true,
+}
+
+// Validate reports whether the configuration is complete
+func (c *GRPCConfig) Validate() error {
+	if c.Host == "" || c.Port == 0 {
+		return fmt.Errorf("host and port are required")
+	}
+	if !grpcStatuses[c.ExpectedStatus] {
+		return fmt.Errorf("expected status must be one of SERVING, NOT_SERVING or SERVICE_UNKNOWN")
+	}
+	if !c.TLS && (c.InsecureSkipVerify || c.CAFile != "") {
+		return fmt.Errorf("insecureSkipVerify and caFile require tls")
+	}
+	return nil
+}
+
+// credentials returns the transport credentials to connect with
+func (c *GRPCConfig) credentials() (credentials.TransportCredentials, error) {
+	if !c.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		ServerName:         c.Host,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		data, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA file")
+		}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// grpcChecker runs gRPC health checks
+type grpcChecker struct{}
+
+func init() {
+	Register(grpcChecker{})
+}
+
+func (grpcChecker) Name() string {
+	return "grpc"
+}
+
+func (grpcChecker) NewConfig() Config {
+	return NewGRPCConfig()
+}
+
+func (grpcChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*GRPCConfig]("grpc", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunGRPCCheck(ctx, c)
+}
+
+// RunGRPCCheck calls grpc.health.v1.Health/Check and compares the serving
+// status with the expected one, optionally listing services with reflection
+func RunGRPCCheck(ctx context.Context, cfg *GRPCConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	creds, err := cfg.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	result.Metadata["host"] = cfg.Host
+	result.Metadata["port"] = cfg.Port
+	result.Metadata["service"] = cfg.Service
+	result.Metadata["tls"] = cfg.TLS
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), grpc.WithUserAgent("moogie-runner"))
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("gRPC client setup failed: %v", err)
+		return result, nil
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout.Std())
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(cfg.Metadata))
+
+	// The connection is made lazily, so the response time includes connecting
+	start := time.Now()
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: cfg.Service})
+	result.ResponseTimeMs = time.Since(start).Milliseconds()
+
+	servingStatus := ""
+	switch code := status.Code(err); {
+	case err == nil:
+		servingStatus = response.GetStatus().String()
+	case code == codes.NotFound:
+		// The health service reports unknown services as NOT_FOUND
+		servingStatus = healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String()
+	default:
+		result.Metadata["grpc_code"] = code.String()
+		result.Status = StatusError
+		if code == codes.Unimplemented {
+			result.ErrorMessage = "gRPC server does not implement grpc.health.v1.Health"
+		} else {
+			result.ErrorMessage = fmt.Sprintf("gRPC health check failed: %s", status.Convert(err).Message())
+		}
+		return result, nil
+	}
+	result.Metadata["serving_status"] = servingStatus
+
+	if cfg.Reflection {
+		services, err := listGRPCServices(ctx, conn)
+		if err != nil {
+			// Listing services is informational, so it does not fail the check
+			result.Metadata["reflection_error"] = err.Error()
+		} else {
+			result.Metadata["services"] = services
+		}
+	}
+
+	if servingStatus != cfg.ExpectedStatus {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("Expected serving status %s, got %s", cfg.ExpectedStatus, servingStatus)
+		return result, nil
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// listGRPCServices lists the services a server exposes with the v1 reflection
+// API, falling back to v1alpha for older servers
+func listGRPCServices(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+	services, err := listServices(ctx, conn, reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName)
+	if status.Code(err) == codes.Unimplemented {
+		services, err = listServices(ctx, conn, reflectionalphapb.ServerReflection_ServerReflectionInfo_FullMethodName)
+	}
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, fmt.Errorf("server reflection is not enabled")
+		}
+		return nil, fmt.Errorf("server reflection failed: %s", status.Convert(err).Message())
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// listServices asks the reflection method for the list of services. The
+// v1alpha API differs from v1 only in its service name, so its messages are
+// read as v1 messages.
+func listServices(ctx context.Context, conn *grpc.ClientConn, method string) ([]string, error) {
+	stream, err := conn.NewStream(ctx, &reflectionpb.ServerReflection_ServiceDesc.Streams[0], method)
+	if err != nil {
+		return nil, err
+	}
+	request := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	}
+	if err := stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+	response := &reflectionpb.ServerReflectionResponse{}
+	if err := stream.RecvMsg(response); err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+
+	if e := response.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.ErrorCode), e.ErrorMessage)
+	}
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	return services, nil
+}
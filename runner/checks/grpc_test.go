@@ -0,0 +1,189 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionalphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// startGRPCServer serves the services added by register on a loopback port
+// until the test ends and returns the port
+func startGRPCServer(t *testing.T, register func(*grpc.Server), opts ...grpc.ServerOption) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(opts...)
+	register(server)
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// registerHealth adds a health service where the server as a whole and
+// moogie.Orders are serving and moogie.Billing is not
+func registerHealth(server *grpc.Server) {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("moogie.Orders", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("moogie.Billing", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+}
+
+func runGRPC(t *testing.T, cfg *GRPCConfig) *CheckResult {
+	t.Helper()
+	cfg.Host = "127.0.0.1"
+	if cfg.Timeout == 0 {
+		cfg.Timeout = Duration(2 * time.Second)
+	}
+	if cfg.ExpectedStatus == "" {
+		cfg.ExpectedStatus = "SERVING"
+	}
+	result, err := RunGRPCCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunGRPCCheck: %v", err)
+	}
+	return result
+}
+
+func TestGRPCHealthStatus(t *testing.T) {
+	port := startGRPCServer(t, registerHealth)
+
+	tests := []struct {
+		name        string
+		service     string
+		expected    string
+		wantServing string
+		wantErr     string
+	}{
+		{name: "server serving", wantServing: "SERVING"},
+		{name: "service serving", service: "moogie.Orders", wantServing: "SERVING"},
+		{name: "service not serving", service: "moogie.Billing", wantServing: "NOT_SERVING", wantErr: "Expected serving status SERVING, got NOT_SERVING"},
+		{name: "expected not serving", service: "moogie.Billing", expected: "NOT_SERVING", wantServing: "NOT_SERVING"},
+		{name: "unknown service", service: "moogie.Missing", wantServing: "SERVICE_UNKNOWN", wantErr: "got SERVICE_UNKNOWN"},
+		{name: "expected unknown service", service: "moogie.Missing", expected: "SERVICE_UNKNOWN", wantServing: "SERVICE_UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runGRPC(t, &GRPCConfig{Port: port, Service: tt.service, ExpectedStatus: tt.expected})
+
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+			} else if result.Status != StatusError || !strings.Contains(result.ErrorMessage, tt.wantErr) {
+				t.Fatalf("status = %q (%s), want an error containing %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if result.Metadata["serving_status"] != tt.wantServing {
+				t.Errorf("serving_status = %v, want %s", result.Metadata["serving_status"], tt.wantServing)
+			}
+		})
+	}
+}
+
+func TestGRPCSendsMetadata(t *testing.T) {
+	received := make(chan metadata.MD, 1)
+	capture := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		received <- md
+		return handler(ctx, req)
+	}
+	port := startGRPCServer(t, registerHealth, grpc.UnaryInterceptor(capture))
+
+	result := runGRPC(t, &GRPCConfig{Port: port, Metadata: map[string]string{"authorization": "Bearer token"}})
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	md := <-received
+	if got := md.Get("authorization"); len(got) != 1 || got[0] != "Bearer token" {
+		t.Errorf("authorization = %v", got)
+	}
+	if got := md.Get("user-agent"); len(got) != 1 || !strings.HasPrefix(got[0], "moogie-runner") {
+		t.Errorf("user-agent = %v", got)
+	}
+}
+
+func TestGRPCReflection(t *testing.T) {
+	tests := []struct {
+		name         string
+		register     func(*grpc.Server)
+		wantServices []string
+		wantErr      string
+	}{
+		{
+			name:     "v1",
+			register: func(server *grpc.Server) { reflection.Register(server) },
+			wantServices: []string{
+				"grpc.health.v1.Health",
+				"grpc.reflection.v1.ServerReflection",
+				"grpc.reflection.v1alpha.ServerReflection",
+			},
+		},
+		{
+			name: "v1alpha only",
+			register: func(server *grpc.Server) {
+				reflectionalphapb.RegisterServerReflectionServer(server, reflection.NewServer(reflection.ServerOptions{Services: server}))
+			},
+			wantServices: []string{"grpc.health.v1.Health", "grpc.reflection.v1alpha.ServerReflection"},
+		},
+		{
+			name:     "not enabled",
+			register: func(*grpc.Server) {},
+			wantErr:  "server reflection is not enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := startGRPCServer(t, func(server *grpc.Server) {
+				registerHealth(server)
+				tt.register(server)
+			})
+
+			result := runGRPC(t, &GRPCConfig{Port: port, Reflection: true})
+			// Listing services is informational and never fails the check
+			if result.Status != StatusSuccess {
+				t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+			}
+			if tt.wantErr != "" {
+				if result.Metadata["reflection_error"] != tt.wantErr {
+					t.Errorf("reflection_error = %v, want %q", result.Metadata["reflection_error"], tt.wantErr)
+				}
+				return
+			}
+			if services := result.Metadata["services"]; !reflect.DeepEqual(services, tt.wantServices) {
+				t.Errorf("services = %v, want %v", services, tt.wantServices)
+			}
+		})
+	}
+}
+
+func TestGRPCFailures(t *testing.T) {
+	withoutHealth := startGRPCServer(t, func(server *grpc.Server) { reflection.Register(server) })
+	result := runGRPC(t, &GRPCConfig{Port: withoutHealth})
+	if result.Status != StatusError || result.ErrorMessage != "gRPC server does not implement grpc.health.v1.Health" {
+		t.Errorf("without health: status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["grpc_code"] != "Unimplemented" {
+		t.Errorf("without health: grpc_code = %v", result.Metadata["grpc_code"])
+	}
+
+	result = runGRPC(t, &GRPCConfig{Port: closedPort(t)})
+	if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, "gRPC health check failed") {
+		t.Errorf("connection refused: status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["grpc_code"] != "Unavailable" {
+		t.Errorf("connection refused: grpc_code = %v", result.Metadata["grpc_code"])
+	}
+}
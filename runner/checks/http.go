@@ -1,103 +1,251 @@
 package checks
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"strconv"
+	"net/http/httptrace"
 	"strings"
 	"time"
 )
 
-// RunHTTPCheck performs an HTTP/HTTPS synthetic check
-// Configuration via environment variables:
+// HTTPConfig configures an HTTP check. JSON field names match the HttpCheck spec.
+type HTTPConfig struct {
+	URL            string            `json:"url"`
+	Method         string            `json:"method"`
+	Timeout        Duration          `json:"timeout"`
+	ExpectedStatus int               `json:"expectedStatusCode"`
+	Headers        map[string]string `json:"headers"`
+	Body           string            `json:"body"`
+	Assertions     HTTPAssertions    `json:"assertions"`
+}
+
+// defaultBodyReadLimit caps how much of the response body is read when no
+// maxBodySize assertion is configured
+const defaultBodyReadLimit = 10 << 20
+
+// NewHTTPConfig returns an HTTPConfig with defaults applied
+func NewHTTPConfig() *HTTPConfig {
+	return &HTTPConfig{
+		Method:         "GET",
+		Timeout:        Duration(30 * time.Second),
+		ExpectedStatus: 200,
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
 //   - HTTP_URL: Target URL (required)
 //   - HTTP_METHOD: HTTP method (default: GET)
 //   - HTTP_TIMEOUT: Timeout in seconds (default: 30)
 //   - HTTP_EXPECTED_STATUS: Expected status code (default: 200)
 //   - HTTP_HEADERS: Comma-separated key:value pairs (e.g., "Authorization:Bearer token,Accept:application/json")
 //   - HTTP_BODY: Request body for POST/PUT requests
-func RunHTTPCheck() (*CheckResult, error) {
-	result := NewCheckResult()
-
-	// Parse configuration
-	url := os.Getenv("HTTP_URL")
-	if url == "" {
-		return nil, fmt.Errorf("HTTP_URL environment variable is required")
+//   - HTTP_BODY_CONTAINS / HTTP_BODY_NOT_CONTAINS: Text the response body must (not) contain
+//   - HTTP_BODY_MATCHES / HTTP_BODY_NOT_MATCHES: Regular expression the response body must (not) match
+//   - HTTP_JSON_EQUALS: Comma-separated path=value pairs, e.g. "$.status=ok,$.count=3" (value is JSON, or a plain string)
+//   - HTTP_JSON_EXISTS: Comma-separated JSONPaths that must exist in the response body
+//   - HTTP_MAX_BODY_SIZE: Maximum response body size in bytes
+func (c *HTTPConfig) LoadEnv() error {
+	envString("HTTP_URL", &c.URL)
+	if c.URL == "" {
+		return fmt.Errorf("HTTP_URL environment variable is required")
 	}
 
-	method := os.Getenv("HTTP_METHOD")
-	if method == "" {
-		method = "GET"
+	envString("HTTP_METHOD", &c.Method)
+	envString("HTTP_BODY", &c.Body)
+
+	if err := envDuration("HTTP_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	if err := envInt("HTTP_EXPECTED_STATUS", &c.ExpectedStatus); err != nil {
+		return err
 	}
 
-	timeout := 30
-	if timeoutStr := os.Getenv("HTTP_TIMEOUT"); timeoutStr != "" {
-		if t, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = t
-		}
+	envPairs("HTTP_HEADERS", &c.Headers)
+
+	return c.loadAssertionsEnv()
+}
+
+func (c *HTTPConfig) loadAssertionsEnv() error {
+	a := &c.Assertions
+
+	envAppend("HTTP_BODY_CONTAINS", &a.Contains)
+	envAppend("HTTP_BODY_NOT_CONTAINS", &a.NotContains)
+	envAppend("HTTP_BODY_MATCHES", &a.Matches)
+	envAppend("HTTP_BODY_NOT_MATCHES", &a.NotMatches)
+
+	if err := loadJSONAssertionsEnv("HTTP_", &a.JSON); err != nil {
+		return err
 	}
 
-	expectedStatus := 200
-	if statusStr := os.Getenv("HTTP_EXPECTED_STATUS"); statusStr != "" {
-		if s, err := strconv.Atoi(statusStr); err == nil {
-			expectedStatus = s
-		}
+	var maxBodySize int
+	if err := envInt("HTTP_MAX_BODY_SIZE", &maxBodySize); err != nil {
+		return err
 	}
+	if maxBodySize > 0 {
+		a.MaxBodySize = int64(maxBodySize)
+	}
+	return nil
+}
 
-	// Build HTTP request
-	var body io.Reader
-	if bodyStr := os.Getenv("HTTP_BODY"); bodyStr != "" {
-		body = strings.NewReader(bodyStr)
+// Validate reports whether the configuration is complete
+func (c *HTTPConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
 	}
+	return c.Assertions.validate()
+}
+
+// httpChecker runs HTTP checks
+type httpChecker struct{}
 
-	req, err := http.NewRequest(method, url, body)
+func init() {
+	Register(httpChecker{})
+}
+
+func (httpChecker) Name() string {
+	return "http"
+}
+
+func (httpChecker) NewConfig() Config {
+	return NewHTTPConfig()
+}
+
+func (httpChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*HTTPConfig]("http", cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
+	}
+	return RunHTTPCheck(ctx, c)
+}
+
+// RunHTTPCheck performs an HTTP/HTTPS synthetic check
+func RunHTTPCheck(ctx context.Context, cfg *HTTPConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Add headers
-	if headersStr := os.Getenv("HTTP_HEADERS"); headersStr != "" {
-		headers := strings.Split(headersStr, ",")
-		for _, header := range headers {
-			parts := strings.SplitN(header, ":", 2)
-			if len(parts) == 2 {
-				req.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
-			}
-		}
+	req, timings, err := newTracedRequest(ctx, cfg.Method, cfg.URL, cfg.Headers, cfg.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	// Execute request with timing
 	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+		Timeout: cfg.Timeout.Std(),
 	}
 
-	start := time.Now()
-	resp, err := client.Do(req)
-	elapsed := time.Since(start)
-
-	result.ResponseTimeMs = elapsed.Milliseconds()
+	resp, err := sendHTTPRequest(client, req, timings, cfg.Assertions.MaxBodySize)
+	result.ResponseTimeMs = resp.ResponseTime.Milliseconds()
+	result.Metadata["timings"] = resp.Timings
 
 	if err != nil {
-		result.Status = "error"
-		result.ErrorMessage = fmt.Sprintf("HTTP request failed: %v", err)
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
 		return result, nil
 	}
-	defer resp.Body.Close()
 
 	// Store response metadata
 	result.Metadata["status_code"] = resp.StatusCode
-	result.Metadata["url"] = url
-	result.Metadata["method"] = method
+	result.Metadata["url"] = cfg.URL
+	result.Metadata["method"] = cfg.Method
+	result.Metadata["body_size"] = len(resp.Body)
+	if resp.Truncated {
+		result.Metadata["body_truncated"] = true
+	}
 
-	// Check if status code matches expectation
-	if resp.StatusCode != expectedStatus {
-		result.Status = "error"
-		result.ErrorMessage = fmt.Sprintf("Expected status %d, got %d", expectedStatus, resp.StatusCode)
+	if err := resp.verify(cfg.ExpectedStatus, &cfg.Assertions); err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
 		return result, nil
 	}
 
-	result.Status = "success"
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// httpResponse is a response read by sendHTTPRequest
+type httpResponse struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	Truncated    bool               // Body was cut off at the read limit
+	ResponseTime time.Duration      // Time until the response headers arrived
+	Timings      map[string]float64 // Phase timings, see httpTimings.metadata
+}
+
+// newTracedRequest builds a request whose phase timings are recorded
+func newTracedRequest(ctx context.Context, method, url string, headers map[string]string, body string) (*http.Request, *httpTimings, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	timings := newHTTPTimings()
+	ctx = httptrace.WithClientTrace(ctx, timings.trace())
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, timings, nil
+}
+
+// sendHTTPRequest executes the request and reads up to maxBodySize bytes of
+// the body (defaultBodyReadLimit if zero). The response is always returned so
+// that timings are available; the error describes why the request failed.
+func sendHTTPRequest(client *http.Client, req *http.Request, timings *httpTimings, maxBodySize int64) (*httpResponse, error) {
+	start := time.Now()
+	resp, err := client.Do(req)
+	result := &httpResponse{ResponseTime: time.Since(start)}
+
+	if err != nil {
+		result.Timings = timings.metadata()
+		return result, fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the body, one byte past the limit so oversized bodies can be detected
+	limit := maxBodySize
+	if limit == 0 {
+		limit = defaultBodyReadLimit
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	timings.finish()
+
+	result.StatusCode = resp.StatusCode
+	result.Header = resp.Header
+	result.Timings = timings.metadata()
+
+	if err != nil {
+		return result, fmt.Errorf("Failed to read response body: %v", err)
+	}
+	if int64(len(body)) > limit {
+		body = body[:limit]
+		result.Truncated = true
+	}
+	result.Body = body
+
 	return result, nil
 }
+
+// verify checks the status code and body assertions, describing the first failure
+func (r *httpResponse) verify(expectedStatus int, assertions *HTTPAssertions) error {
+	if r.StatusCode != expectedStatus {
+		return fmt.Errorf("Expected status %d, got %d", expectedStatus, r.StatusCode)
+	}
+	if r.Truncated && assertions.MaxBodySize > 0 {
+		return fmt.Errorf("Assertion failed: body exceeds maximum size of %d bytes", assertions.MaxBodySize)
+	}
+	if err := assertions.check(r.Body); err != nil {
+		return fmt.Errorf("Assertion failed: %v", err)
+	}
+	return nil
+}
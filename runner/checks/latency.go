@@ -0,0 +1,113 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LatencyThresholds grade a passing check by its response time. JSON field
+// names match the common spec's latency block.
+type LatencyThresholds struct {
+	Warning  Duration `json:"warning"`  // Slower passing checks are degraded (0 = disabled)
+	Critical Duration `json:"critical"` // Slower passing checks fail (0 = disabled)
+}
+
+// LoadEnv reads the thresholds from environment variables:
+//   - CHECK_LATENCY_WARNING: Response time above which a check is degraded (e.g. "500ms")
+//   - CHECK_LATENCY_CRITICAL: Response time above which a check fails (e.g. "2s")
+func (t *LatencyThresholds) LoadEnv() error {
+	if err := envDuration("CHECK_LATENCY_WARNING", &t.Warning); err != nil {
+		return err
+	}
+	if err := envDuration("CHECK_LATENCY_CRITICAL", &t.Critical); err != nil {
+		return err
+	}
+	return t.Validate()
+}
+
+// Validate reports whether the thresholds are consistent
+func (t *LatencyThresholds) Validate() error {
+	if t.Warning < 0 || t.Critical < 0 {
+		return fmt.Errorf("latency thresholds must not be negative")
+	}
+	if t.Warning > 0 && t.Critical > 0 && t.Warning >= t.Critical {
+		return fmt.Errorf("latency critical threshold must be greater than the warning threshold")
+	}
+	return nil
+}
+
+// LatencyThresholdsFromJSON reads the latency block of a spec or moogie.io/v1
+// document. When no warning threshold is set, alerts.latencyThreshold is used
+// as one if alerts.onHighLatency is enabled.
+func LatencyThresholdsFromJSON(data []byte) (*LatencyThresholds, error) {
+	data, err := specJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latency thresholds: %w", err)
+	}
+
+	var spec struct {
+		Latency LatencyThresholds `json:"latency"`
+		Alerts  struct {
+			OnHighLatency    bool     `json:"onHighLatency"`
+			LatencyThreshold Duration `json:"latencyThreshold"`
+		} `json:"alerts"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid latency thresholds: %w", err)
+	}
+
+	t := &spec.Latency
+	if t.Warning == 0 && spec.Alerts.OnHighLatency {
+		t.Warning = spec.Alerts.LatencyThreshold
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// apply downgrades a passing result whose response time exceeds a threshold
+func (t *LatencyThresholds) apply(result *CheckResult) {
+	if t == nil || result.Status != StatusSuccess {
+		return
+	}
+
+	responseTime := time.Duration(result.ResponseTimeMs) * time.Millisecond
+	switch {
+	case t.Critical > 0 && responseTime > t.Critical.Std():
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("Response time %dms exceeded critical threshold of %s", result.ResponseTimeMs, t.Critical.Std())
+	case t.Warning > 0 && responseTime > t.Warning.Std():
+		result.Status = StatusDegraded
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]interface{})
+		}
+		result.Metadata["warning"] = fmt.Sprintf("Response time %dms exceeded warning threshold of %s", result.ResponseTimeMs, t.Warning.Std())
+	}
+}
+
+// latencyChecker grades the results of another checker by latency
+type latencyChecker struct {
+	Checker
+	thresholds *LatencyThresholds
+}
+
+// WithLatencyThresholds wraps a checker so that passing results slower than
+// the thresholds are reported as degraded or failed
+func WithLatencyThresholds(c Checker, t *LatencyThresholds) Checker {
+	if t == nil || (t.Warning == 0 && t.Critical == 0) {
+		return c
+	}
+	return latencyChecker{Checker: c, thresholds: t}
+}
+
+func (l latencyChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	result, err := l.Checker.Run(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	l.thresholds.apply(result)
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// timedChecker returns a result with a fixed status and response time
+type timedChecker struct {
+	status         string
+	responseTimeMs int64
+	err            error
+}
+
+func (c timedChecker) Name() string      { return "timed" }
+func (c timedChecker) NewConfig() Config { return NewTCPConfig() }
+func (c timedChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	result := NewCheckResult()
+	result.Status = c.status
+	result.ResponseTimeMs = c.responseTimeMs
+	if c.status == StatusError {
+		result.ErrorMessage = "connection refused"
+	}
+	return result, nil
+}
+
+func TestWithLatencyThresholds(t *testing.T) {
+	both := &LatencyThresholds{Warning: Duration(500 * time.Millisecond), Critical: Duration(2 * time.Second)}
+
+	tests := []struct {
+		name           string
+		thresholds     *LatencyThresholds
+		status         string
+		responseTimeMs int64
+		wantStatus     string
+		wantMessage    string
+		wantWarning    string
+	}{
+		{name: "fast", thresholds: both, status: StatusSuccess, responseTimeMs: 100, wantStatus: StatusSuccess},
+		{name: "at the warning threshold", thresholds: both, status: StatusSuccess, responseTimeMs: 500, wantStatus: StatusSuccess},
+		{
+			name: "past the warning threshold", thresholds: both, status: StatusSuccess, responseTimeMs: 501,
+			wantStatus: StatusDegraded, wantWarning: "Response time 501ms exceeded warning threshold of 500ms",
+		},
+		{
+			name: "at the critical threshold", thresholds: both, status: StatusSuccess, responseTimeMs: 2000,
+			wantStatus: StatusDegraded, wantWarning: "Response time 2000ms exceeded warning threshold of 500ms",
+		},
+		{
+			name: "past the critical threshold", thresholds: both, status: StatusSuccess, responseTimeMs: 2001,
+			wantStatus: StatusError, wantMessage: "Response time 2001ms exceeded critical threshold of 2s",
+		},
+		{
+			name: "critical only", thresholds: &LatencyThresholds{Critical: Duration(time.Second)}, status: StatusSuccess, responseTimeMs: 1500,
+			wantStatus: StatusError, wantMessage: "Response time 1500ms exceeded critical threshold of 1s",
+		},
+		{
+			name: "warning only", thresholds: &LatencyThresholds{Warning: Duration(time.Second)}, status: StatusSuccess, responseTimeMs: 60000,
+			wantStatus: StatusDegraded, wantWarning: "Response time 60000ms exceeded warning threshold of 1s",
+		},
+		{
+			name: "failures are kept", thresholds: both, status: StatusError, responseTimeMs: 5000,
+			wantStatus: StatusError, wantMessage: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := WithLatencyThresholds(timedChecker{status: tt.status, responseTimeMs: tt.responseTimeMs}, tt.thresholds)
+
+			result, err := checker.Run(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.ErrorMessage != tt.wantMessage {
+				t.Errorf("error = %q, want %q", result.ErrorMessage, tt.wantMessage)
+			}
+			if warning, _ := result.Metadata["warning"].(string); warning != tt.wantWarning {
+				t.Errorf("warning = %q, want %q", warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestWithLatencyThresholdsPassThrough(t *testing.T) {
+	inner := timedChecker{status: StatusSuccess, responseTimeMs: 100}
+	for _, thresholds := range []*LatencyThresholds{nil, {}} {
+		if checker := WithLatencyThresholds(inner, thresholds); checker != Checker(inner) {
+			t.Errorf("WithLatencyThresholds(%+v) wrapped the checker", thresholds)
+		}
+	}
+
+	checker := WithLatencyThresholds(timedChecker{err: errors.New("invalid config")}, &LatencyThresholds{Warning: Duration(time.Second)})
+	if _, err := checker.Run(context.Background(), nil); err == nil || err.Error() != "invalid config" {
+		t.Errorf("error = %v, want the checker's", err)
+	}
+	if checker.Name() != "timed" {
+		t.Errorf("Name() = %q, want the wrapped checker's", checker.Name())
+	}
+}
+
+func TestLatencyThresholdsFromJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		wantWarning  time.Duration
+		wantCritical time.Duration
+		wantErr      string
+	}{
+		{name: "latency block", json: `{"spec":{"latency":{"warning":"500ms","critical":"2s"}}}`, wantWarning: 500 * time.Millisecond, wantCritical: 2 * time.Second},
+		{name: "alert threshold", json: `{"spec":{"alerts":{"onHighLatency":true,"latencyThreshold":"1s"}}}`, wantWarning: time.Second},
+		{name: "alert disabled", json: `{"spec":{"alerts":{"onHighLatency":false,"latencyThreshold":"1s"}}}`},
+		{name: "latency block wins", json: `{"spec":{"latency":{"warning":"300ms"},"alerts":{"onHighLatency":true,"latencyThreshold":"1s"}}}`, wantWarning: 300 * time.Millisecond},
+		{name: "warning above critical", json: `{"spec":{"latency":{"warning":"2s","critical":"1s"}}}`, wantErr: "must be greater than the warning threshold"},
+		{name: "equal thresholds", json: `{"spec":{"latency":{"warning":"1s","critical":"1s"}}}`, wantErr: "must be greater than the warning threshold"},
+		{name: "negative", json: `{"spec":{"latency":{"warning":"-1s"}}}`, wantErr: "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds, err := LatencyThresholdsFromJSON([]byte(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LatencyThresholdsFromJSON: %v", err)
+			}
+			if thresholds.Warning.Std() != tt.wantWarning || thresholds.Critical.Std() != tt.wantCritical {
+				t.Errorf("thresholds = %s/%s, want %s/%s", thresholds.Warning.Std(), thresholds.Critical.Std(), tt.wantWarning, tt.wantCritical)
+			}
+		})
+	}
+}
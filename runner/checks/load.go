@@ -0,0 +1,64 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ConfigFromEnv builds a checker's config from environment variables
+func ConfigFromEnv(c Checker) (Config, error) {
+	cfg := c.NewConfig()
+	if err := cfg.LoadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ConfigFromFile builds a checker's config from a JSON file, see ConfigFromJSON
+func ConfigFromFile(c Checker, path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ConfigFromJSON(c, data)
+}
+
+// ConfigFromJSON builds a checker's config from JSON. The data may be either
+// a bare spec or a full moogie.io/v1 document as stored in a job's config,
+// in which case the spec is taken from its "spec" field. Fields the checker
+// does not use, such as schedule and alerts, are ignored.
+func ConfigFromJSON(c Checker, data []byte) (Config, error) {
+	data, err := specJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", c.Name(), err)
+	}
+
+	cfg := c.NewConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", c.Name(), err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// specJSON returns the "spec" field of a moogie.io/v1 document, or data
+// unchanged if it is a bare spec
+func specJSON(data []byte) ([]byte, error) {
+	var document struct {
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Spec) > 0 && !bytes.Equal(document.Spec, []byte("null")) {
+		return document.Spec, nil
+	}
+	return data, nil
+}
@@ -0,0 +1,233 @@
+package checks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lookup(t *testing.T, name string) Checker {
+	t.Helper()
+	c, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("%s checker not registered", name)
+	}
+	return c
+}
+
+func TestConfigFromJSONSpecAndDocument(t *testing.T) {
+	c := lookup(t, "http")
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"bare spec", `{"url":"https://example.com","method":"HEAD","timeout":"5s"}`},
+		{"document", `{
+			"apiVersion": "moogie.io/v1",
+			"kind": "HttpCheck",
+			"metadata": {"name": "example"},
+			"spec": {"url":"https://example.com","method":"HEAD","timeout":"5s","schedule":"*/5 * * * *"}
+		}`},
+		{"document with null spec falls back to the data", `{"spec":null,"url":"https://example.com","method":"HEAD","timeout":5}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ConfigFromJSON(c, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("ConfigFromJSON: %v", err)
+			}
+			httpCfg := cfg.(*HTTPConfig)
+			if httpCfg.URL != "https://example.com" || httpCfg.Method != "HEAD" {
+				t.Errorf("got url %q method %q", httpCfg.URL, httpCfg.Method)
+			}
+			if httpCfg.Timeout.Std() != 5*time.Second {
+				t.Errorf("timeout = %s, want 5s", httpCfg.Timeout.Std())
+			}
+		})
+	}
+}
+
+func TestConfigFromJSONKeepsDefaults(t *testing.T) {
+	cfg, err := ConfigFromJSON(lookup(t, "http"), []byte(`{"spec":{"url":"https://example.com"}}`))
+	if err != nil {
+		t.Fatalf("ConfigFromJSON: %v", err)
+	}
+
+	httpCfg := cfg.(*HTTPConfig)
+	defaults := NewHTTPConfig()
+	if httpCfg.Method != defaults.Method || httpCfg.Timeout != defaults.Timeout || httpCfg.ExpectedStatus != defaults.ExpectedStatus {
+		t.Errorf("unset fields lost their defaults: %+v", httpCfg)
+	}
+}
+
+func TestConfigFromJSONErrors(t *testing.T) {
+	c := lookup(t, "tcp")
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"malformed", `{"host":`, "invalid tcp config"},
+		{"wrong field type", `{"spec":{"host":"localhost","port":"80"}}`, "invalid tcp config"},
+		{"bad duration", `{"host":"localhost","port":80,"timeout":"soon"}`, "invalid tcp config"},
+		{"missing port", `{"spec":{"host":"localhost"}}`, "host and port are required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfigFromJSON(c, []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromFile(t *testing.T) {
+	c := lookup(t, "tcp")
+	path := filepath.Join(t.TempDir(), "check.json")
+	data := `{"apiVersion":"moogie.io/v1","kind":"TcpCheck","spec":{"host":"db.internal","port":5432,"timeout":3}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ConfigFromFile(c, path)
+	if err != nil {
+		t.Fatalf("ConfigFromFile: %v", err)
+	}
+	tcpCfg := cfg.(*TCPConfig)
+	if tcpCfg.Host != "db.internal" || tcpCfg.Port != 5432 || tcpCfg.Timeout.Std() != 3*time.Second {
+		t.Errorf("got %+v", tcpCfg)
+	}
+
+	if _, err := ConfigFromFile(c, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("ConfigFromFile with a missing file succeeded")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	c := lookup(t, "tcp")
+
+	t.Setenv("TCP_HOST", "cache.internal")
+	t.Setenv("TCP_PORT", "6379")
+	t.Setenv("TCP_TIMEOUT", "2s")
+	t.Setenv("TCP_SEND", `PING\r\n`)
+	t.Setenv("TCP_EXPECT", "+PONG")
+
+	cfg, err := ConfigFromEnv(c)
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	tcpCfg := cfg.(*TCPConfig)
+	if tcpCfg.Host != "cache.internal" || tcpCfg.Port != 6379 || tcpCfg.Timeout.Std() != 2*time.Second {
+		t.Errorf("got %+v", tcpCfg)
+	}
+	if tcpCfg.Send != "PING\r\n" || tcpCfg.Expect != "+PONG" {
+		t.Errorf("exchange = %q / %q", tcpCfg.Send, tcpCfg.Expect)
+	}
+}
+
+func TestConfigFromEnvDefaults(t *testing.T) {
+	t.Setenv("HTTP_URL", "https://example.com")
+	t.Setenv("HTTP_METHOD", "")
+	t.Setenv("HTTP_TIMEOUT", "")
+
+	cfg, err := ConfigFromEnv(lookup(t, "http"))
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	httpCfg := cfg.(*HTTPConfig)
+	if httpCfg.Method != "GET" || httpCfg.Timeout.Std() != 30*time.Second {
+		t.Errorf("empty variables overrode defaults: %+v", httpCfg)
+	}
+}
+
+func TestConfigFromEnvErrors(t *testing.T) {
+	c := lookup(t, "tcp")
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"missing host", map[string]string{"TCP_HOST": "", "TCP_PORT": "80"}, "TCP_HOST"},
+		{"missing port", map[string]string{"TCP_HOST": "localhost", "TCP_PORT": ""}, "TCP_PORT"},
+		{"invalid port", map[string]string{"TCP_HOST": "localhost", "TCP_PORT": "http"}, "TCP_PORT"},
+		{"invalid timeout", map[string]string{"TCP_HOST": "localhost", "TCP_PORT": "80", "TCP_TIMEOUT": "soon"}, "TCP_TIMEOUT"},
+		{"invalid exchange", map[string]string{"TCP_HOST": "localhost", "TCP_PORT": "80", "TCP_EXPECT": "(", "TCP_MATCH": "regex"}, "invalid expect pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := ConfigFromEnv(c)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"http without url", NewHTTPConfig(), true},
+		{"http with url", &HTTPConfig{URL: "https://example.com"}, false},
+		{"tcp without port", &TCPConfig{Host: "localhost"}, true},
+		{"tcp complete", &TCPConfig{Host: "localhost", Port: 80, Exchange: newExchange()}, false},
+		{"tcp with send and sendHex", &TCPConfig{Host: "localhost", Port: 80, Exchange: Exchange{Send: "a", SendHex: "61", Match: "contains"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{`"30s"`, 30 * time.Second, false},
+		{`"1m30s"`, 90 * time.Second, false},
+		{`"15"`, 15 * time.Second, false},
+		{`10`, 10 * time.Second, false},
+		{`0.5`, 500 * time.Millisecond, false},
+		{`"soon"`, 0, true},
+		{`true`, 0, true},
+	}
+
+	for _, tt := range tests {
+		var d Duration
+		err := json.Unmarshal([]byte(tt.data), &d)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			continue
+		}
+		if err == nil && d.Std() != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.data, d.Std(), tt.want)
+		}
+	}
+
+	out, err := json.Marshal(Duration(90 * time.Second))
+	if err != nil || string(out) != `"1m30s"` {
+		t.Errorf("Marshal = %s, %v", out, err)
+	}
+}
@@ -0,0 +1,420 @@
+package checks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MailConfig configures a mail round-trip check. JSON field names match the MailCheck spec.
+type MailConfig struct {
+	SMTP         MailServer    `json:"smtp"`
+	From         string        `json:"from"`
+	To           string        `json:"to"`
+	Mailbox      MailboxConfig `json:"mailbox"` // Mailbox of the recipient, polled until the message arrives
+	Timeout      Duration      `json:"timeout"` // Covers sending and delivery
+	PollInterval Duration      `json:"pollInterval"`
+	Keep         bool          `json:"keep"` // Leave the message in the mailbox instead of deleting it
+}
+
+// MailServer is a server the check logs in to
+type MailServer struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port"`     // Default: depends on the protocol and security
+	Security           string `json:"security"` // starttls, tls or none
+	Username           string `json:"username"`
+	Password           string `json:"password"`
+	PasswordEnv        string `json:"passwordEnv"` // Runner environment variable holding the password
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+}
+
+// MailboxConfig is the IMAP or POP3 mailbox the message is delivered to
+type MailboxConfig struct {
+	MailServer
+	Protocol string `json:"protocol"` // imap or pop3
+	Folder   string `json:"folder"`   // IMAP folder (default: INBOX)
+}
+
+// Mail connection security modes
+const (
+	SecuritySTARTTLS = "starttls"
+	SecurityTLS      = "tls"
+	SecurityNone     = "none"
+)
+
+// mailPorts are the default ports of each protocol, by security mode
+var mailPorts = map[string]map[string]int{
+	"smtp": {SecuritySTARTTLS: 587, SecurityTLS: 465, SecurityNone: 25},
+	"imap": {SecuritySTARTTLS: 143, SecurityTLS: 993, SecurityNone: 143},
+	"pop3": {SecuritySTARTTLS: 110, SecurityTLS: 995, SecurityNone: 110},
+}
+
+// NewMailConfig returns a MailConfig with defaults applied
+func NewMailConfig() *MailConfig {
+	return &MailConfig{
+		SMTP: MailServer{Security: SecuritySTARTTLS},
+		Mailbox: MailboxConfig{
+			MailServer: MailServer{Security: SecurityTLS},
+			Protocol:   "imap",
+			Folder:     "INBOX",
+		},
+		Timeout:      Duration(2 * time.Minute),
+		PollInterval: Duration(5 * time.Second),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
+//   - MAIL_SMTP_HOST: SMTP server to send through (required)
+//   - MAIL_SMTP_PORT: SMTP port (default: 587, 465 with tls or 25 with none)
+//   - MAIL_SMTP_SECURITY: starttls, tls or none (default: starttls)
+//   - MAIL_SMTP_USERNAME / MAIL_SMTP_PASSWORD: Credentials for SMTP AUTH (default: no AUTH)
+//   - MAIL_SMTP_INSECURE_SKIP_VERIFY: Set to true to skip verifying the SMTP server's certificate
+//   - MAIL_FROM: Sender address (required)
+//   - MAIL_TO: Recipient address (required)
+//   - MAIL_MAILBOX_PROTOCOL: imap or pop3 (default: imap)
+//   - MAIL_MAILBOX_HOST: Server holding the recipient's mailbox (required)
+//   - MAIL_MAILBOX_PORT: Mailbox port (default: 993 or 995, 143 or 110 without tls)
+//   - MAIL_MAILBOX_SECURITY: tls, starttls or none (default: tls)
+//   - MAIL_MAILBOX_USERNAME / MAIL_MAILBOX_PASSWORD: Mailbox credentials (required)
+//   - MAIL_MAILBOX_INSECURE_SKIP_VERIFY: Set to true to skip verifying the mailbox server's certificate
+//   - MAIL_MAILBOX_FOLDER: IMAP folder the message is delivered to (default: INBOX)
+//   - MAIL_TIMEOUT: How long sending and delivery may take in total (default: 2m)
+//   - MAIL_POLL_INTERVAL: How often the mailbox is checked (default: 5s)
+//   - MAIL_KEEP: Set to true to leave the message in the mailbox
+func (c *MailConfig) LoadEnv() error {
+	envString("MAIL_SMTP_HOST", &c.SMTP.Host)
+	if c.SMTP.Host == "" {
+		return fmt.Errorf("MAIL_SMTP_HOST environment variable is required")
+	}
+	if err := c.SMTP.loadEnv("MAIL_SMTP_"); err != nil {
+		return err
+	}
+
+	envString("MAIL_FROM", &c.From)
+	envString("MAIL_TO", &c.To)
+	if c.From == "" || c.To == "" {
+		return fmt.Errorf("MAIL_FROM and MAIL_TO environment variables are required")
+	}
+
+	envString("MAIL_MAILBOX_HOST", &c.Mailbox.Host)
+	if c.Mailbox.Host == "" {
+		return fmt.Errorf("MAIL_MAILBOX_HOST environment variable is required")
+	}
+	if err := c.Mailbox.loadEnv("MAIL_MAILBOX_"); err != nil {
+		return err
+	}
+	envString("MAIL_MAILBOX_PROTOCOL", &c.Mailbox.Protocol)
+	envString("MAIL_MAILBOX_FOLDER", &c.Mailbox.Folder)
+
+	if err := envDuration("MAIL_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	if err := envDuration("MAIL_POLL_INTERVAL", &c.PollInterval); err != nil {
+		return err
+	}
+	return envBool("MAIL_KEEP", &c.Keep)
+}
+
+// loadEnv reads the server settings other than the host from environment
+// variables with the given prefix, e.g. MAIL_SMTP_PORT
+func (s *MailServer) loadEnv(prefix string) error {
+	if err := envInt(prefix+"PORT", &s.Port); err != nil {
+		return err
+	}
+	envString(prefix+"SECURITY", &s.Security)
+	envString(prefix+"USERNAME", &s.Username)
+	envString(prefix+"PASSWORD", &s.Password)
+	return envBool(prefix+"INSECURE_SKIP_VERIFY", &s.InsecureSkipVerify)
+}
+
+// Validate reports whether the configuration is complete
+func (c *MailConfig) Validate() error {
+	if err := c.SMTP.validate("smtp"); err != nil {
+		return err
+	}
+	if c.From == "" || c.To == "" {
+		return fmt.Errorf("from and to are required")
+	}
+	if strings.ContainsAny(c.From+c.To, "\r\n") {
+		return fmt.Errorf("from and to must be single addresses")
+	}
+	if c.Mailbox.Protocol != "imap" && c.Mailbox.Protocol != "pop3" {
+		return fmt.Errorf("mailbox protocol must be imap or pop3")
+	}
+	if err := c.Mailbox.validate("mailbox"); err != nil {
+		return err
+	}
+	if c.Mailbox.Username == "" {
+		return fmt.Errorf("mailbox username is required")
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive")
+	}
+	return nil
+}
+
+func (s *MailServer) validate(name string) error {
+	if s.Host == "" {
+		return fmt.Errorf("%s host is required", name)
+	}
+	switch s.Security {
+	case SecuritySTARTTLS, SecurityTLS, SecurityNone:
+	default:
+		return fmt.Errorf("%s security must be one of starttls, tls or none", name)
+	}
+	if s.Password != "" && s.PasswordEnv != "" {
+		return fmt.Errorf("%s password and passwordEnv cannot both be set", name)
+	}
+	return nil
+}
+
+// port returns the configured port, or the protocol's standard port for the security mode
+func (s *MailServer) port(protocol string) int {
+	if s.Port != 0 {
+		return s.Port
+	}
+	return mailPorts[protocol][s.Security]
+}
+
+// password returns the configured password, reading it from the runner's environment if passwordEnv is set
+func (s *MailServer) password() string {
+	if s.PasswordEnv != "" {
+		return os.Getenv(s.PasswordEnv)
+	}
+	return s.Password
+}
+
+func (s *MailServer) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: s.Host, InsecureSkipVerify: s.InsecureSkipVerify}
+}
+
+// dial connects to the server, completing the TLS handshake in tls mode. The
+// connection's deadline is the context's.
+func (s *MailServer) dial(ctx context.Context, protocol string) (net.Conn, error) {
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.port(protocol)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if s.Security != SecurityTLS {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, s.tlsConfig())
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// mailChecker runs mail round-trip checks
+type mailChecker struct{}
+
+func init() {
+	Register(mailChecker{})
+}
+
+func (mailChecker) Name() string {
+	return "mail"
+}
+
+func (mailChecker) NewConfig() Config {
+	return NewMailConfig()
+}
+
+func (mailChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*MailConfig]("mail", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunMailCheck(ctx, c)
+}
+
+// RunMailCheck sends a uniquely tagged message over SMTP and polls the
+// recipient's mailbox until it arrives. The response time is the delivery
+// latency, from the SMTP server accepting the message until it is found.
+func RunMailCheck(ctx context.Context, cfg *MailConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	result.Metadata["smtp_host"] = cfg.SMTP.Host
+	result.Metadata["smtp_port"] = cfg.SMTP.port("smtp")
+	result.Metadata["mailbox_protocol"] = cfg.Mailbox.Protocol
+	result.Metadata["mailbox_host"] = cfg.Mailbox.Host
+	result.Metadata["mailbox_port"] = cfg.Mailbox.port(cfg.Mailbox.Protocol)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout.Std())
+	defer cancel()
+
+	token, err := mailToken()
+	if err != nil {
+		return nil, err
+	}
+	result.Metadata["message_id"] = "<" + token + "@moogie>"
+
+	// Log in first so that a mailbox the check cannot read does not fill up with messages
+	mailbox, err := openMailbox(ctx, &cfg.Mailbox)
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("Mailbox login failed: %v", err)
+		return result, nil
+	}
+	defer mailbox.Close()
+
+	start := time.Now()
+	if err := sendMail(ctx, cfg, token); err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("SMTP delivery failed: %v", err)
+		return result, nil
+	}
+	sent := time.Now()
+	result.Metadata["smtp_time_ms"] = sent.Sub(start).Milliseconds()
+
+	if err := waitForMail(ctx, cfg, mailbox, token, result); err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	result.ResponseTimeMs = time.Since(sent).Milliseconds()
+	result.Metadata["delivery_time_ms"] = result.ResponseTimeMs
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// mailToken returns a random token identifying the message
+func mailToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate message token: %w", err)
+	}
+	return "moogie-" + hex.EncodeToString(b), nil
+}
+
+// sendMail submits the tagged message, upgrading with STARTTLS and
+// authenticating when configured
+func sendMail(ctx context.Context, cfg *MailConfig, token string) error {
+	server := &cfg.SMTP
+	conn, err := server.dial(ctx, "smtp")
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, server.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if hostname, err := os.Hostname(); err == nil {
+		if err := client.Hello(hostname); err != nil {
+			return err
+		}
+	}
+	if server.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(server.tlsConfig()); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if server.Username != "" {
+		auth := smtp.PlainAuth("", server.Username, server.password(), server.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(cfg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(mailMessage(cfg, token)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// mailMessage builds the message. The token is in the subject, which every
+// IMAP server can search, and in its own header.
+func mailMessage(cfg *MailConfig, token string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", cfg.To)
+	fmt.Fprintf(&b, "Subject: Moogie mail check %s\r\n", token)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@moogie>\r\n", token)
+	fmt.Fprintf(&b, "X-Moogie-Check: %s\r\n", token)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString("This message was sent by a Moogie mail check and can be deleted.\r\n")
+	return []byte(b.String())
+}
+
+// waitForMail polls the mailbox until the message arrives or the check times out
+func waitForMail(ctx context.Context, cfg *MailConfig, mailbox mailbox, token string, result *CheckResult) error {
+	polls := 0
+	ticker := time.NewTicker(cfg.PollInterval.Std())
+	defer ticker.Stop()
+	for {
+		polls++
+		result.Metadata["polls"] = polls
+		found, err := mailbox.find(token, !cfg.Keep)
+		if found {
+			// The message arrived, so a failed deletion does not fail the check
+			if err != nil {
+				result.Metadata["delete_error"] = err.Error()
+			}
+			return nil
+		}
+		if ctx.Err() != nil || deadlinePassed(ctx) {
+			return fmt.Errorf("Message not delivered within %s", cfg.Timeout.Std())
+		}
+		if err != nil {
+			return fmt.Errorf("Mailbox check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Message not delivered within %s", cfg.Timeout.Std())
+		case <-ticker.C:
+		}
+	}
+}
+
+// deadlinePassed reports whether the context's deadline has passed.
+// Connections time out at the deadline, which can be just before the context
+// reports it.
+func deadlinePassed(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
@@ -0,0 +1,411 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMailStore is a mailbox shared by the fake SMTP, IMAP and POP3 servers
+type fakeMailStore struct {
+	mu       sync.Mutex
+	nextUID  int
+	messages []fakeMessage
+
+	delay time.Duration // How long after SMTP accepts a message it is delivered
+	drop  bool          // Accept messages but never deliver them
+}
+
+type fakeMessage struct {
+	uid  int
+	data string
+}
+
+func (s *fakeMailStore) deliver(data string) {
+	if s.drop {
+		return
+	}
+	time.AfterFunc(s.delay, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.nextUID++
+		s.messages = append(s.messages, fakeMessage{uid: s.nextUID, data: data})
+	})
+}
+
+func (s *fakeMailStore) snapshot() []fakeMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeMessage(nil), s.messages...)
+}
+
+func (s *fakeMailStore) remove(uids map[int]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []fakeMessage
+	for _, m := range s.messages {
+		if !uids[m.uid] {
+			kept = append(kept, m)
+		}
+	}
+	s.messages = kept
+}
+
+// serveFake accepts connections until the test ends, handling each with serve,
+// and returns the port
+func serveFake(t *testing.T, serve func(conn net.Conn, r *bufio.Reader)) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn, bufio.NewReader(conn))
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func readLine(r *bufio.Reader) (string, bool) {
+	line, err := r.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err == nil
+}
+
+// fakeSMTP accepts every message and hands it to the store
+func fakeSMTP(store *fakeMailStore) func(net.Conn, *bufio.Reader) {
+	return func(conn net.Conn, r *bufio.Reader) {
+		fmt.Fprint(conn, "220 fake.test ESMTP\r\n")
+		for {
+			line, ok := readLine(r)
+			if !ok {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line + " x")[0])
+			switch verb {
+			case "EHLO", "HELO":
+				fmt.Fprint(conn, "250-fake.test\r\n250 8BITMIME\r\n")
+			case "MAIL", "RCPT", "RSET", "NOOP":
+				fmt.Fprint(conn, "250 OK\r\n")
+			case "DATA":
+				fmt.Fprint(conn, "354 Go ahead\r\n")
+				var data strings.Builder
+				for {
+					line, ok := readLine(r)
+					if !ok {
+						return
+					}
+					if line == "." {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+					data.WriteString("\r\n")
+				}
+				store.deliver(data.String())
+				fmt.Fprint(conn, "250 Queued\r\n")
+			case "QUIT":
+				fmt.Fprint(conn, "221 Bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "502 Command not implemented\r\n")
+			}
+		}
+	}
+}
+
+// fakeIMAP serves the store as INBOX, with message UIDs as IMAP UIDs
+func fakeIMAP(store *fakeMailStore, username, password string) func(net.Conn, *bufio.Reader) {
+	return func(conn net.Conn, r *bufio.Reader) {
+		fmt.Fprint(conn, "* OK fake IMAP ready\r\n")
+		deleted := make(map[int]bool)
+		for {
+			line, ok := readLine(r)
+			if !ok {
+				return
+			}
+			tag, rest, _ := strings.Cut(line, " ")
+			verb, args, _ := strings.Cut(rest, " ")
+			switch strings.ToUpper(verb) {
+			case "LOGIN":
+				if args != imapQuote(username)+" "+imapQuote(password) {
+					fmt.Fprintf(conn, "%s NO [AUTHENTICATIONFAILED] Invalid credentials\r\n", tag)
+					continue
+				}
+				fmt.Fprintf(conn, "%s OK LOGIN completed\r\n", tag)
+			case "SELECT":
+				if args != `"INBOX"` {
+					fmt.Fprintf(conn, "%s NO Mailbox does not exist\r\n", tag)
+					continue
+				}
+				fmt.Fprintf(conn, "* %d EXISTS\r\n%s OK [READ-WRITE] SELECT completed\r\n", len(store.snapshot()), tag)
+			case "UID":
+				subcommand, args, _ := strings.Cut(args, " ")
+				switch strings.ToUpper(subcommand) {
+				case "SEARCH":
+					token := strings.Trim(strings.TrimPrefix(args, "SUBJECT "), `"`)
+					var uids []string
+					for _, m := range store.snapshot() {
+						if strings.Contains(m.data, "Subject: Moogie mail check "+token) {
+							uids = append(uids, strconv.Itoa(m.uid))
+						}
+					}
+					fmt.Fprintf(conn, "* SEARCH %s\r\n%s OK SEARCH completed\r\n", strings.Join(uids, " "), tag)
+				case "STORE":
+					set, _, _ := strings.Cut(args, " ")
+					for _, uid := range strings.Split(set, ",") {
+						n, _ := strconv.Atoi(uid)
+						deleted[n] = true
+					}
+					fmt.Fprintf(conn, "%s OK STORE completed\r\n", tag)
+				default:
+					fmt.Fprintf(conn, "%s BAD Unknown UID command\r\n", tag)
+				}
+			case "EXPUNGE":
+				store.remove(deleted)
+				fmt.Fprintf(conn, "%s OK EXPUNGE completed\r\n", tag)
+			case "LOGOUT":
+				fmt.Fprintf(conn, "* BYE\r\n%s OK LOGOUT completed\r\n", tag)
+				return
+			default:
+				fmt.Fprintf(conn, "%s BAD Unknown command\r\n", tag)
+			}
+		}
+	}
+}
+
+// fakePOP3 serves the messages in the store when the session starts, applying
+// deletions on QUIT
+func fakePOP3(store *fakeMailStore, username, password string) func(net.Conn, *bufio.Reader) {
+	return func(conn net.Conn, r *bufio.Reader) {
+		fmt.Fprint(conn, "+OK fake POP3 ready\r\n")
+		messages := store.snapshot()
+		deleted := make(map[int]bool)
+		user := ""
+		for {
+			line, ok := readLine(r)
+			if !ok {
+				return
+			}
+			verb, args, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "USER":
+				user = args
+				fmt.Fprint(conn, "+OK\r\n")
+			case "PASS":
+				if user != username || args != password {
+					fmt.Fprint(conn, "-ERR [AUTH] Invalid credentials\r\n")
+					return
+				}
+				fmt.Fprint(conn, "+OK Logged in\r\n")
+			case "STAT":
+				size := 0
+				for _, m := range messages {
+					size += len(m.data)
+				}
+				fmt.Fprintf(conn, "+OK %d %d\r\n", len(messages), size)
+			case "TOP":
+				var i, lines int
+				fmt.Sscanf(args, "%d %d", &i, &lines)
+				if i < 1 || i > len(messages) {
+					fmt.Fprint(conn, "-ERR No such message\r\n")
+					continue
+				}
+				headers, _, _ := strings.Cut(messages[i-1].data, "\r\n\r\n")
+				fmt.Fprint(conn, "+OK\r\n")
+				for _, header := range strings.Split(headers, "\r\n") {
+					if strings.HasPrefix(header, ".") {
+						header = "." + header
+					}
+					fmt.Fprintf(conn, "%s\r\n", header)
+				}
+				fmt.Fprint(conn, "\r\n.\r\n")
+			case "DELE":
+				i, _ := strconv.Atoi(args)
+				if i < 1 || i > len(messages) {
+					fmt.Fprint(conn, "-ERR No such message\r\n")
+					continue
+				}
+				deleted[messages[i-1].uid] = true
+				fmt.Fprint(conn, "+OK Deleted\r\n")
+			case "QUIT":
+				store.remove(deleted)
+				fmt.Fprint(conn, "+OK Bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "-ERR Unknown command\r\n")
+			}
+		}
+	}
+}
+
+// newFakeMailCheck starts the fake servers and returns a config using them
+func newFakeMailCheck(t *testing.T, store *fakeMailStore, protocol string) *MailConfig {
+	t.Helper()
+
+	cfg := NewMailConfig()
+	cfg.SMTP = MailServer{Host: "127.0.0.1", Port: serveFake(t, fakeSMTP(store)), Security: SecurityNone}
+	cfg.From = "monitor@example.test"
+	cfg.To = "inbox@example.test"
+	cfg.Mailbox.Protocol = protocol
+	cfg.Mailbox.Host = "127.0.0.1"
+	cfg.Mailbox.Security = SecurityNone
+	cfg.Mailbox.Username = "inbox"
+	cfg.Mailbox.Password = "s3cret"
+	cfg.Timeout = Duration(5 * time.Second)
+	cfg.PollInterval = Duration(20 * time.Millisecond)
+
+	if protocol == "pop3" {
+		cfg.Mailbox.Port = serveFake(t, fakePOP3(store, "inbox", "s3cret"))
+	} else {
+		cfg.Mailbox.Port = serveFake(t, fakeIMAP(store, "inbox", "s3cret"))
+	}
+	return cfg
+}
+
+func TestMailRoundTrip(t *testing.T) {
+	for _, protocol := range []string{"imap", "pop3"} {
+		for _, keep := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s keep=%v", protocol, keep), func(t *testing.T) {
+				store := &fakeMailStore{delay: 100 * time.Millisecond}
+				cfg := newFakeMailCheck(t, store, protocol)
+				cfg.Keep = keep
+
+				result, err := RunMailCheck(context.Background(), cfg)
+				if err != nil {
+					t.Fatalf("RunMailCheck: %v", err)
+				}
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+				}
+
+				// The message is delivered after the first poll
+				if polls := result.Metadata["polls"].(int); polls < 2 {
+					t.Errorf("polls = %d, want the mailbox polled until delivery", polls)
+				}
+				if result.Metadata["delivery_time_ms"].(int64) < 100 {
+					t.Errorf("delivery_time_ms = %v, want at least the delivery delay", result.Metadata["delivery_time_ms"])
+				}
+
+				messages := store.snapshot()
+				if keep && len(messages) != 1 {
+					t.Errorf("mailbox has %d messages, want the message kept", len(messages))
+				}
+				if !keep && len(messages) != 0 {
+					t.Errorf("mailbox has %d messages, want the message deleted", len(messages))
+				}
+				if len(messages) == 1 {
+					token := strings.Trim(result.Metadata["message_id"].(string), "<>")
+					if !strings.Contains(messages[0].data, "Message-ID: <"+token+">") {
+						t.Errorf("stored message does not carry the token:\n%s", messages[0].data)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMailIgnoresOtherMessages(t *testing.T) {
+	store := &fakeMailStore{}
+	store.deliver("Subject: Moogie mail check moogie-0000000000000000\r\n\r\nAn earlier check\r\n")
+	time.Sleep(10 * time.Millisecond)
+
+	cfg := newFakeMailCheck(t, store, "pop3")
+	result, err := RunMailCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunMailCheck: %v", err)
+	}
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if messages := store.snapshot(); len(messages) != 1 || !strings.Contains(messages[0].data, "An earlier check") {
+		t.Errorf("mailbox = %v, want only the other message left", messages)
+	}
+}
+
+func TestMailNotDelivered(t *testing.T) {
+	for _, protocol := range []string{"imap", "pop3"} {
+		t.Run(protocol, func(t *testing.T) {
+			store := &fakeMailStore{drop: true}
+			cfg := newFakeMailCheck(t, store, protocol)
+			cfg.Timeout = Duration(200 * time.Millisecond)
+
+			result, err := RunMailCheck(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("RunMailCheck: %v", err)
+			}
+			if result.Status != StatusError || !strings.Contains(result.ErrorMessage, "not delivered within") {
+				t.Errorf("status = %q (%s), want a delivery timeout", result.Status, result.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestMailMailboxLoginFails(t *testing.T) {
+	store := &fakeMailStore{}
+	cfg := newFakeMailCheck(t, store, "imap")
+	cfg.Mailbox.Password = "wrong"
+
+	result, err := RunMailCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunMailCheck: %v", err)
+	}
+	if result.Status != StatusError || !strings.Contains(result.ErrorMessage, "Mailbox login failed: LOGIN rejected") {
+		t.Errorf("status = %q (%s), want a login failure", result.Status, result.ErrorMessage)
+	}
+	if strings.Contains(result.ErrorMessage, "wrong") {
+		t.Errorf("error message leaks the password: %s", result.ErrorMessage)
+	}
+
+	// Nothing is sent when the mailbox cannot be read
+	time.Sleep(10 * time.Millisecond)
+	if messages := store.snapshot(); len(messages) != 0 {
+		t.Errorf("mailbox has %d messages, want none sent", len(messages))
+	}
+}
+
+func TestIMAPReadResponseLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{"plain line", "* SEARCH 1 2\r\n", "* SEARCH 1 2", ""},
+		{"literal", "* 1 FETCH (BODY[] {5}\r\nhello)\r\n", "* 1 FETCH (BODY[] {5}hello)", ""},
+		{"literal at the limit", "* X {65536}\r\n" + strings.Repeat("a", 65536) + "\r\n", "* X {65536}" + strings.Repeat("a", 65536), ""},
+		{"literal over the limit", "* X {65537}\r\n", "", "exceeds limit"},
+		{"literal size overflows", "* X {99999999999999999999}\r\n", "", "exceeds limit"},
+		{"truncated literal", "* X {10}\r\nabc", "", "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &imapMailbox{r: bufio.NewReader(strings.NewReader(tt.data))}
+			got, err := m.readResponse()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("readResponse() = %.40q, %v, want %.40q", got, err, tt.want)
+			}
+		})
+	}
+}
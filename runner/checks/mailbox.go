@@ -0,0 +1,280 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mailbox finds a message tagged with a token
+type mailbox interface {
+	// find reports whether the message has arrived, deleting it if remove is
+	// set. A failed deletion is returned as an error alongside found.
+	find(token string, remove bool) (found bool, err error)
+	Close() error
+}
+
+// openMailbox logs in to the mailbox. IMAP keeps one connection open for
+// every poll; POP3 servers only show new messages in a new session, so POP3
+// connects on each poll instead.
+func openMailbox(ctx context.Context, cfg *MailboxConfig) (mailbox, error) {
+	if cfg.Protocol == "pop3" {
+		return &pop3Mailbox{ctx: ctx, cfg: cfg}, nil
+	}
+	return openIMAP(ctx, cfg)
+}
+
+// upgradeMailConn asks the server to switch to TLS with the protocol's STARTTLS
+// command and completes the handshake
+func upgradeMailConn(ctx context.Context, conn net.Conn, cfg *MailServer, protocol string) (net.Conn, error) {
+	if err := startTLS(conn, protocol); err != nil {
+		return nil, fmt.Errorf("STARTTLS failed: %w", err)
+	}
+	tlsConn := tls.Client(conn, cfg.tlsConfig())
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// imapMailbox is a logged-in IMAP session with the folder selected
+type imapMailbox struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+func openIMAP(ctx context.Context, cfg *MailboxConfig) (*imapMailbox, error) {
+	conn, err := cfg.dial(ctx, "imap")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &imapMailbox{conn: conn, r: bufio.NewReader(conn)}
+	if cfg.Security == SecuritySTARTTLS {
+		if m.conn, err = upgradeMailConn(ctx, conn, &cfg.MailServer, "imap"); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		m.r = bufio.NewReader(m.conn)
+	} else if _, err := readReply(m.r, "* OK"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	if _, err := m.command("LOGIN %s %s", imapQuote(cfg.Username), imapQuote(cfg.password())); err != nil {
+		m.conn.Close()
+		return nil, err
+	}
+	if _, err := m.command("SELECT %s", imapQuote(cfg.Folder)); err != nil {
+		m.conn.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// find searches the folder by subject, which every server indexes
+func (m *imapMailbox) find(token string, remove bool) (bool, error) {
+	lines, err := m.command("UID SEARCH SUBJECT %s", imapQuote(token))
+	if err != nil {
+		return false, err
+	}
+	var uids []string
+	for _, line := range lines {
+		if rest, ok := strings.CutPrefix(line, "* SEARCH"); ok {
+			uids = append(uids, strings.Fields(rest)...)
+		}
+	}
+	if len(uids) == 0 {
+		return false, nil
+	}
+
+	if remove {
+		if _, err := m.command(`UID STORE %s +FLAGS.SILENT (\Deleted)`, strings.Join(uids, ",")); err != nil {
+			return true, err
+		}
+		if _, err := m.command("EXPUNGE"); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+func (m *imapMailbox) Close() error {
+	m.command("LOGOUT")
+	return m.conn.Close()
+}
+
+// command sends a tagged command and returns the untagged responses that
+// precede the tagged OK
+func (m *imapMailbox) command(format string, args ...interface{}) ([]string, error) {
+	m.tag++
+	tag := fmt.Sprintf("m%03d", m.tag)
+	line := fmt.Sprintf(format, args...)
+	if _, err := fmt.Fprintf(m.conn, "%s %s\r\n", tag, line); err != nil {
+		return nil, err
+	}
+
+	var untagged []string
+	for {
+		response, err := m.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		status, ok := strings.CutPrefix(response, tag+" ")
+		if !ok {
+			untagged = append(untagged, response)
+			continue
+		}
+		if !strings.HasPrefix(status, "OK") {
+			// Only the command name, since LOGIN carries the password
+			return nil, fmt.Errorf("%s rejected: %s", strings.Fields(line)[0], status)
+		}
+		return untagged, nil
+	}
+}
+
+// imapLiteral matches the {size} announcing a literal at the end of a line
+var imapLiteral = regexp.MustCompile(`\{(\d+)\}$`)
+
+// imapMaxLiteral caps the size of a literal read from the server. The check
+// only reads search results, which never carry large literals.
+const imapMaxLiteral = 64 * 1024
+
+// readResponse reads one response, including any literals it contains
+func (m *imapMailbox) readResponse() (string, error) {
+	var response strings.Builder
+	for {
+		line, err := readReplyLine(m.r)
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		response.WriteString(line)
+
+		match := imapLiteral.FindStringSubmatch(line)
+		if match == nil {
+			return response.String(), nil
+		}
+		size, err := strconv.Atoi(match[1])
+		if err != nil || size > imapMaxLiteral {
+			return "", fmt.Errorf("IMAP literal of %s bytes exceeds limit of %d", match[1], imapMaxLiteral)
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(m.r, literal); err != nil {
+			return "", err
+		}
+		response.Write(literal)
+	}
+}
+
+// imapQuote returns s as an IMAP quoted string
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// pop3ScanLimit is how many of the newest messages are searched for the token
+const pop3ScanLimit = 50
+
+// pop3Mailbox logs in to a POP3 mailbox on every poll
+type pop3Mailbox struct {
+	ctx context.Context
+	cfg *MailboxConfig
+}
+
+// find reads the headers of the newest messages. A deletion is only applied
+// when the session ends with QUIT.
+func (m *pop3Mailbox) find(token string, remove bool) (bool, error) {
+	conn, err := m.cfg.dial(m.ctx, "pop3")
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	if m.cfg.Security == SecuritySTARTTLS {
+		if conn, err = upgradeMailConn(m.ctx, conn, &m.cfg.MailServer, "pop3"); err != nil {
+			return false, err
+		}
+		r = bufio.NewReader(conn)
+	} else if _, err := readReply(r, "+OK"); err != nil {
+		return false, fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	if err := command(conn, r, "USER "+m.cfg.Username, "+OK"); err != nil {
+		return false, err
+	}
+	if err := command(conn, r, "PASS "+m.cfg.password(), "+OK"); err != nil {
+		return false, err
+	}
+
+	if _, err := fmt.Fprint(conn, "STAT\r\n"); err != nil {
+		return false, err
+	}
+	stat, err := readReply(r, "+OK")
+	if err != nil {
+		return false, fmt.Errorf("STAT rejected: %w", err)
+	}
+	fields := strings.Fields(stat)
+	if len(fields) < 2 {
+		return false, fmt.Errorf("invalid STAT response: %s", stat)
+	}
+	count, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return false, fmt.Errorf("invalid STAT response: %s", stat)
+	}
+
+	found := false
+	for i := count; i > 0 && i > count-pop3ScanLimit; i-- {
+		headers, err := pop3MultiLine(conn, r, fmt.Sprintf("TOP %d 0", i))
+		if err != nil {
+			return false, err
+		}
+		if !strings.Contains(headers, token) {
+			continue
+		}
+		found = true
+		if remove {
+			if err := command(conn, r, fmt.Sprintf("DELE %d", i), "+OK"); err != nil {
+				return true, err
+			}
+		}
+		break
+	}
+
+	if err := command(conn, r, "QUIT", "+OK"); err != nil && found && remove {
+		return true, err
+	}
+	return found, nil
+}
+
+func (m *pop3Mailbox) Close() error {
+	return nil
+}
+
+// pop3MultiLine sends a command with a multi-line reply and returns the reply
+// body, which ends with a line holding a single dot
+func pop3MultiLine(conn net.Conn, r *bufio.Reader, line string) (string, error) {
+	if err := command(conn, r, line, "+OK"); err != nil {
+		return "", err
+	}
+	var body strings.Builder
+	for {
+		line, err := readReplyLine(r)
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			return body.String(), nil
+		}
+		body.WriteString(strings.TrimPrefix(line, "."))
+		body.WriteString("\n")
+	}
+}
@@ -0,0 +1,188 @@
+package checks
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// NTPConfig checks an NTP server's clock. JSON field names match the UdpCheck spec.
+type NTPConfig struct {
+	MaxOffset  Duration `json:"maxOffset"`  // Largest allowed difference from the local clock
+	MaxStratum int      `json:"maxStratum"` // Highest allowed stratum
+}
+
+// NewNTPConfig returns an NTPConfig with defaults applied
+func NewNTPConfig() *NTPConfig {
+	return &NTPConfig{
+		MaxOffset:  Duration(time.Second),
+		MaxStratum: 15,
+	}
+}
+
+// Validate reports whether the thresholds are usable
+func (c *NTPConfig) Validate() error {
+	if c.MaxOffset <= 0 {
+		return fmt.Errorf("NTP max offset must be positive")
+	}
+	if c.MaxStratum < 1 || c.MaxStratum > 15 {
+		return fmt.Errorf("NTP max stratum must be between 1 and 15")
+	}
+	return nil
+}
+
+const (
+	ntpPort       = 123
+	ntpPacketSize = 48
+
+	// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+	ntpEpochOffset = 2208988800
+
+	ntpModeClient = 3
+	ntpModeServer = 4
+
+	// ntpLeapAlarm marks a server whose clock is not synchronized
+	ntpLeapAlarm = 3
+)
+
+// ntpResponse is the part of a server's NTP packet the check reports on
+type ntpResponse struct {
+	Leap           uint8
+	Version        uint8
+	Mode           uint8
+	Stratum        uint8
+	RootDelay      time.Duration
+	RootDispersion time.Duration
+	ReferenceID    [4]byte
+	Origin         time.Time
+	Receive        time.Time
+	Transmit       time.Time
+}
+
+// checkNTP sends an SNTP client request (RFC 4330) and fails if the server is
+// unsynchronized or its clock offset or stratum exceed the thresholds
+func checkNTP(ctx context.Context, conn net.Conn, cfg *UDPConfig, result *CheckResult) error {
+	conn.SetDeadline(cfg.readDeadline(ctx.Deadline()))
+
+	request := make([]byte, ntpPacketSize)
+	request[0] = 4<<3 | ntpModeClient // leap 0, version 4
+	sent := time.Now()
+	origin := toNTPTime(sent)
+	binary.BigEndian.PutUint64(request[40:], origin)
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("Failed to send NTP request: %v", err)
+	}
+
+	// Skip invalid packets and stray or late replies to other requests until
+	// the deadline, reporting the last invalid packet if no reply arrives
+	buf := make([]byte, udpMaxDatagram)
+	var response *ntpResponse
+	var received time.Time
+	var invalid error
+	for response == nil {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if invalid != nil {
+				return fmt.Errorf("No valid NTP response received: %v", invalid)
+			}
+			return fmt.Errorf("No NTP response received: %v", err)
+		}
+		received = time.Now()
+		packet, err := parseNTP(buf[:n])
+		if err != nil {
+			invalid = err
+			continue
+		}
+		if packet.Origin.Equal(fromNTPTime(origin)) {
+			response = packet
+		}
+	}
+
+	// The offset and round-trip delay use the four timestamps of the exchange.
+	// The delay uses the monotonic clock for the local side.
+	offset := (response.Receive.Sub(sent) + response.Transmit.Sub(received)) / 2
+	delay := received.Sub(sent) - response.Transmit.Sub(response.Receive)
+
+	result.ResponseTimeMs = delay.Milliseconds()
+	result.Metadata["ntp_offset_ms"] = milliseconds(offset)
+	result.Metadata["ntp_delay_ms"] = milliseconds(delay)
+	result.Metadata["ntp_stratum"] = response.Stratum
+	result.Metadata["ntp_version"] = response.Version
+	result.Metadata["ntp_leap"] = response.Leap
+	result.Metadata["ntp_reference_id"] = ntpReferenceID(response)
+	result.Metadata["ntp_root_delay_ms"] = milliseconds(response.RootDelay)
+	result.Metadata["ntp_root_dispersion_ms"] = milliseconds(response.RootDispersion)
+	result.Metadata["ntp_server_time"] = response.Transmit.UTC()
+
+	switch {
+	case response.Stratum == 0:
+		return fmt.Errorf("NTP server sent kiss-of-death %q", ntpReferenceID(response))
+	case response.Leap == ntpLeapAlarm || response.Stratum > 15:
+		return fmt.Errorf("NTP server is not synchronized")
+	case int(response.Stratum) > cfg.NTP.MaxStratum:
+		return fmt.Errorf("NTP stratum %d is above the maximum of %d", response.Stratum, cfg.NTP.MaxStratum)
+	case offset.Abs() > cfg.NTP.MaxOffset.Std():
+		return fmt.Errorf("NTP clock offset %s exceeds the maximum of %s", offset.Round(time.Microsecond), cfg.NTP.MaxOffset.Std())
+	}
+	return nil
+}
+
+// parseNTP decodes a server's NTP packet
+func parseNTP(packet []byte) (*ntpResponse, error) {
+	if len(packet) < ntpPacketSize {
+		return nil, fmt.Errorf("NTP response too short: %d bytes", len(packet))
+	}
+
+	response := &ntpResponse{
+		Leap:           packet[0] >> 6,
+		Version:        packet[0] >> 3 & 0x7,
+		Mode:           packet[0] & 0x7,
+		Stratum:        packet[1],
+		RootDelay:      fromNTPShort(binary.BigEndian.Uint32(packet[4:])),
+		RootDispersion: fromNTPShort(binary.BigEndian.Uint32(packet[8:])),
+		Origin:         fromNTPTime(binary.BigEndian.Uint64(packet[24:])),
+		Receive:        fromNTPTime(binary.BigEndian.Uint64(packet[32:])),
+		Transmit:       fromNTPTime(binary.BigEndian.Uint64(packet[40:])),
+	}
+	copy(response.ReferenceID[:], packet[12:16])
+
+	if response.Mode != ntpModeServer {
+		return nil, fmt.Errorf("unexpected NTP mode %d in response", response.Mode)
+	}
+	return response, nil
+}
+
+// ntpReferenceID formats the reference ID: a clock source or kiss code for
+// stratum 0 and 1 servers, otherwise the IPv4 address of the upstream server
+func ntpReferenceID(response *ntpResponse) string {
+	if response.Stratum <= 1 {
+		end := 0
+		for end < len(response.ReferenceID) && response.ReferenceID[end] != 0 {
+			end++
+		}
+		return string(response.ReferenceID[:end])
+	}
+	return net.IP(response.ReferenceID[:]).String()
+}
+
+// toNTPTime converts a time to a 64-bit NTP timestamp: seconds since 1900 and a 32-bit fraction
+func toNTPTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// fromNTPTime converts a 64-bit NTP timestamp to a time
+func fromNTPTime(ntp uint64) time.Time {
+	seconds := int64(ntp>>32) - ntpEpochOffset
+	nanos := (ntp & 0xffffffff) * uint64(time.Second) >> 32
+	return time.Unix(seconds, int64(nanos))
+}
+
+// fromNTPShort converts a 32-bit NTP short format (16.16 fixed point seconds) to a duration
+func fromNTPShort(v uint32) time.Duration {
+	return time.Duration(v) * time.Second >> 16
+}
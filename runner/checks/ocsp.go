@@ -0,0 +1,107 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPConfig configures revocation checking for an SSL check. A stapled
+// response is used when the server sends one; otherwise the responder is queried.
+type OCSPConfig struct {
+	Responder       string `json:"responder"`       // Responder URL (default: from the certificate)
+	RequireStapling bool   `json:"requireStapling"` // Fail if the server does not staple a response
+}
+
+// checkOCSP checks the leaf certificate's revocation status and records it in the result
+func checkOCSP(ctx context.Context, cfg *OCSPConfig, cert, issuer *x509.Certificate, stapled []byte, timeout time.Duration, result *CheckResult) error {
+	if issuer == nil {
+		return fmt.Errorf("OCSP check failed: issuer certificate not available")
+	}
+
+	source := "stapled"
+	raw := stapled
+	if len(raw) == 0 {
+		if cfg.RequireStapling {
+			result.Metadata["ocsp_stapled"] = false
+			return fmt.Errorf("Server did not staple an OCSP response")
+		}
+
+		responder := cfg.Responder
+		if responder == "" {
+			if len(cert.OCSPServer) == 0 {
+				return fmt.Errorf("OCSP check failed: certificate has no OCSP responder")
+			}
+			responder = cert.OCSPServer[0]
+		}
+
+		var err error
+		raw, err = queryOCSP(ctx, responder, cert, issuer, timeout)
+		if err != nil {
+			return fmt.Errorf("OCSP check failed: %v", err)
+		}
+		source = responder
+	}
+	result.Metadata["ocsp_stapled"] = len(stapled) > 0
+	result.Metadata["ocsp_source"] = source
+
+	response, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+	if err != nil {
+		return fmt.Errorf("OCSP check failed: invalid response: %v", err)
+	}
+
+	result.Metadata["ocsp_this_update"] = response.ThisUpdate
+	if !response.NextUpdate.IsZero() {
+		result.Metadata["ocsp_next_update"] = response.NextUpdate
+		if time.Now().After(response.NextUpdate) {
+			return fmt.Errorf("OCSP response is stale (next update was %s)", response.NextUpdate.Format(time.RFC3339))
+		}
+	}
+
+	switch response.Status {
+	case ocsp.Good:
+		result.Metadata["ocsp_status"] = "good"
+		return nil
+	case ocsp.Revoked:
+		result.Metadata["ocsp_status"] = "revoked"
+		result.Metadata["ocsp_revoked_at"] = response.RevokedAt
+		return fmt.Errorf("Certificate was revoked on %s", response.RevokedAt.Format(time.RFC3339))
+	default:
+		result.Metadata["ocsp_status"] = "unknown"
+		return fmt.Errorf("OCSP responder does not know the certificate")
+	}
+}
+
+// queryOCSP sends an OCSP request for the certificate to the responder
+func queryOCSP(ctx context.Context, responder string, cert, issuer *x509.Certificate, timeout time.Duration) ([]byte, error) {
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responder, bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("responder returned %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,190 @@
+package checks
+
+import (
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// ocspResponse signs a response about cert with the issuing CA's key
+func ocspResponse(t *testing.T, ca *testCA, cert *x509.Certificate, template ocsp.Response) []byte {
+	t.Helper()
+	template.SerialNumber = cert.SerialNumber
+	if template.ThisUpdate.IsZero() {
+		template.ThisUpdate = time.Now().Add(-time.Hour)
+	}
+	response, err := ocsp.CreateResponse(ca.cert, ca.cert, template, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// startOCSPResponder answers every request with respond's result and returns
+// the responder URL
+func startOCSPResponder(t *testing.T, respond func(req *ocsp.Request) ([]byte, int)) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil || r.Header.Get("Content-Type") != "application/ocsp-request" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response, status := respond(req)
+		w.WriteHeader(status)
+		w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestSSLOCSPResponder(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	revokedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		response   ocsp.Response
+		httpStatus int
+		wantStatus string
+		wantErr    string
+	}{
+		{name: "good", response: ocsp.Response{Status: ocsp.Good, NextUpdate: time.Now().Add(time.Hour)}, wantStatus: "good"},
+		{name: "good without next update", response: ocsp.Response{Status: ocsp.Good}, wantStatus: "good"},
+		{
+			name:       "revoked",
+			response:   ocsp.Response{Status: ocsp.Revoked, RevokedAt: revokedAt, RevocationReason: ocsp.KeyCompromise},
+			wantStatus: "revoked",
+			wantErr:    "Certificate was revoked on 2024-03-01T12:00:00Z",
+		},
+		{name: "unknown", response: ocsp.Response{Status: ocsp.Unknown}, wantStatus: "unknown", wantErr: "OCSP responder does not know the certificate"},
+		{
+			name:     "stale",
+			response: ocsp.Response{Status: ocsp.Good, ThisUpdate: time.Now().Add(-48 * time.Hour), NextUpdate: time.Now().Add(-24 * time.Hour)},
+			wantErr:  "OCSP response is stale",
+		},
+		{name: "responder error", httpStatus: http.StatusInternalServerError, wantErr: "OCSP check failed: responder returned 500 Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var leaf *x509.Certificate
+			responder := startOCSPResponder(t, func(req *ocsp.Request) ([]byte, int) {
+				if tt.httpStatus != 0 {
+					return nil, tt.httpStatus
+				}
+				if req.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+					return nil, http.StatusNotFound
+				}
+				return ocspResponse(t, root, leaf, tt.response), http.StatusOK
+			})
+			leaf, key := root.issue(t, func(c *x509.Certificate) { c.OCSPServer = []string{responder} })
+			cfg := newTestSSLConfig(startTLSTestServer(t, serverCertificate(leaf, key), nil), root)
+			cfg.OCSP = &OCSPConfig{}
+
+			result := runSSL(t, cfg)
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+			} else if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, tt.wantErr) {
+				t.Fatalf("status = %q (%s), want an error starting with %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if tt.wantStatus != "" {
+				if result.Metadata["ocsp_status"] != tt.wantStatus {
+					t.Errorf("ocsp_status = %v, want %s", result.Metadata["ocsp_status"], tt.wantStatus)
+				}
+				if result.Metadata["ocsp_source"] != responder || result.Metadata["ocsp_stapled"] != false {
+					t.Errorf("ocsp_source = %v, ocsp_stapled = %v, want the responder", result.Metadata["ocsp_source"], result.Metadata["ocsp_stapled"])
+				}
+			}
+		})
+	}
+}
+
+func TestSSLOCSPConfiguredResponder(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	leaf, key := root.issue(t, nil)
+	responder := startOCSPResponder(t, func(*ocsp.Request) ([]byte, int) {
+		return ocspResponse(t, root, leaf, ocsp.Response{Status: ocsp.Good}), http.StatusOK
+	})
+	port := startTLSTestServer(t, serverCertificate(leaf, key), nil)
+
+	// The certificate names no responder
+	cfg := newTestSSLConfig(port, root)
+	cfg.OCSP = &OCSPConfig{}
+	result := runSSL(t, cfg)
+	if result.Status != StatusError || result.ErrorMessage != "OCSP check failed: certificate has no OCSP responder" {
+		t.Errorf("without a responder: status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+
+	cfg.OCSP.Responder = responder
+	result = runSSL(t, cfg)
+	if result.Status != StatusSuccess || result.Metadata["ocsp_source"] != responder {
+		t.Errorf("with a responder: status = %q (%s), ocsp_source = %v", result.Status, result.ErrorMessage, result.Metadata["ocsp_source"])
+	}
+}
+
+func TestSSLOCSPStapling(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	leaf, key := root.issue(t, func(c *x509.Certificate) { c.OCSPServer = []string{"http://127.0.0.1:1/unused"} })
+
+	// A server that does not staple fails when stapling is required
+	port := startTLSTestServer(t, serverCertificate(leaf, key), nil)
+	cfg := newTestSSLConfig(port, root)
+	cfg.OCSP = &OCSPConfig{RequireStapling: true}
+	result := runSSL(t, cfg)
+	if result.Status != StatusError || result.ErrorMessage != "Server did not staple an OCSP response" {
+		t.Errorf("not stapled: status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["ocsp_stapled"] != false {
+		t.Errorf("not stapled: ocsp_stapled = %v", result.Metadata["ocsp_stapled"])
+	}
+
+	tests := []struct {
+		name     string
+		response ocsp.Response
+		wantErr  string
+	}{
+		{name: "good", response: ocsp.Response{Status: ocsp.Good}},
+		{name: "revoked", response: ocsp.Response{Status: ocsp.Revoked, RevokedAt: time.Now().Add(-time.Hour)}, wantErr: "Certificate was revoked on"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certificate := serverCertificate(leaf, key)
+			certificate.OCSPStaple = ocspResponse(t, root, leaf, tt.response)
+			cfg := newTestSSLConfig(startTLSTestServer(t, certificate, nil), root)
+			cfg.OCSP = &OCSPConfig{RequireStapling: true}
+
+			// The stapled response is used without contacting the responder
+			result := runSSL(t, cfg)
+			if tt.wantErr == "" && result.Status != StatusSuccess {
+				t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+			}
+			if tt.wantErr != "" && (result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, tt.wantErr)) {
+				t.Fatalf("status = %q (%s), want an error starting with %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if result.Metadata["ocsp_stapled"] != true || result.Metadata["ocsp_source"] != "stapled" {
+				t.Errorf("ocsp_stapled = %v, ocsp_source = %v", result.Metadata["ocsp_stapled"], result.Metadata["ocsp_source"])
+			}
+		})
+	}
+
+	// A staple signed by another CA is rejected
+	otherRoot := newTestCA(t, "Other Root", nil, nil)
+	certificate := serverCertificate(leaf, key)
+	certificate.OCSPStaple = ocspResponse(t, otherRoot, leaf, ocsp.Response{Status: ocsp.Good})
+	cfg = newTestSSLConfig(startTLSTestServer(t, certificate, nil), root)
+	cfg.OCSP = &OCSPConfig{}
+	result = runSSL(t, cfg)
+	if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, "OCSP check failed: invalid response") {
+		t.Errorf("foreign staple: status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+}
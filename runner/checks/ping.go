@@ -0,0 +1,335 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// PingConfig configures an ICMP echo check. JSON field names match the PingCheck spec.
+type PingConfig struct {
+	Host                string   `json:"host"`
+	Count               int      `json:"count"`
+	Interval            Duration `json:"interval"`
+	Timeout             Duration `json:"timeout"`             // How long to wait for each reply
+	PacketLossThreshold float64  `json:"packetLossThreshold"` // Fail when loss exceeds this percentage
+}
+
+// NewPingConfig returns a PingConfig with defaults applied. By default only
+// total packet loss fails the check.
+func NewPingConfig() *PingConfig {
+	return &PingConfig{
+		Count:               4,
+		Interval:            Duration(time.Second),
+		Timeout:             Duration(5 * time.Second),
+		PacketLossThreshold: 100,
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
+//   - PING_HOST: Target host (required)
+//   - PING_COUNT: Number of echo requests (default: 4)
+//   - PING_INTERVAL: Time between requests (default: 1s)
+//   - PING_TIMEOUT: Time to wait for each reply (default: 5s)
+//   - PING_PACKET_LOSS_THRESHOLD: Fail when packet loss exceeds this percentage (default: 100)
+func (c *PingConfig) LoadEnv() error {
+	envString("PING_HOST", &c.Host)
+	if c.Host == "" {
+		return fmt.Errorf("PING_HOST environment variable is required")
+	}
+
+	if err := envInt("PING_COUNT", &c.Count); err != nil {
+		return err
+	}
+	if err := envDuration("PING_INTERVAL", &c.Interval); err != nil {
+		return err
+	}
+	if err := envDuration("PING_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	return envFloat("PING_PACKET_LOSS_THRESHOLD", &c.PacketLossThreshold)
+}
+
+// Validate reports whether the configuration is complete
+func (c *PingConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if c.Count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	if c.PacketLossThreshold < 0 || c.PacketLossThreshold > 100 {
+		return fmt.Errorf("packetLossThreshold must be between 0 and 100")
+	}
+	return nil
+}
+
+// pingChecker runs ICMP echo checks
+type pingChecker struct{}
+
+func init() {
+	Register(pingChecker{})
+}
+
+func (pingChecker) Name() string {
+	return "ping"
+}
+
+func (pingChecker) NewConfig() Config {
+	return NewPingConfig()
+}
+
+func (pingChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*PingConfig]("ping", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunPingCheck(ctx, c)
+}
+
+// icmpFamily holds the protocol details that differ between IPv4 and IPv6
+type icmpFamily struct {
+	protocol    int
+	echoRequest icmp.Type
+	echoReply   icmp.Type
+	datagramNet string // unprivileged ICMP datagram socket
+	rawNet      string // raw socket, needs root or CAP_NET_RAW
+	listenAddr  string
+}
+
+var (
+	icmpV4 = icmpFamily{1, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply, "udp4", "ip4:icmp", "0.0.0.0"}
+	icmpV6 = icmpFamily{58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, "udp6", "ip6:ipv6-icmp", "::"}
+)
+
+// RunPingCheck sends ICMP echo requests and reports round-trip statistics.
+// It uses an unprivileged datagram socket where the kernel allows it (see
+// net.ipv4.ping_group_range on Linux) and falls back to a raw socket.
+func RunPingCheck(ctx context.Context, cfg *PingConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	result.Metadata["host"] = cfg.Host
+
+	ip, err := resolvePingTarget(ctx, cfg.Host)
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("Failed to resolve host: %v", err)
+		return result, nil
+	}
+	result.Metadata["ip"] = ip.String()
+
+	family := icmpV6
+	if ip.To4() != nil {
+		family = icmpV4
+	}
+
+	conn, privileged, err := listenICMP(family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ICMP socket: %w", err)
+	}
+	defer conn.Close()
+
+	if privileged {
+		result.Metadata["socket"] = "raw"
+	} else {
+		result.Metadata["socket"] = "datagram"
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: ip}
+	if privileged {
+		dst = &net.IPAddr{IP: ip}
+	}
+
+	// Raw sockets see every echo reply the host receives, so each check uses
+	// a random identifier and starting sequence number to avoid taking the
+	// replies to concurrent pings. The kernel assigns the identifier for
+	// datagram sockets, so replies are only matched on it for raw sockets.
+	id := rand.IntN(0x10000)
+	firstSeq := rand.IntN(0x10000)
+
+	var rtts []time.Duration
+	sent := 0
+	for i := 0; i < cfg.Count; i++ {
+		if i > 0 && !sleep(ctx, cfg.Interval.Std()) {
+			break
+		}
+
+		seq := (firstSeq + i) & 0xffff
+		rtt, err := pingOnce(ctx, conn, family, dst, id, seq, privileged, cfg.Timeout.Std())
+		if err != nil {
+			return nil, err
+		}
+		sent++
+		if rtt > 0 {
+			rtts = append(rtts, rtt)
+		}
+	}
+
+	received := len(rtts)
+	loss := float64(sent-received) / float64(sent) * 100
+
+	result.Metadata["packets_sent"] = sent
+	result.Metadata["packets_received"] = received
+	result.Metadata["packet_loss"] = loss
+
+	if received == 0 {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("No replies from %s (100%% packet loss)", cfg.Host)
+		return result, nil
+	}
+
+	min, avg, max, stddev := rttStats(rtts)
+	result.Metadata["min_rtt_ms"] = milliseconds(min)
+	result.Metadata["avg_rtt_ms"] = milliseconds(avg)
+	result.Metadata["max_rtt_ms"] = milliseconds(max)
+	result.Metadata["stddev_rtt_ms"] = milliseconds(stddev)
+	result.ResponseTimeMs = avg.Milliseconds()
+
+	if loss > cfg.PacketLossThreshold {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("Packet loss %.1f%% exceeds threshold of %.1f%%", loss, cfg.PacketLossThreshold)
+		return result, nil
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// resolvePingTarget resolves host to an IP address, preferring IPv4
+func resolvePingTarget(ctx context.Context, host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			return addr.IP, nil
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return addrs[0].IP, nil
+}
+
+// listenICMP opens an unprivileged datagram socket, falling back to a raw socket
+func listenICMP(family icmpFamily) (*icmp.PacketConn, bool, error) {
+	conn, err := icmp.ListenPacket(family.datagramNet, family.listenAddr)
+	if err == nil {
+		return conn, false, nil
+	}
+
+	raw, rawErr := icmp.ListenPacket(family.rawNet, family.listenAddr)
+	if rawErr != nil {
+		return nil, false, fmt.Errorf("datagram socket: %v; raw socket: %v", err, rawErr)
+	}
+	return raw, true, nil
+}
+
+// pingOnce sends one echo request and waits for the matching reply from dst.
+// It returns a zero duration if no reply arrived within the timeout.
+func pingOnce(ctx context.Context, conn *icmp.PacketConn, family icmpFamily, dst net.Addr, id, seq int, matchID bool, timeout time.Duration) (time.Duration, error) {
+	message := icmp.Message{
+		Type: family.echoRequest,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("moogie-ping")},
+	}
+	packet, err := message.Marshal(nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build echo request: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(packet, dst); err != nil {
+		return 0, fmt.Errorf("failed to send echo request: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("failed to read echo reply: %w", err)
+		}
+		rtt := time.Since(start)
+
+		if !sameHost(peer, dst) {
+			continue
+		}
+
+		reply, err := icmp.ParseMessage(family.protocol, buf[:n])
+		if err != nil || reply.Type != family.echoReply {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq || (matchID && echo.ID != id) {
+			continue
+		}
+		return rtt, nil
+	}
+}
+
+// sameHost reports whether two socket addresses have the same IP address
+func sameHost(a, b net.Addr) bool {
+	ip := func(addr net.Addr) net.IP {
+		switch addr := addr.(type) {
+		case *net.UDPAddr:
+			return addr.IP
+		case *net.IPAddr:
+			return addr.IP
+		}
+		return nil
+	}
+	aIP, bIP := ip(a), ip(b)
+	return aIP != nil && aIP.Equal(bIP)
+}
+
+// rttStats returns the minimum, mean, maximum and population standard deviation
+func rttStats(rtts []time.Duration) (min, avg, max, stddev time.Duration) {
+	min, max = rtts[0], rtts[0]
+	var sum float64
+	for _, rtt := range rtts {
+		if rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		sum += float64(rtt)
+	}
+	mean := sum / float64(len(rtts))
+
+	var variance float64
+	for _, rtt := range rtts {
+		variance += math.Pow(float64(rtt)-mean, 2)
+	}
+	variance /= float64(len(rtts))
+
+	return min, time.Duration(mean), max, time.Duration(math.Sqrt(variance))
+}
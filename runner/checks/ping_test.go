@@ -0,0 +1,57 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSameHost(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b net.Addr
+		want bool
+	}{
+		{"udp", &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}, &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}, true},
+		{"raw", &net.IPAddr{IP: net.ParseIP("2001:db8::1")}, &net.IPAddr{IP: net.ParseIP("2001:db8::1")}, true},
+		{"ipv4 in ipv6 form", &net.UDPAddr{IP: net.ParseIP("::ffff:192.0.2.1")}, &net.UDPAddr{IP: net.ParseIP("192.0.2.1").To4()}, true},
+		{"other host", &net.UDPAddr{IP: net.ParseIP("192.0.2.2")}, &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}, false},
+		{"unknown address type", &net.TCPAddr{IP: net.ParseIP("192.0.2.1")}, &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}, false},
+		{"nil peer", nil, &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameHost(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameHost(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPingLoopback(t *testing.T) {
+	conn, _, err := listenICMP(icmpV4)
+	if err != nil {
+		t.Skipf("ICMP sockets not permitted: %v", err)
+	}
+	conn.Close()
+
+	cfg := &PingConfig{
+		Host:                "127.0.0.1",
+		Count:               3,
+		Interval:            Duration(10 * time.Millisecond),
+		Timeout:             Duration(time.Second),
+		PacketLossThreshold: 0,
+	}
+	result, err := RunPingCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunPingCheck: %v", err)
+	}
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["packets_received"] != 3 {
+		t.Errorf("packets_received = %v, want 3", result.Metadata["packets_received"])
+	}
+}
@@ -0,0 +1,149 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy controls how often a failing check is retried before its
+// result is reported. JSON field names match the common spec fields.
+type RetryPolicy struct {
+	Retries int      `json:"retries"`      // Additional attempts after the first
+	Delay   Duration `json:"retryDelay"`   // Wait before the first retry
+	Backoff float64  `json:"retryBackoff"` // Multiplier applied to the delay after each retry
+}
+
+// NewRetryPolicy returns a RetryPolicy with defaults applied (no retries)
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		Delay:   Duration(time.Second),
+		Backoff: 2,
+	}
+}
+
+// LoadEnv reads the policy from environment variables:
+//   - CHECK_RETRIES: Number of retries after a failed attempt (default: 0)
+//   - CHECK_RETRY_DELAY: Wait before the first retry (default: 1s)
+//   - CHECK_RETRY_BACKOFF: Multiplier applied to the delay after each retry (default: 2)
+func (p *RetryPolicy) LoadEnv() error {
+	if err := envInt("CHECK_RETRIES", &p.Retries); err != nil {
+		return err
+	}
+	if err := envDuration("CHECK_RETRY_DELAY", &p.Delay); err != nil {
+		return err
+	}
+	if err := envFloat("CHECK_RETRY_BACKOFF", &p.Backoff); err != nil {
+		return err
+	}
+	return p.Validate()
+}
+
+// Validate reports whether the policy is usable
+func (p *RetryPolicy) Validate() error {
+	if p.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if p.Delay < 0 {
+		return fmt.Errorf("retryDelay must not be negative")
+	}
+	if p.Backoff < 1 {
+		return fmt.Errorf("retryBackoff must be at least 1")
+	}
+	return nil
+}
+
+// RetryPolicyFromJSON reads the retry fields of a spec or moogie.io/v1 document
+func RetryPolicyFromJSON(data []byte) (*RetryPolicy, error) {
+	data, err := specJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry policy: %w", err)
+	}
+
+	p := NewRetryPolicy()
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid retry policy: %w", err)
+	}
+	if p.Backoff == 0 {
+		p.Backoff = 2
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// maxRetryDelay caps the delay between retries as the backoff grows it. A
+// longer retryDelay is used as is.
+const maxRetryDelay = time.Minute
+
+// retrySleep waits between attempts; tests replace it to record the delays
+var retrySleep = sleep
+
+// Attempt records the outcome of a single run of a check
+type Attempt struct {
+	Attempt        int       `json:"attempt"`
+	Status         string    `json:"status"`
+	ResponseTimeMs int64     `json:"response_time"`
+	Timestamp      time.Time `json:"timestamp"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// RunWithRetries runs a check, retrying failed (but not degraded) attempts according to the
+// policy. The returned result is the one from the last attempt, with every
+// attempt recorded in its metadata:
+//   - attempts: status, duration and error of each attempt
+//   - attempt_count: number of attempts made
+//   - recovered: true if the check passed (or was degraded) after at least one failed attempt
+//
+// An error from the checker (the check could not be run at all) is returned
+// immediately, since retrying would not change it.
+func RunWithRetries(ctx context.Context, c Checker, cfg Config, policy *RetryPolicy) (*CheckResult, error) {
+	if policy == nil {
+		policy = NewRetryPolicy()
+	}
+
+	var attempts []Attempt
+	delay := policy.Delay.Std()
+
+	for attempt := 1; ; attempt++ {
+		result, err := c.Run(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, Attempt{
+			Attempt:        attempt,
+			Status:         result.Status,
+			ResponseTimeMs: result.ResponseTimeMs,
+			Timestamp:      result.Timestamp,
+			Error:          result.ErrorMessage,
+		})
+
+		if result.Status != StatusError || attempt > policy.Retries || !retrySleep(ctx, delay) {
+			if result.Metadata == nil {
+				result.Metadata = make(map[string]interface{})
+			}
+			result.Metadata["attempts"] = attempts
+			result.Metadata["attempt_count"] = len(attempts)
+			result.Metadata["recovered"] = result.Status != StatusError && len(attempts) > 1
+			return result, nil
+		}
+
+		delay = min(time.Duration(float64(delay)*policy.Backoff), max(maxRetryDelay, policy.Delay.Std()))
+	}
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,218 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedChecker returns the given statuses in turn, repeating the last one
+type scriptedChecker struct {
+	statuses []string
+	err      error
+	calls    int
+}
+
+func (s *scriptedChecker) Name() string      { return "scripted" }
+func (s *scriptedChecker) NewConfig() Config { return NewTCPConfig() }
+func (s *scriptedChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	result := NewCheckResult()
+	result.Status = s.statuses[min(s.calls, len(s.statuses))-1]
+	result.ResponseTimeMs = int64(s.calls * 10)
+	if result.Status == StatusError {
+		result.ErrorMessage = fmt.Sprintf("attempt %d failed", s.calls)
+	}
+	return result, nil
+}
+
+// recordSleeps replaces the wait between attempts with one that records the
+// delays and returns immediately
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	retrySleep = func(ctx context.Context, d time.Duration) bool {
+		delays = append(delays, d)
+		return true
+	}
+	t.Cleanup(func() { retrySleep = sleep })
+	return &delays
+}
+
+func TestRunWithRetriesAttempts(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []string
+		retries       int
+		wantCalls     int
+		wantStatus    string
+		wantRecovered bool
+	}{
+		{"no retries", []string{StatusError}, 0, 1, StatusError, false},
+		{"every attempt fails", []string{StatusError}, 3, 4, StatusError, false},
+		{"success stops retries", []string{StatusError, StatusError, StatusSuccess}, 5, 3, StatusSuccess, true},
+		{"first attempt passes", []string{StatusSuccess}, 3, 1, StatusSuccess, false},
+		{"degraded is not retried", []string{StatusDegraded}, 3, 1, StatusDegraded, false},
+		{"recovered as degraded", []string{StatusError, StatusDegraded}, 3, 2, StatusDegraded, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordSleeps(t)
+			checker := &scriptedChecker{statuses: tt.statuses}
+
+			result, err := RunWithRetries(context.Background(), checker, nil, &RetryPolicy{Retries: tt.retries, Delay: Duration(time.Second), Backoff: 2})
+			if err != nil {
+				t.Fatalf("RunWithRetries: %v", err)
+			}
+			if checker.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", checker.calls, tt.wantCalls)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.Metadata["attempt_count"] != tt.wantCalls {
+				t.Errorf("attempt_count = %v, want %d", result.Metadata["attempt_count"], tt.wantCalls)
+			}
+			if result.Metadata["recovered"] != tt.wantRecovered {
+				t.Errorf("recovered = %v, want %v", result.Metadata["recovered"], tt.wantRecovered)
+			}
+		})
+	}
+}
+
+func TestRunWithRetriesRecordsAttempts(t *testing.T) {
+	recordSleeps(t)
+	checker := &scriptedChecker{statuses: []string{StatusError, StatusError, StatusSuccess}}
+
+	result, err := RunWithRetries(context.Background(), checker, nil, &RetryPolicy{Retries: 2, Delay: Duration(time.Second), Backoff: 2})
+	if err != nil {
+		t.Fatalf("RunWithRetries: %v", err)
+	}
+
+	attempts := result.Metadata["attempts"].([]Attempt)
+	if len(attempts) != 3 {
+		t.Fatalf("attempts = %+v, want 3", attempts)
+	}
+	for i, attempt := range attempts {
+		if attempt.Attempt != i+1 || attempt.ResponseTimeMs != int64((i+1)*10) || attempt.Timestamp.IsZero() {
+			t.Errorf("attempt %d = %+v", i+1, attempt)
+		}
+	}
+	if attempts[0].Status != StatusError || attempts[0].Error != "attempt 1 failed" || attempts[1].Error != "attempt 2 failed" {
+		t.Errorf("failed attempts = %+v, %+v", attempts[0], attempts[1])
+	}
+	if attempts[2].Status != StatusSuccess || attempts[2].Error != "" {
+		t.Errorf("last attempt = %+v", attempts[2])
+	}
+	// The result is the last attempt's
+	if result.ResponseTimeMs != 30 {
+		t.Errorf("response time = %d, want the last attempt's 30", result.ResponseTimeMs)
+	}
+}
+
+func TestRunWithRetriesBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   []time.Duration
+	}{
+		{
+			name:   "grows by the backoff",
+			policy: RetryPolicy{Retries: 3, Delay: Duration(time.Second), Backoff: 2},
+			want:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:   "constant without backoff",
+			policy: RetryPolicy{Retries: 3, Delay: Duration(5 * time.Second), Backoff: 1},
+			want:   []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:   "capped at a minute",
+			policy: RetryPolicy{Retries: 5, Delay: Duration(10 * time.Second), Backoff: 3},
+			want:   []time.Duration{10 * time.Second, 30 * time.Second, time.Minute, time.Minute, time.Minute},
+		},
+		{
+			name:   "longer delay is kept",
+			policy: RetryPolicy{Retries: 3, Delay: Duration(2 * time.Minute), Backoff: 2},
+			want:   []time.Duration{2 * time.Minute, 2 * time.Minute, 2 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := recordSleeps(t)
+			policy := tt.policy
+
+			if _, err := RunWithRetries(context.Background(), &scriptedChecker{statuses: []string{StatusError}}, nil, &policy); err != nil {
+				t.Fatalf("RunWithRetries: %v", err)
+			}
+			if !reflect.DeepEqual(*delays, tt.want) {
+				t.Errorf("delays = %v, want %v", *delays, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWithRetriesCheckerError(t *testing.T) {
+	recordSleeps(t)
+	checker := &scriptedChecker{err: errors.New("invalid config")}
+
+	_, err := RunWithRetries(context.Background(), checker, nil, &RetryPolicy{Retries: 3, Delay: Duration(time.Second), Backoff: 2})
+	if err == nil || err.Error() != "invalid config" {
+		t.Errorf("error = %v, want the checker's", err)
+	}
+	if checker.calls != 1 {
+		t.Errorf("calls = %d, want no retries", checker.calls)
+	}
+}
+
+func TestRunWithRetriesCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	checker := &scriptedChecker{statuses: []string{StatusError, StatusSuccess}}
+
+	start := time.Now()
+	result, err := RunWithRetries(ctx, checker, nil, &RetryPolicy{Retries: 3, Delay: Duration(time.Hour), Backoff: 2})
+	if err != nil {
+		t.Fatalf("RunWithRetries: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %s, want the wait cut short", elapsed)
+	}
+	if checker.calls != 1 || result.Status != StatusError || result.Metadata["attempt_count"] != 1 {
+		t.Errorf("calls = %d, status = %q, attempt_count = %v, want the first attempt's result",
+			checker.calls, result.Status, result.Metadata["attempt_count"])
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy RetryPolicy
+		want   string
+	}{
+		{RetryPolicy{Retries: -1, Backoff: 2}, "retries must not be negative"},
+		{RetryPolicy{Delay: Duration(-time.Second), Backoff: 2}, "retryDelay must not be negative"},
+		{RetryPolicy{Backoff: 0.5}, "retryBackoff must be at least 1"},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.policy, err, tt.want)
+		}
+	}
+
+	policy, err := RetryPolicyFromJSON([]byte(`{"spec":{"retries":2,"retryDelay":"500ms"}}`))
+	if err != nil {
+		t.Fatalf("RetryPolicyFromJSON: %v", err)
+	}
+	if policy.Retries != 2 || policy.Delay.Std() != 500*time.Millisecond || policy.Backoff != 2 {
+		t.Errorf("policy = %+v, want the default backoff", policy)
+	}
+}
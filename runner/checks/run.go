@@ -0,0 +1,45 @@
+package checks
+
+import "context"
+
+// RunOptions are the settings shared by every check type that control how a
+// check is run and graded, as opposed to what it checks
+type RunOptions struct {
+	Retry   *RetryPolicy
+	Latency *LatencyThresholds
+}
+
+// RunOptionsFromEnv reads the run options from environment variables, see
+// RetryPolicy.LoadEnv and LatencyThresholds.LoadEnv
+func RunOptionsFromEnv() (*RunOptions, error) {
+	opts := &RunOptions{Retry: NewRetryPolicy(), Latency: &LatencyThresholds{}}
+	if err := opts.Retry.LoadEnv(); err != nil {
+		return nil, err
+	}
+	if err := opts.Latency.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// RunOptionsFromJSON reads the run options from a spec or moogie.io/v1 document
+func RunOptionsFromJSON(data []byte) (*RunOptions, error) {
+	retry, err := RetryPolicyFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	latency, err := LatencyThresholdsFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return &RunOptions{Retry: retry, Latency: latency}, nil
+}
+
+// Run executes a check with the given options, grading each attempt by
+// latency and retrying failed attempts
+func Run(ctx context.Context, c Checker, cfg Config, opts *RunOptions) (*CheckResult, error) {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	return RunWithRetries(ctx, WithLatencyThresholds(c, opts.Latency), cfg, opts.Retry)
+}
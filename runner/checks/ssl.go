@@ -1,7 +1,11 @@
 package checks
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os"
@@ -9,108 +13,437 @@ import (
 	"time"
 )
 
-// RunSSLCheck performs an SSL/TLS certificate check
-// Configuration via environment variables:
+// SSLConfig configures an SSL check. JSON field names match the SslCheck spec.
+type SSLConfig struct {
+	Host          string      `json:"host"`
+	Port          int         `json:"port"`
+	Timeout       Duration    `json:"timeout"`
+	DaysWarning   int         `json:"daysBeforeExpiry"`
+	CheckChain    bool        `json:"checkChain"`
+	CheckHostname bool        `json:"checkHostname"`
+	CAFile        string      `json:"caFile"`   // PEM bundle to verify the chain against instead of the system roots
+	CABundle      string      `json:"caBundle"` // PEM certificates, as an alternative to CAFile
+	OCSP          *OCSPConfig `json:"ocsp"`     // Check revocation status
+	Policy        *TLSPolicy  `json:"policy"`   // Probe supported protocols and cipher suites
+	StartTLS      string      `json:"starttls"` // Upgrade a plaintext connection first: smtp, imap, pop3, ftp, ldap or postgres
+}
+
+// NewSSLConfig returns an SSLConfig with defaults applied
+func NewSSLConfig() *SSLConfig {
+	return &SSLConfig{
+		Timeout:       Duration(15 * time.Second),
+		DaysWarning:   30,
+		CheckChain:    true,
+		CheckHostname: true,
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
 //   - SSL_HOST: Target host (required)
-//   - SSL_PORT: Target port (default: 443)
+//   - SSL_PORT: Target port (default: 443, or the STARTTLS protocol's port)
 //   - SSL_TIMEOUT: Timeout in seconds (default: 15)
+//   - SSL_STARTTLS: Upgrade a plaintext connection first: smtp, imap, pop3, ftp, ldap or postgres (optional)
 //   - SSL_DAYS_WARNING: Days before expiry to warn (default: 30)
 //   - SSL_CHECK_CHAIN: Validate entire certificate chain (default: true)
-func RunSSLCheck() (*CheckResult, error) {
-	result := NewCheckResult()
+//   - SSL_CHECK_HOSTNAME: Check that the certificate matches the host (default: true)
+//   - SSL_CA_FILE: PEM bundle to verify the chain against (default: system roots)
+//   - SSL_OCSP: Check revocation status with OCSP (default: false)
+//   - SSL_OCSP_RESPONDER: OCSP responder URL (default: from the certificate)
+//   - SSL_OCSP_REQUIRE_STAPLING: Fail if the server does not staple an OCSP response (default: false)
+//   - SSL_PROBE: Probe supported protocol versions and cipher suites (default: false)
+//   - SSL_MIN_VERSION: Fail if the server accepts a TLS version below this, e.g. "1.2" (optional)
+//   - SSL_BANNED_CIPHERS: Comma-separated cipher suite names or fragments such as "CBC" that must not be accepted (optional)
+//   - SSL_REQUIRED_ALPN: Comma-separated ALPN protocols the server must negotiate, e.g. "h2" (optional)
+func (c *SSLConfig) LoadEnv() error {
+	envString("SSL_HOST", &c.Host)
+	if c.Host == "" {
+		return fmt.Errorf("SSL_HOST environment variable is required")
+	}
 
-	// Parse configuration
-	host := os.Getenv("SSL_HOST")
-	if host == "" {
-		return nil, fmt.Errorf("SSL_HOST environment variable is required")
+	if err := envInt("SSL_PORT", &c.Port); err != nil {
+		return err
 	}
+	if err := envDuration("SSL_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	if err := envInt("SSL_DAYS_WARNING", &c.DaysWarning); err != nil {
+		return err
+	}
+	if err := envBool("SSL_CHECK_CHAIN", &c.CheckChain); err != nil {
+		return err
+	}
+	if err := envBool("SSL_CHECK_HOSTNAME", &c.CheckHostname); err != nil {
+		return err
+	}
+	envString("SSL_CA_FILE", &c.CAFile)
+	envString("SSL_STARTTLS", &c.StartTLS)
 
-	port := 443
-	if portStr := os.Getenv("SSL_PORT"); portStr != "" {
-		if p, err := strconv.Atoi(portStr); err == nil {
-			port = p
-		}
+	ocsp := &OCSPConfig{}
+	enabled := false
+	if err := envBool("SSL_OCSP", &enabled); err != nil {
+		return err
+	}
+	envString("SSL_OCSP_RESPONDER", &ocsp.Responder)
+	if err := envBool("SSL_OCSP_REQUIRE_STAPLING", &ocsp.RequireStapling); err != nil {
+		return err
+	}
+	if enabled || ocsp.Responder != "" || ocsp.RequireStapling {
+		c.OCSP = ocsp
 	}
 
-	timeout := 15
-	if timeoutStr := os.Getenv("SSL_TIMEOUT"); timeoutStr != "" {
-		if t, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = t
-		}
+	policy := &TLSPolicy{}
+	enabled = false
+	if err := envBool("SSL_PROBE", &enabled); err != nil {
+		return err
+	}
+	envString("SSL_MIN_VERSION", &policy.MinVersion)
+	envList("SSL_BANNED_CIPHERS", &policy.BannedCiphers)
+	envList("SSL_REQUIRED_ALPN", &policy.RequiredALPN)
+	if enabled || policy.MinVersion != "" || len(policy.BannedCiphers) > 0 || len(policy.RequiredALPN) > 0 {
+		c.Policy = policy
+	}
+	return nil
+}
+
+// Validate reports whether the configuration is complete
+func (c *SSLConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if _, ok := startTLSPorts[c.StartTLS]; c.StartTLS != "" && !ok {
+		return fmt.Errorf("starttls must be one of smtp, imap, pop3, ftp, ldap or postgres")
+	}
+	if c.CAFile != "" && c.CABundle != "" {
+		return fmt.Errorf("caFile and caBundle cannot both be set")
 	}
+	if c.Policy != nil {
+		return c.Policy.validate()
+	}
+	return nil
+}
 
-	daysWarning := 30
-	if daysStr := os.Getenv("SSL_DAYS_WARNING"); daysStr != "" {
-		if d, err := strconv.Atoi(daysStr); err == nil {
-			daysWarning = d
+// port returns the configured port, or the default for the protocol
+func (c *SSLConfig) port() int {
+	if c.Port != 0 {
+		return c.Port
+	}
+	if port, ok := startTLSPorts[c.StartTLS]; ok {
+		return port
+	}
+	return 443
+}
+
+// roots returns the CA pool to verify against, or nil for the system roots
+func (c *SSLConfig) roots() (*x509.CertPool, error) {
+	pem := []byte(c.CABundle)
+	if c.CAFile != "" {
+		data, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
 		}
+		pem = data
+	}
+	if len(pem) == 0 {
+		return nil, nil
 	}
 
-	// Connect to server with TLS
-	addr := fmt.Sprintf("%s:%d", host, port)
-	dialer := &net.Dialer{
-		Timeout: time.Duration(timeout) * time.Second,
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle")
 	}
+	return pool, nil
+}
+
+// sslChecker runs SSL certificate checks
+type sslChecker struct{}
+
+func init() {
+	Register(sslChecker{})
+}
+
+func (sslChecker) Name() string {
+	return "ssl"
+}
+
+func (sslChecker) NewConfig() Config {
+	return NewSSLConfig()
+}
+
+func (sslChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*SSLConfig]("ssl", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunSSLCheck(ctx, c)
+}
+
+// CertificateInfo describes one certificate of the chain
+type CertificateInfo struct {
+	Role            string    `json:"role"` // leaf, intermediate or root
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	SerialNumber    string    `json:"serial_number"`
+	NotBefore       time.Time `json:"not_before"`
+	NotAfter        time.Time `json:"not_after"`
+	DaysUntilExpiry int       `json:"days_until_expiry"`
+}
 
+// RunSSLCheck performs an SSL/TLS certificate check
+func RunSSLCheck(ctx context.Context, cfg *SSLConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	roots, err := cfg.roots()
+	if err != nil {
+		return nil, err
+	}
+
+	// Connect to server with TLS. The chain and hostname are verified below so
+	// that failures can be reported in detail rather than as a handshake error.
 	start := time.Now()
-	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
-		ServerName: host,
-	})
+	conn, err := dialTLS(ctx, cfg, &tls.Config{})
 	elapsed := time.Since(start)
 
 	result.ResponseTimeMs = elapsed.Milliseconds()
 
 	if err != nil {
-		result.Status = "error"
+		result.Status = StatusError
 		result.ErrorMessage = fmt.Sprintf("TLS connection failed: %v", err)
 		return result, nil
 	}
-	defer conn.Close()
+	state := conn.ConnectionState()
+	conn.Close()
+
+	result.Metadata["tls_version"] = tls.VersionName(state.Version)
+	result.Metadata["cipher_suite"] = tls.CipherSuiteName(state.CipherSuite)
 
+	checkCertificates(ctx, cfg, roots, state, result)
+
+	if cfg.Policy != nil && result.Status == StatusSuccess {
+		if err := probeTLSPolicy(ctx, cfg, result); err != nil {
+			result.Status = StatusError
+			result.ErrorMessage = err.Error()
+		}
+	}
+	return result, nil
+}
+
+// dialTLS connects to the configured host, upgrades the connection with
+// STARTTLS if configured and performs a TLS handshake with the given settings.
+// Certificates are not verified during the handshake.
+func dialTLS(ctx context.Context, cfg *SSLConfig, config *tls.Config) (*tls.Conn, error) {
+	config.ServerName = cfg.Host
+	config.InsecureSkipVerify = true
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout.Std())
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.port()))
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.StartTLS != "" {
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		if err := startTLS(conn, cfg.StartTLS); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s STARTTLS failed: %w", cfg.StartTLS, err)
+		}
+		conn.SetDeadline(time.Time{})
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// checkCertificates validates the certificates presented in a TLS handshake:
+// expiry of the leaf and intermediates, the hostname, the chain of trust and
+// optionally the OCSP status. The result's status is set to the first failure.
+func checkCertificates(ctx context.Context, cfg *SSLConfig, roots *x509.CertPool, state tls.ConnectionState, result *CheckResult) {
 	// Get certificate information
-	certs := conn.ConnectionState().PeerCertificates
+	certs := state.PeerCertificates
 	if len(certs) == 0 {
-		result.Status = "error"
+		result.Status = StatusError
 		result.ErrorMessage = "No certificates found"
-		return result, nil
+		return
 	}
 
 	cert := certs[0] // Leaf certificate
 
 	// Calculate days until expiry
 	now := time.Now()
-	daysUntilExpiry := int(cert.NotAfter.Sub(now).Hours() / 24)
+	daysUntilExpiry := daysUntil(cert.NotAfter, now)
 
 	// Store certificate metadata
-	result.Metadata["host"] = host
-	result.Metadata["port"] = port
+	result.Metadata["host"] = cfg.Host
+	result.Metadata["port"] = cfg.port()
+	if cfg.StartTLS != "" {
+		result.Metadata["starttls"] = cfg.StartTLS
+	}
 	result.Metadata["issuer"] = cert.Issuer.String()
 	result.Metadata["subject"] = cert.Subject.String()
 	result.Metadata["not_before"] = cert.NotBefore
 	result.Metadata["not_after"] = cert.NotAfter
 	result.Metadata["days_until_expiry"] = daysUntilExpiry
 	result.Metadata["serial_number"] = cert.SerialNumber.String()
+	result.Metadata["fingerprint_sha256"] = certificateFingerprint(cert)
+	result.Metadata["sans"] = subjectAltNames(cert)
 
-	// Check for expiry
-	if now.After(cert.NotAfter) {
-		result.Status = "error"
-		result.ErrorMessage = fmt.Sprintf("Certificate expired on %s", cert.NotAfter.Format(time.RFC3339))
-		return result, nil
+	// Verify the chain first so that the reported chain is the one that was trusted
+	chain := certs
+	var chainErr error
+	if cfg.CheckChain {
+		intermediates := x509.NewCertPool()
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
+		}
+		chains, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   now,
+		})
+		if err != nil {
+			chainErr = err
+		} else {
+			chain = chains[0]
+		}
 	}
+	result.Metadata["chain"] = describeChain(chain, now)
 
-	if now.Before(cert.NotBefore) {
-		result.Status = "error"
-		result.ErrorMessage = fmt.Sprintf("Certificate not valid until %s", cert.NotBefore.Format(time.RFC3339))
-		return result, nil
+	// Check for expiry of the leaf and every intermediate, failing on the earliest
+	for _, c := range chain {
+		if now.After(c.NotAfter) {
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("%s expired on %s", certificateLabel(c, cert), c.NotAfter.Format(time.RFC3339))
+			return
+		}
+		if now.Before(c.NotBefore) {
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("%s not valid until %s", certificateLabel(c, cert), c.NotBefore.Format(time.RFC3339))
+			return
+		}
 	}
 
+	earliest := earliestExpiry(chain)
+	result.Metadata["earliest_expiry"] = earliest.NotAfter
+	result.Metadata["earliest_expiry_subject"] = earliest.Subject.String()
+
 	// Warn if expiring soon
-	if daysUntilExpiry <= daysWarning {
-		result.Status = "error"
-		result.ErrorMessage = fmt.Sprintf("Certificate expires in %d days (warning threshold: %d days)", daysUntilExpiry, daysWarning)
-		return result, nil
+	if days := daysUntil(earliest.NotAfter, now); days <= cfg.DaysWarning {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("%s expires in %d days (warning threshold: %d days)", certificateLabel(earliest, cert), days, cfg.DaysWarning)
+		return
 	}
 
-	result.Status = "success"
-	return result, nil
+	if cfg.CheckHostname {
+		if err := cert.VerifyHostname(cfg.Host); err != nil {
+			result.Metadata["hostname_match"] = false
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Certificate does not match host: %v", err)
+			return
+		}
+		result.Metadata["hostname_match"] = true
+	}
+
+	if cfg.CheckChain {
+		result.Metadata["chain_valid"] = chainErr == nil
+		if chainErr != nil {
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Certificate chain verification failed: %v", chainErr)
+			return
+		}
+	}
+
+	if cfg.OCSP != nil {
+		var issuer *x509.Certificate
+		if len(chain) > 1 {
+			issuer = chain[1]
+		}
+		if err := checkOCSP(ctx, cfg.OCSP, cert, issuer, state.OCSPResponse, cfg.Timeout.Std(), result); err != nil {
+			result.Status = StatusError
+			result.ErrorMessage = err.Error()
+			return
+		}
+	}
+
+	result.Status = StatusSuccess
+}
+
+// describeChain lists the certificates from the leaf up
+func describeChain(chain []*x509.Certificate, now time.Time) []CertificateInfo {
+	infos := make([]CertificateInfo, len(chain))
+	for i, c := range chain {
+		role := "intermediate"
+		if i == 0 {
+			role = "leaf"
+		} else if isSelfSigned(c) {
+			role = "root"
+		}
+		infos[i] = CertificateInfo{
+			Role:            role,
+			Subject:         c.Subject.String(),
+			Issuer:          c.Issuer.String(),
+			SerialNumber:    c.SerialNumber.String(),
+			NotBefore:       c.NotBefore,
+			NotAfter:        c.NotAfter,
+			DaysUntilExpiry: daysUntil(c.NotAfter, now),
+		}
+	}
+	return infos
+}
+
+// earliestExpiry returns the certificate that expires first, ignoring the
+// root, whose expiry is handled by the trust store
+func earliestExpiry(chain []*x509.Certificate) *x509.Certificate {
+	earliest := chain[0]
+	for _, c := range chain[1:] {
+		if isSelfSigned(c) {
+			continue
+		}
+		if c.NotAfter.Before(earliest.NotAfter) {
+			earliest = c
+		}
+	}
+	return earliest
+}
+
+// certificateLabel names a certificate in error messages
+func certificateLabel(c, leaf *x509.Certificate) string {
+	if c == leaf {
+		return "Certificate"
+	}
+	return fmt.Sprintf("Intermediate certificate %q", c.Subject.CommonName)
+}
+
+func isSelfSigned(c *x509.Certificate) bool {
+	return c.Subject.String() == c.Issuer.String() && c.CheckSignatureFrom(c) == nil
+}
+
+func subjectAltNames(c *x509.Certificate) []string {
+	sans := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+func daysUntil(t, now time.Time) int {
+	return int(t.Sub(now).Hours() / 24)
+}
+
+// certificateFingerprint returns the SHA-256 fingerprint of a certificate as hex
+func certificateFingerprint(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.Raw)
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,333 @@
+package checks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority for issuing test certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCA creates a root CA, or an intermediate if parent is set. modify
+// may adjust the template before it is signed.
+func newTestCA(t *testing.T, name string, parent *testCA, modify func(*x509.Certificate)) *testCA {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if modify != nil {
+		modify(template)
+	}
+	cert, key := signCertificate(t, template, parent)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue creates a leaf certificate for 127.0.0.1, valid for a year unless
+// modify changes it
+func (ca *testCA) issue(t *testing.T, modify func(*x509.Certificate)) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "moogie test server"},
+		NotBefore:   time.Now().Add(-time.Hour),
+		NotAfter:    time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if modify != nil {
+		modify(template)
+	}
+	return signCertificate(t, template, ca)
+}
+
+// pem returns the CA certificate in PEM form
+func (ca *testCA) pem() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))
+}
+
+// signCertificate signs the template with a new key, self-signed if parent is nil
+func signCertificate(t *testing.T, template *x509.Certificate, parent *testCA) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = serial
+
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// serverCertificate bundles a leaf and the intermediates the server presents
+func serverCertificate(leaf *x509.Certificate, key *ecdsa.PrivateKey, intermediates ...*x509.Certificate) tls.Certificate {
+	certificate := tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: key, Leaf: leaf}
+	for _, c := range intermediates {
+		certificate.Certificate = append(certificate.Certificate, c.Raw)
+	}
+	return certificate
+}
+
+// startTLSTestServer serves TLS with the certificate until the test ends and
+// returns the port. configure may restrict the server's TLS settings.
+func startTLSTestServer(t *testing.T, certificate tls.Certificate, configure func(*tls.Config)) int {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	// Failed handshakes are expected while probing
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	if configure != nil {
+		configure(server.TLS)
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().(*net.TCPAddr).Port
+}
+
+func newTestSSLConfig(port int, root *testCA) *SSLConfig {
+	cfg := NewSSLConfig()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = port
+	cfg.Timeout = Duration(5 * time.Second)
+	cfg.CABundle = root.pem()
+	return cfg
+}
+
+func runSSL(t *testing.T, cfg *SSLConfig) *CheckResult {
+	t.Helper()
+	result, err := RunSSLCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunSSLCheck: %v", err)
+	}
+	return result
+}
+
+func TestSSLValidChain(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	intermediate := newTestCA(t, "Test Intermediate", root, nil)
+	leaf, key := intermediate.issue(t, func(c *x509.Certificate) { c.DNSNames = []string{"www.example.test"} })
+	port := startTLSTestServer(t, serverCertificate(leaf, key, intermediate.cert), nil)
+
+	result := runSSL(t, newTestSSLConfig(port, root))
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["chain_valid"] != true || result.Metadata["hostname_match"] != true {
+		t.Errorf("chain_valid = %v, hostname_match = %v", result.Metadata["chain_valid"], result.Metadata["hostname_match"])
+	}
+	if sans := result.Metadata["sans"].([]string); len(sans) != 2 || sans[0] != "www.example.test" || sans[1] != "127.0.0.1" {
+		t.Errorf("sans = %v", sans)
+	}
+	if result.Metadata["fingerprint_sha256"] != certificateFingerprint(leaf) {
+		t.Errorf("fingerprint_sha256 = %v", result.Metadata["fingerprint_sha256"])
+	}
+
+	chain := result.Metadata["chain"].([]CertificateInfo)
+	wantRoles := []string{"leaf", "intermediate", "root"}
+	wantSubjects := []string{"CN=moogie test server", "CN=Test Intermediate", "CN=Test Root"}
+	if len(chain) != len(wantRoles) {
+		t.Fatalf("chain = %+v", chain)
+	}
+	for i, c := range chain {
+		if c.Role != wantRoles[i] || c.Subject != wantSubjects[i] {
+			t.Errorf("chain[%d] = %s %s, want %s %s", i, c.Role, c.Subject, wantRoles[i], wantSubjects[i])
+		}
+	}
+	// The root is left to the trust store, so the leaf expires first
+	if result.Metadata["earliest_expiry_subject"] != "CN=moogie test server" {
+		t.Errorf("earliest_expiry_subject = %v", result.Metadata["earliest_expiry_subject"])
+	}
+}
+
+func TestSSLCertificateFailures(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	intermediate := newTestCA(t, "Test Intermediate", root, nil)
+	otherRoot := newTestCA(t, "Other Root", nil, nil)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		server func(t *testing.T) tls.Certificate
+		want   string
+	}{
+		{
+			name: "untrusted root",
+			server: func(t *testing.T) tls.Certificate {
+				leaf, key := otherRoot.issue(t, nil)
+				return serverCertificate(leaf, key)
+			},
+			want: "Certificate chain verification failed: x509: certificate signed by unknown authority",
+		},
+		{
+			name: "intermediate not sent",
+			server: func(t *testing.T) tls.Certificate {
+				leaf, key := intermediate.issue(t, nil)
+				return serverCertificate(leaf, key)
+			},
+			want: "Certificate chain verification failed",
+		},
+		{
+			name: "hostname mismatch",
+			server: func(t *testing.T) tls.Certificate {
+				leaf, key := root.issue(t, func(c *x509.Certificate) {
+					c.IPAddresses = nil
+					c.DNSNames = []string{"www.example.test"}
+				})
+				return serverCertificate(leaf, key)
+			},
+			want: "Certificate does not match host: x509: cannot validate certificate for 127.0.0.1",
+		},
+		{
+			name: "expired",
+			server: func(t *testing.T) tls.Certificate {
+				leaf, key := root.issue(t, func(c *x509.Certificate) {
+					c.NotBefore = time.Now().Add(-30 * day)
+					c.NotAfter = time.Now().Add(-day)
+				})
+				return serverCertificate(leaf, key)
+			},
+			want: "Certificate expired on",
+		},
+		{
+			name: "not yet valid",
+			server: func(t *testing.T) tls.Certificate {
+				leaf, key := root.issue(t, func(c *x509.Certificate) { c.NotBefore = time.Now().Add(day) })
+				return serverCertificate(leaf, key)
+			},
+			want: "Certificate not valid until",
+		},
+		{
+			name: "expiring soon",
+			server: func(t *testing.T) tls.Certificate {
+				leaf, key := root.issue(t, func(c *x509.Certificate) { c.NotAfter = time.Now().Add(10*day + time.Hour) })
+				return serverCertificate(leaf, key)
+			},
+			want: "Certificate expires in 10 days (warning threshold: 30 days)",
+		},
+		{
+			name: "expired intermediate",
+			server: func(t *testing.T) tls.Certificate {
+				expired := newTestCA(t, "Expired Intermediate", root, func(c *x509.Certificate) { c.NotAfter = time.Now().Add(-day) })
+				leaf, key := expired.issue(t, nil)
+				return serverCertificate(leaf, key, expired.cert)
+			},
+			want: `Intermediate certificate "Expired Intermediate" expired on`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runSSL(t, newTestSSLConfig(startTLSTestServer(t, tt.server(t), nil), root))
+			if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, tt.want) {
+				t.Errorf("status = %q (%s), want an error starting with %q", result.Status, result.ErrorMessage, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSLIntermediateExpiresFirst(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	intermediate := newTestCA(t, "Test Intermediate", root, func(c *x509.Certificate) { c.NotAfter = time.Now().Add(5*24*time.Hour + time.Hour) })
+	leaf, key := intermediate.issue(t, nil)
+	port := startTLSTestServer(t, serverCertificate(leaf, key, intermediate.cert), nil)
+
+	result := runSSL(t, newTestSSLConfig(port, root))
+	want := `Intermediate certificate "Test Intermediate" expires in 5 days (warning threshold: 30 days)`
+	if result.Status != StatusError || result.ErrorMessage != want {
+		t.Errorf("status = %q (%s), want %q", result.Status, result.ErrorMessage, want)
+	}
+	if result.Metadata["earliest_expiry_subject"] != "CN=Test Intermediate" {
+		t.Errorf("earliest_expiry_subject = %v", result.Metadata["earliest_expiry_subject"])
+	}
+}
+
+func TestSSLChecksDisabled(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	otherRoot := newTestCA(t, "Other Root", nil, nil)
+	leaf, key := otherRoot.issue(t, func(c *x509.Certificate) {
+		c.IPAddresses = nil
+		c.DNSNames = []string{"www.example.test"}
+	})
+	port := startTLSTestServer(t, serverCertificate(leaf, key), nil)
+
+	cfg := newTestSSLConfig(port, root)
+	cfg.CheckChain = false
+	cfg.CheckHostname = false
+	result := runSSL(t, cfg)
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s), want success with chain and hostname checks disabled", result.Status, result.ErrorMessage)
+	}
+	if _, ok := result.Metadata["chain_valid"]; ok {
+		t.Errorf("chain_valid = %v with the chain check disabled", result.Metadata["chain_valid"])
+	}
+}
+
+func TestSSLConnectionFailed(t *testing.T) {
+	cfg := NewSSLConfig()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = closedPort(t)
+	result := runSSL(t, cfg)
+	if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, "TLS connection failed") {
+		t.Errorf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+}
+
+func TestSSLValidate(t *testing.T) {
+	tests := []struct {
+		cfg  SSLConfig
+		want string
+	}{
+		{SSLConfig{}, "host is required"},
+		{SSLConfig{Host: "example.test", StartTLS: "http"}, "starttls must be one of"},
+		{SSLConfig{Host: "example.test", CAFile: "ca.pem", CABundle: "-----BEGIN CERTIFICATE-----"}, "caFile and caBundle cannot both be set"},
+		{SSLConfig{Host: "example.test", Policy: &TLSPolicy{MinVersion: "1.4"}}, "minVersion must be one of"},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+
+	cfg := newTestSSLConfig(443, newTestCA(t, "Test Root", nil, nil))
+	cfg.CABundle = "not a certificate"
+	if _, err := RunSSLCheck(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "no certificates found in CA bundle") {
+		t.Errorf("invalid CA bundle: error = %v", err)
+	}
+}
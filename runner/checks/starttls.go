@@ -0,0 +1,248 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// startTLSPorts are the default ports of the protocols that support STARTTLS
+var startTLSPorts = map[string]int{
+	"smtp":     25,
+	"imap":     143,
+	"pop3":     110,
+	"ftp":      21,
+	"ldap":     389,
+	"postgres": 5432,
+}
+
+// startTLS asks the server to upgrade a plaintext connection to TLS using the
+// protocol's own command. The TLS handshake follows on the same connection.
+func startTLS(conn net.Conn, protocol string) error {
+	r := bufio.NewReader(conn)
+
+	switch protocol {
+	case "smtp":
+		if _, err := readReply(r, "220"); err != nil {
+			return fmt.Errorf("unexpected greeting: %w", err)
+		}
+		if err := command(conn, r, "EHLO moogie", "250"); err != nil {
+			return err
+		}
+		return command(conn, r, "STARTTLS", "220")
+	case "imap":
+		if _, err := readReply(r, "* OK"); err != nil {
+			return fmt.Errorf("unexpected greeting: %w", err)
+		}
+		if _, err := fmt.Fprint(conn, "a001 STARTTLS\r\n"); err != nil {
+			return err
+		}
+		// Skip untagged responses until the tagged one
+		for {
+			line, err := readReplyLine(r)
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "a001 ") {
+				if !strings.HasPrefix(line, "a001 OK") {
+					return fmt.Errorf("STARTTLS rejected: %s", strings.TrimSpace(line))
+				}
+				return nil
+			}
+		}
+	case "pop3":
+		if _, err := readReply(r, "+OK"); err != nil {
+			return fmt.Errorf("unexpected greeting: %w", err)
+		}
+		return command(conn, r, "STLS", "+OK")
+	case "ftp":
+		if _, err := readReply(r, "220"); err != nil {
+			return fmt.Errorf("unexpected greeting: %w", err)
+		}
+		return command(conn, r, "AUTH TLS", "234")
+	case "ldap":
+		return ldapStartTLS(conn, r)
+	case "postgres":
+		return postgresSSLRequest(conn, r)
+	}
+	return fmt.Errorf("unsupported STARTTLS protocol: %s", protocol)
+}
+
+// command sends a line and checks the reply code
+func command(conn net.Conn, r *bufio.Reader, line, code string) error {
+	if _, err := fmt.Fprintf(conn, "%s\r\n", line); err != nil {
+		return err
+	}
+	if _, err := readReply(r, code); err != nil {
+		return fmt.Errorf("%s rejected: %w", strings.Fields(line)[0], err)
+	}
+	return nil
+}
+
+// maxReplySize caps a reply read from the server, including every line of a
+// multi-line reply. Greetings and capability lists are far smaller.
+const maxReplySize = 64 * 1024
+
+// readReplyLine reads one line, failing once it grows past maxReplySize
+// instead of buffering whatever the server sends
+func readReplyLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxReplySize {
+			return "", fmt.Errorf("reply exceeds limit of %d bytes", maxReplySize)
+		}
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(line), nil
+	}
+}
+
+// readReply reads a possibly multi-line reply ("250-..." continued by "250 ...")
+// and checks that it starts with code
+func readReply(r *bufio.Reader, code string) (string, error) {
+	var reply strings.Builder
+	for {
+		line, err := readReplyLine(r)
+		if err != nil {
+			return "", err
+		}
+		if reply.Len()+len(line) > maxReplySize {
+			return "", fmt.Errorf("reply exceeds limit of %d bytes", maxReplySize)
+		}
+		reply.WriteString(line)
+		// SMTP and FTP continue multi-line replies with a dash after the code
+		if len(line) > 3 && line[3] == '-' && isDigits(line[:3]) {
+			continue
+		}
+		break
+	}
+
+	text := strings.TrimSpace(reply.String())
+	if !strings.HasPrefix(text, code) {
+		return text, fmt.Errorf("%s", firstLine(text))
+	}
+	return text, nil
+}
+
+// ldapStartTLSRequest is an ExtendedRequest for the StartTLS OID 1.3.6.1.4.1.1466.20037 with message ID 1
+var ldapStartTLSRequest = append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, "1.3.6.1.4.1.1466.20037"...)
+
+// ldapStartTLS sends the StartTLS extended operation and checks the result code
+func ldapStartTLS(conn net.Conn, r *bufio.Reader) error {
+	if _, err := conn.Write(ldapStartTLSRequest); err != nil {
+		return err
+	}
+
+	// LDAPMessage ::= SEQUENCE { messageID INTEGER, ExtendedResponse [APPLICATION 24] { resultCode ENUMERATED, ... } }
+	message, err := readBER(r, 0x30)
+	if err != nil {
+		return fmt.Errorf("invalid StartTLS response: %w", err)
+	}
+	body := bytes.NewReader(message)
+	if _, err := readBER(body, 0x02); err != nil {
+		return fmt.Errorf("invalid StartTLS response: %w", err)
+	}
+	response, err := readBER(body, 0x78)
+	if err != nil {
+		return fmt.Errorf("invalid StartTLS response: %w", err)
+	}
+	resultCode, err := readBER(bytes.NewReader(response), 0x0a)
+	if err != nil || len(resultCode) != 1 {
+		return fmt.Errorf("invalid StartTLS response")
+	}
+	if resultCode[0] != 0 {
+		return fmt.Errorf("StartTLS rejected with LDAP result code %d", resultCode[0])
+	}
+	return nil
+}
+
+// postgresSSLRequest sends an SSLRequest message; the server answers with a single byte
+func postgresSSLRequest(conn net.Conn, r *bufio.Reader) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], 80877103)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	answer, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if answer != 'S' {
+		return fmt.Errorf("server does not accept SSL connections")
+	}
+	return nil
+}
+
+// maxBERSize caps the length of a BER element read from the server. LDAP
+// StartTLS responses are a few dozen bytes.
+const maxBERSize = 64 * 1024
+
+// readBER reads one BER element with the expected tag from r and returns its contents
+func readBER(r io.ByteReader, tag byte) ([]byte, error) {
+	got, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if got != tag {
+		return nil, fmt.Errorf("unexpected tag 0x%02x", got)
+	}
+
+	length, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	size := int(length)
+	if length&0x80 != 0 {
+		octets := int(length & 0x7f)
+		if octets == 0 || octets > 4 {
+			return nil, fmt.Errorf("unsupported length encoding")
+		}
+		size = 0
+		for i := 0; i < octets; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			size = size<<8 | int(b)
+		}
+	}
+	if size > maxBERSize {
+		return nil, fmt.Errorf("BER element of %d bytes exceeds limit of %d", size, maxBERSize)
+	}
+
+	content := make([]byte, size)
+	for i := range content {
+		if content[i], err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadBER(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     byte
+		data    []byte
+		want    []byte
+		wantErr string
+	}{
+		{"short length", 0x02, []byte{0x02, 0x01, 0x05}, []byte{0x05}, ""},
+		{"long length", 0x04, append([]byte{0x04, 0x81, 0x03}, "abc"...), []byte("abc"), ""},
+		{"wrong tag", 0x02, []byte{0x04, 0x01, 0x05}, nil, "unexpected tag"},
+		{"indefinite length", 0x02, []byte{0x02, 0x80}, nil, "unsupported length encoding"},
+		{"too many length octets", 0x02, []byte{0x02, 0x85, 1, 1, 1, 1, 1}, nil, "unsupported length encoding"},
+		{"over the limit", 0x02, []byte{0x02, 0x84, 0x7f, 0xff, 0xff, 0xff}, nil, "exceeds limit"},
+		{"truncated", 0x02, []byte{0x02, 0x03, 0x01}, nil, "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readBER(bytes.NewReader(tt.data), tt.tag)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, tt.want) {
+				t.Errorf("readBER = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBERLimitBeforeAllocating(t *testing.T) {
+	// A server announcing a huge element must be rejected without reading it
+	data := []byte{0x30, 0x83, 0x01, 0x00, 0x01}
+	r := bufio.NewReader(bytes.NewReader(data))
+	if _, err := readBER(r, 0x30); err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("error = %v, want the size limit", err)
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		code    string
+		want    string
+		wantErr string
+	}{
+		{"single line", "220 mail.example.test ESMTP\r\n", "220", "220 mail.example.test ESMTP", ""},
+		{"multi-line", "250-mail.example.test\r\n250-PIPELINING\r\n250 STARTTLS\r\n", "250", "250-mail.example.test\r\n250-PIPELINING\r\n250 STARTTLS", ""},
+		{"wrong code", "554 no service\r\n", "220", "", "554 no service"},
+		{"overlong line", "220 " + strings.Repeat("x", maxReplySize) + "\r\n", "220", "", "exceeds limit"},
+		{"endless multi-line", strings.Repeat("250-PIPELINING\r\n", maxReplySize/16+1) + "250 OK\r\n", "250", "", "exceeds limit"},
+		{"unterminated", "220 mail.example.test", "220", "", "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.data)), tt.code)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("readReply = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
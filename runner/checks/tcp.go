@@ -1,60 +1,120 @@
 package checks
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
-	"os"
 	"strconv"
 	"time"
 )
 
-// RunTCPCheck performs a TCP connectivity check
-// Configuration via environment variables:
+// TCPConfig configures a TCP check. JSON field names match the TcpCheck spec.
+type TCPConfig struct {
+	Host    string   `json:"host"`
+	Port    int      `json:"port"`
+	Timeout Duration `json:"timeout"`
+
+	// Optional exchange after connecting, e.g. sending "PING\r\n" and expecting "+PONG"
+	Exchange
+}
+
+// tcpMaxRead is the most the check reads from the connection
+const tcpMaxRead = 64 * 1024
+
+// NewTCPConfig returns a TCPConfig with defaults applied
+func NewTCPConfig() *TCPConfig {
+	return &TCPConfig{
+		Timeout:  Duration(10 * time.Second),
+		Exchange: newExchange(),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
 //   - TCP_HOST: Target host (required)
 //   - TCP_PORT: Target port (required)
 //   - TCP_TIMEOUT: Timeout in seconds (default: 10)
-func RunTCPCheck() (*CheckResult, error) {
-	result := NewCheckResult()
+//   - TCP_SEND: Payload to send after connecting; escapes such as \r\n and \x00 are interpreted
+//   - TCP_SEND_HEX: Hex-encoded payload to send, as an alternative to TCP_SEND
+//   - TCP_EXPECT: Expected response
+//   - TCP_MATCH: How TCP_EXPECT is compared: contains, regex or hex (default: contains)
+//   - TCP_READ_TIMEOUT: How long to wait for the response (default: 5s)
+func (c *TCPConfig) LoadEnv() error {
+	envString("TCP_HOST", &c.Host)
+	if c.Host == "" {
+		return fmt.Errorf("TCP_HOST environment variable is required")
+	}
+
+	if err := envInt("TCP_PORT", &c.Port); err != nil {
+		return err
+	}
+	if c.Port == 0 {
+		return fmt.Errorf("TCP_PORT environment variable is required")
+	}
 
-	// Parse configuration
-	host := os.Getenv("TCP_HOST")
-	if host == "" {
-		return nil, fmt.Errorf("TCP_HOST environment variable is required")
+	if err := envDuration("TCP_TIMEOUT", &c.Timeout); err != nil {
+		return err
 	}
+	return c.Exchange.loadEnv("TCP_")
+}
 
-	portStr := os.Getenv("TCP_PORT")
-	if portStr == "" {
-		return nil, fmt.Errorf("TCP_PORT environment variable is required")
+// Validate reports whether the configuration is complete
+func (c *TCPConfig) Validate() error {
+	if c.Host == "" || c.Port == 0 {
+		return fmt.Errorf("host and port are required")
 	}
+	return c.Exchange.validate()
+}
+
+// tcpChecker runs TCP connectivity checks
+type tcpChecker struct{}
+
+func init() {
+	Register(tcpChecker{})
+}
+
+func (tcpChecker) Name() string {
+	return "tcp"
+}
+
+func (tcpChecker) NewConfig() Config {
+	return NewTCPConfig()
+}
 
-	port, err := strconv.Atoi(portStr)
+func (tcpChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*TCPConfig]("tcp", cfg)
 	if err != nil {
-		return nil, fmt.Errorf("invalid TCP_PORT: %w", err)
+		return nil, err
 	}
+	return RunTCPCheck(ctx, c)
+}
 
-	timeout := 10
-	if timeoutStr := os.Getenv("TCP_TIMEOUT"); timeoutStr != "" {
-		if t, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = t
-		}
+// RunTCPCheck performs a TCP connectivity check, optionally sending a payload
+// and matching the response
+func RunTCPCheck(ctx context.Context, cfg *TCPConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Attempt TCP connection with timing
-	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
 	dialer := net.Dialer{
-		Timeout: time.Duration(timeout) * time.Second,
+		Timeout: cfg.Timeout.Std(),
 	}
 
 	start := time.Now()
-	conn, err := dialer.Dial("tcp", addr)
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
 	elapsed := time.Since(start)
 
 	result.ResponseTimeMs = elapsed.Milliseconds()
-	result.Metadata["host"] = host
-	result.Metadata["port"] = port
+	result.Metadata["host"] = cfg.Host
+	result.Metadata["port"] = cfg.Port
 
 	if err != nil {
-		result.Status = "error"
+		result.Status = StatusError
 		result.ErrorMessage = fmt.Sprintf("TCP connection failed: %v", err)
 		return result, nil
 	}
@@ -64,6 +124,74 @@ func RunTCPCheck() (*CheckResult, error) {
 	result.Metadata["local_addr"] = conn.LocalAddr().String()
 	result.Metadata["remote_addr"] = conn.RemoteAddr().String()
 
-	result.Status = "success"
+	if !cfg.Exchange.active() {
+		result.Status = StatusSuccess
+		return result, nil
+	}
+
+	result.Metadata["connect_time_ms"] = elapsed.Milliseconds()
+	err = exchangeTCP(ctx, conn, &cfg.Exchange, result)
+	result.ResponseTimeMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	result.Status = StatusSuccess
 	return result, nil
 }
+
+// exchangeTCP writes the payload and reads until the response matches expect,
+// the server closes the connection or the read timeout passes. Without an
+// expectation, the first data received is recorded and not waited for.
+func exchangeTCP(ctx context.Context, conn net.Conn, cfg *Exchange, result *CheckResult) error {
+	conn.SetDeadline(cfg.readDeadline(ctx.Deadline()))
+
+	// Unblock reads if the check is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if payload := cfg.payload(); len(payload) > 0 {
+		n, err := conn.Write(payload)
+		result.Metadata["sent_bytes"] = n
+		if err != nil {
+			return fmt.Errorf("Failed to send payload: %v", err)
+		}
+	}
+
+	var response []byte
+	buf := make([]byte, 4096)
+	matched := false
+	var readErr error
+	for len(response) < tcpMaxRead {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+		if cfg.Expect != "" && cfg.matches(response) {
+			matched = true
+			break
+		}
+		if cfg.Expect == "" && len(response) > 0 {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	recordResponse(result, response)
+
+	if cfg.Expect == "" || matched {
+		return nil
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.As(readErr, &netErr) && netErr.Timeout():
+		return fmt.Errorf("Expected response %q (%s) not received within %s", cfg.Expect, cfg.Match, cfg.ReadTimeout.Std())
+	case readErr != nil && !errors.Is(readErr, io.EOF):
+		return fmt.Errorf("Failed to read response: %v", readErr)
+	default:
+		return fmt.Errorf("Response does not match %q (%s). Got: %q", cfg.Expect, cfg.Match, truncateBytes(response, 100))
+	}
+}
@@ -0,0 +1,207 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fake TCP services for the send/expect tests
+var (
+	// pingServer answers PING with +PONG, like Redis
+	pingServer = func(conn net.Conn, r *bufio.Reader) {
+		for {
+			line, ok := readLine(r)
+			if !ok {
+				return
+			}
+			if line == "PING" {
+				conn.Write([]byte("+PONG\r\n"))
+			}
+		}
+	}
+	// bannerServer greets clients first, like SSH or SMTP
+	bannerServer = func(conn net.Conn, r *bufio.Reader) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+		io.Copy(io.Discard, r)
+	}
+	// splitServer sends its greeting in two writes
+	splitServer = func(conn net.Conn, r *bufio.Reader) {
+		conn.Write([]byte("220 mail.example.test E"))
+		time.Sleep(20 * time.Millisecond)
+		conn.Write([]byte("SMTP ready\r\n"))
+		io.Copy(io.Discard, r)
+	}
+	// binaryServer answers the bytes 01 02 with ca fe 00
+	binaryServer = func(conn net.Conn, r *bufio.Reader) {
+		request := make([]byte, 2)
+		if _, err := io.ReadFull(r, request); err == nil && request[0] == 0x01 && request[1] == 0x02 {
+			conn.Write([]byte{0xca, 0xfe, 0x00})
+		}
+		io.Copy(io.Discard, r)
+	}
+	// silentServer never answers
+	silentServer = func(conn net.Conn, r *bufio.Reader) {
+		io.Copy(io.Discard, r)
+	}
+	// closingServer says goodbye and hangs up
+	closingServer = func(conn net.Conn, r *bufio.Reader) {
+		conn.Write([]byte("BYE\r\n"))
+	}
+	// chattyServer sends more than is recorded and hangs up
+	chattyServer = func(conn net.Conn, r *bufio.Reader) {
+		conn.Write([]byte(strings.Repeat("x", 1000)))
+	}
+)
+
+func runTCP(t *testing.T, port int, exchange Exchange) *CheckResult {
+	t.Helper()
+	cfg := NewTCPConfig()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = port
+	cfg.Timeout = Duration(5 * time.Second)
+	if exchange.Match == "" {
+		exchange.Match = MatchContains
+	}
+	if exchange.ReadTimeout == 0 {
+		exchange.ReadTimeout = Duration(2 * time.Second)
+	}
+	cfg.Exchange = exchange
+
+	result, err := RunTCPCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunTCPCheck: %v", err)
+	}
+	return result
+}
+
+func TestTCPExchange(t *testing.T) {
+	tests := []struct {
+		name         string
+		server       func(net.Conn, *bufio.Reader)
+		exchange     Exchange
+		wantErr      string
+		wantReceived string
+	}{
+		{name: "connect only", server: silentServer},
+		{name: "banner", server: bannerServer, exchange: Exchange{Expect: "SSH-2.0"}, wantReceived: "SSH-2.0-OpenSSH_9.6\r\n"},
+		{name: "banner regex", server: bannerServer, exchange: Exchange{Expect: `^SSH-2\.0-OpenSSH_\d+\.\d+`, Match: MatchRegex}},
+		{name: "banner without expect", server: bannerServer, exchange: Exchange{ReadTimeout: Duration(time.Second), Send: "\r\n"}, wantReceived: "SSH-2.0-OpenSSH_9.6\r\n"},
+		{name: "send and expect", server: pingServer, exchange: Exchange{Send: "PING\r\n", Expect: "+PONG"}, wantReceived: "+PONG\r\n"},
+		{name: "response split across reads", server: splitServer, exchange: Exchange{Expect: "ESMTP ready"}, wantReceived: "220 mail.example.test ESMTP ready\r\n"},
+		{name: "hex", server: binaryServer, exchange: Exchange{SendHex: "0102", Expect: "cafe", Match: MatchHex}},
+		{
+			name: "no answer", server: silentServer,
+			exchange: Exchange{Send: "PING\r\n", Expect: "+PONG", ReadTimeout: Duration(100 * time.Millisecond)},
+			wantErr:  `Expected response "+PONG" (contains) not received within 100ms`,
+		},
+		{
+			name: "different answer", server: closingServer,
+			exchange: Exchange{Send: "PING\r\n", Expect: "+PONG"},
+			wantErr:  `Response does not match "+PONG" (contains). Got: "BYE\r\n"`, wantReceived: "BYE\r\n",
+		},
+		{
+			name: "regex mismatch", server: bannerServer,
+			exchange: Exchange{Expect: `^220 `, Match: MatchRegex, ReadTimeout: Duration(100 * time.Millisecond)},
+			wantErr:  `Expected response "^220 " (regex) not received within 100ms`, wantReceived: "SSH-2.0-OpenSSH_9.6\r\n",
+		},
+		{
+			name: "send without expect", server: silentServer,
+			exchange: Exchange{Send: "PING\r\n", ReadTimeout: Duration(100 * time.Millisecond)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runTCP(t, serveFake(t, tt.server), tt.exchange)
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+			} else if result.Status != StatusError || result.ErrorMessage != tt.wantErr {
+				t.Fatalf("status = %q (%s), want an error %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if tt.wantReceived != "" && result.Metadata["received"] != tt.wantReceived {
+				t.Errorf("received = %q, want %q", result.Metadata["received"], tt.wantReceived)
+			}
+			if sent := len(tt.exchange.payload()); sent > 0 && result.Metadata["sent_bytes"] != sent {
+				t.Errorf("sent_bytes = %v, want %d", result.Metadata["sent_bytes"], sent)
+			}
+		})
+	}
+}
+
+func TestTCPExchangeRecordsResponse(t *testing.T) {
+	result := runTCP(t, serveFake(t, binaryServer), Exchange{SendHex: "0102", Expect: "cafe00", Match: MatchHex})
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if result.Metadata["received_hex"] != "cafe00" || result.Metadata["received_bytes"] != 3 {
+		t.Errorf("received_hex = %v, received_bytes = %v", result.Metadata["received_hex"], result.Metadata["received_bytes"])
+	}
+	if result.Metadata["connect_time_ms"] == nil {
+		t.Error("connect_time_ms not recorded")
+	}
+
+	result = runTCP(t, serveFake(t, chattyServer), Exchange{Expect: "done"})
+	if result.Metadata["received_bytes"] != 1000 || result.Metadata["received_truncated"] != true {
+		t.Errorf("received_bytes = %v, received_truncated = %v", result.Metadata["received_bytes"], result.Metadata["received_truncated"])
+	}
+	if received := result.Metadata["received"].(string); len(received) != maxRecordedResponse {
+		t.Errorf("recorded %d bytes, want %d", len(received), maxRecordedResponse)
+	}
+}
+
+func TestTCPConnectionFailed(t *testing.T) {
+	result := runTCP(t, closedPort(t), Exchange{})
+	if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, "TCP connection failed") {
+		t.Errorf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+}
+
+func TestExchangeValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange Exchange
+		want     string
+	}{
+		{"send and sendHex", Exchange{Send: "PING", SendHex: "00", Match: MatchContains}, "send and sendHex cannot both be set"},
+		{"bad sendHex", Exchange{SendHex: "zz", Match: MatchContains}, "invalid sendHex"},
+		{"bad pattern", Exchange{Expect: "(", Match: MatchRegex}, "invalid expect pattern"},
+		{"bad hex expect", Exchange{Expect: "xyz", Match: MatchHex}, "invalid hex expect"},
+		{"unknown match", Exchange{Expect: "OK", Match: "exact"}, "match must be one of contains, regex or hex"},
+		{"no read timeout", Exchange{Expect: "OK", Match: MatchContains}, "read timeout must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.exchange.validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeLoadEnv(t *testing.T) {
+	t.Setenv("TCP_HOST", "redis.example.test")
+	t.Setenv("TCP_PORT", "6379")
+	t.Setenv("TCP_SEND", `AUTH "secret"\r\nPING\r\n`)
+	t.Setenv("TCP_EXPECT", "+PONG")
+	t.Setenv("TCP_READ_TIMEOUT", "2s")
+
+	cfg := NewTCPConfig()
+	if err := cfg.LoadEnv(); err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if cfg.Send != "AUTH \"secret\"\r\nPING\r\n" || cfg.Expect != "+PONG" || cfg.Match != MatchContains || cfg.ReadTimeout.Std() != 2*time.Second {
+		t.Errorf("exchange = %+v", cfg.Exchange)
+	}
+
+	t.Setenv("TCP_SEND", `\q`)
+	if err := NewTCPConfig().LoadEnv(); err == nil || !strings.Contains(err.Error(), "invalid TCP_SEND") {
+		t.Errorf("invalid escape: error = %v", err)
+	}
+}
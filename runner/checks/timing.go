@@ -0,0 +1,109 @@
+package checks
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// httpTimings collects the phase timings of an HTTP request using httptrace.
+// Phases that happen more than once (e.g. when following redirects) are summed;
+// phases skipped because a connection was reused are zero.
+type httpTimings struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+	done         time.Time
+
+	dnsLookup    time.Duration
+	tcpConnect   time.Duration
+	tlsHandshake time.Duration
+}
+
+func newHTTPTimings() *httpTimings {
+	return &httpTimings{start: time.Now()}
+}
+
+// trace returns the hooks that record the timings
+func (t *httpTimings) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.dnsLookup += time.Since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			t.connectStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.tcpConnect += time.Since(t.connectStart)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.tlsHandshake += time.Since(t.tlsStart)
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.firstByte = time.Now()
+			t.mu.Unlock()
+		},
+	}
+}
+
+// finish marks the end of the body transfer
+func (t *httpTimings) finish() {
+	t.mu.Lock()
+	t.done = time.Now()
+	t.mu.Unlock()
+}
+
+// metadata returns the timings in milliseconds:
+//   - dns_lookup_ms, tcp_connect_ms, tls_handshake_ms: connection setup phases
+//   - ttfb_ms: time from the start of the request to the first response byte
+//   - content_transfer_ms: time from the first response byte to the end of the body
+//   - total_ms: time from the start of the request to the end of the body
+func (t *httpTimings) metadata() map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := map[string]float64{
+		"dns_lookup_ms":    milliseconds(t.dnsLookup),
+		"tcp_connect_ms":   milliseconds(t.tcpConnect),
+		"tls_handshake_ms": milliseconds(t.tlsHandshake),
+	}
+	if !t.firstByte.IsZero() {
+		timings["ttfb_ms"] = milliseconds(t.firstByte.Sub(t.start))
+		if !t.done.IsZero() {
+			timings["content_transfer_ms"] = milliseconds(t.done.Sub(t.firstByte))
+		}
+	}
+	if !t.done.IsZero() {
+		timings["total_ms"] = milliseconds(t.done.Sub(t.start))
+	}
+	return timings
+}
+
+// milliseconds converts d to fractional milliseconds with microsecond precision
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,114 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// slowHandler waits before the headers and again halfway through the body so
+// that both the time to first byte and the transfer are measurable
+func slowHandler(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(20 * time.Millisecond)
+	w.Write([]byte("first half, "))
+	w.(http.Flusher).Flush()
+	time.Sleep(20 * time.Millisecond)
+	w.Write([]byte("second half"))
+}
+
+func TestHTTPTimings(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  func(http.Handler) *httptest.Server
+		wantTLS bool
+	}{
+		{"plain HTTP", httptest.NewServer, false},
+		{"TLS", httptest.NewTLSServer, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server(http.HandlerFunc(slowHandler))
+			t.Cleanup(server.Close)
+
+			req, timings, err := newTracedRequest(context.Background(), http.MethodGet, server.URL, nil, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := sendHTTPRequest(server.Client(), req, timings, 0)
+			if err != nil {
+				t.Fatalf("sendHTTPRequest: %v", err)
+			}
+			got := resp.Timings
+
+			for _, phase := range []string{"dns_lookup_ms", "tcp_connect_ms", "tls_handshake_ms", "ttfb_ms", "content_transfer_ms", "total_ms"} {
+				if value, ok := got[phase]; !ok || value < 0 {
+					t.Errorf("%s = %v, %v, want a non-negative value", phase, value, ok)
+				}
+			}
+			if got["tcp_connect_ms"] <= 0 {
+				t.Errorf("tcp_connect_ms = %v, want the connection timed", got["tcp_connect_ms"])
+			}
+			if tt.wantTLS != (got["tls_handshake_ms"] > 0) {
+				t.Errorf("tls_handshake_ms = %v, want it set only for TLS", got["tls_handshake_ms"])
+			}
+			if got["ttfb_ms"] < 20 || got["content_transfer_ms"] < 20 {
+				t.Errorf("ttfb_ms = %v, content_transfer_ms = %v, want both to include the 20ms waits", got["ttfb_ms"], got["content_transfer_ms"])
+			}
+
+			// Connection setup happens before the first byte, and the first byte
+			// and the transfer make up the total
+			if setup := got["dns_lookup_ms"] + got["tcp_connect_ms"] + got["tls_handshake_ms"]; setup > got["ttfb_ms"] {
+				t.Errorf("setup = %vms, longer than ttfb_ms = %v", setup, got["ttfb_ms"])
+			}
+			if sum := got["ttfb_ms"] + got["content_transfer_ms"]; sum < got["total_ms"]-0.01 || sum > got["total_ms"]+0.01 {
+				t.Errorf("ttfb_ms + content_transfer_ms = %v, want total_ms = %v", sum, got["total_ms"])
+			}
+		})
+	}
+}
+
+func TestHTTPTimingsReusedConnection(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	client := server.Client()
+
+	var got map[string]float64
+	for range 2 {
+		req, timings, err := newTracedRequest(context.Background(), http.MethodGet, server.URL, nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := sendHTTPRequest(client, req, timings, 0)
+		if err != nil {
+			t.Fatalf("sendHTTPRequest: %v", err)
+		}
+		got = resp.Timings
+	}
+
+	// The second request reuses the kept-alive connection
+	if got["tcp_connect_ms"] != 0 || got["tls_handshake_ms"] != 0 {
+		t.Errorf("tcp_connect_ms = %v, tls_handshake_ms = %v, want no setup on a reused connection", got["tcp_connect_ms"], got["tls_handshake_ms"])
+	}
+	if got["total_ms"] <= 0 {
+		t.Errorf("total_ms = %v", got["total_ms"])
+	}
+}
+
+func TestHTTPTimingsInResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(slowHandler))
+	t.Cleanup(server.Close)
+
+	cfg := NewHTTPConfig()
+	cfg.URL = server.URL
+	result, err := RunHTTPCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunHTTPCheck: %v", err)
+	}
+	timings, ok := result.Metadata["timings"].(map[string]float64)
+	if !ok || timings["total_ms"] < 40 {
+		t.Errorf("timings = %v, want the phase breakdown in the metadata", result.Metadata["timings"])
+	}
+}
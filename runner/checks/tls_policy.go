@@ -0,0 +1,152 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// TLSPolicy configures probing of the protocol versions and cipher suites a
+// server accepts, and the rules they must meet
+type TLSPolicy struct {
+	MinVersion    string   `json:"minVersion"`    // Lowest acceptable version: "1.0", "1.1", "1.2" or "1.3"
+	BannedCiphers []string `json:"bannedCiphers"` // Cipher suite names, or fragments such as "CBC" or "3DES"
+	RequiredALPN  []string `json:"requiredAlpn"`  // Protocols the server must negotiate, e.g. "h2"
+}
+
+// tlsVersions are the protocol versions that can be probed, oldest first
+var tlsVersions = []struct {
+	name    string
+	version uint16
+}{
+	{"1.0", tls.VersionTLS10},
+	{"1.1", tls.VersionTLS11},
+	{"1.2", tls.VersionTLS12},
+	{"1.3", tls.VersionTLS13},
+}
+
+func (p *TLSPolicy) validate() error {
+	if p.MinVersion != "" && p.minVersion() == 0 {
+		return fmt.Errorf("minVersion must be one of 1.0, 1.1, 1.2 or 1.3")
+	}
+	return nil
+}
+
+func (p *TLSPolicy) minVersion() uint16 {
+	for _, v := range tlsVersions {
+		if v.name == strings.TrimPrefix(p.MinVersion, "TLS") {
+			return v.version
+		}
+	}
+	return 0
+}
+
+// banned returns the banned entry matching a cipher suite name, if any
+func (p *TLSPolicy) banned(suite string) string {
+	for _, banned := range p.BannedCiphers {
+		if strings.Contains(strings.ToUpper(suite), strings.ToUpper(banned)) {
+			return banned
+		}
+	}
+	return ""
+}
+
+// AcceptedCipherSuite is a cipher suite the server accepted while probing
+type AcceptedCipherSuite struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Insecure bool   `json:"insecure"`
+}
+
+// probeTLSPolicy performs a handshake for each protocol version and each
+// cipher suite, records what the server accepts and checks it against the
+// policy. TLS 1.3 suites cannot be chosen by the client, so only the one the
+// server negotiates is recorded.
+func probeTLSPolicy(ctx context.Context, cfg *SSLConfig, result *CheckResult) error {
+	policy := cfg.Policy
+
+	var protocols []string
+	var suites []AcceptedCipherSuite
+	var lowest uint16
+	for _, v := range tlsVersions {
+		state, ok := probeHandshake(ctx, cfg, &tls.Config{MinVersion: v.version, MaxVersion: v.version})
+		if !ok {
+			continue
+		}
+		protocols = append(protocols, tls.VersionName(v.version))
+		if lowest == 0 {
+			lowest = v.version
+		}
+		if v.version == tls.VersionTLS13 {
+			suites = append(suites, AcceptedCipherSuite{Name: tls.CipherSuiteName(state.CipherSuite), Version: tls.VersionName(v.version)})
+		}
+	}
+	result.Metadata["protocols"] = protocols
+
+	if lowest != 0 && lowest < tls.VersionTLS13 {
+		for _, suite := range probeCipherSuites() {
+			state, ok := probeHandshake(ctx, cfg, &tls.Config{
+				MinVersion:   lowest,
+				MaxVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{suite.ID},
+			})
+			if !ok {
+				continue
+			}
+			suites = append(suites, AcceptedCipherSuite{Name: suite.Name, Version: tls.VersionName(state.Version), Insecure: suite.Insecure})
+		}
+	}
+	result.Metadata["cipher_suites"] = suites
+
+	alpn := make(map[string]bool)
+	for _, protocol := range policy.RequiredALPN {
+		state, ok := probeHandshake(ctx, cfg, &tls.Config{NextProtos: []string{protocol}})
+		alpn[protocol] = ok && state.NegotiatedProtocol == protocol
+	}
+	if len(alpn) > 0 {
+		result.Metadata["alpn"] = alpn
+	}
+
+	if len(protocols) == 0 {
+		return fmt.Errorf("TLS policy violation: no protocol version could be negotiated")
+	}
+	if min := policy.minVersion(); min != 0 && lowest < min {
+		return fmt.Errorf("TLS policy violation: server accepts %s, below the minimum of %s", tls.VersionName(lowest), tls.VersionName(min))
+	}
+	for _, suite := range suites {
+		if banned := policy.banned(suite.Name); banned != "" {
+			return fmt.Errorf("TLS policy violation: server accepts banned cipher suite %s (%s)", suite.Name, banned)
+		}
+	}
+	for _, protocol := range policy.RequiredALPN {
+		if !alpn[protocol] {
+			return fmt.Errorf("TLS policy violation: server does not negotiate ALPN protocol %s", protocol)
+		}
+	}
+	return nil
+}
+
+// probeHandshake reports whether a handshake with the given settings succeeds
+func probeHandshake(ctx context.Context, cfg *SSLConfig, config *tls.Config) (tls.ConnectionState, bool) {
+	conn, err := dialTLS(ctx, cfg, config)
+	if err != nil {
+		return tls.ConnectionState{}, false
+	}
+	defer conn.Close()
+	return conn.ConnectionState(), true
+}
+
+// probeCipherSuites returns every TLS 1.0-1.2 cipher suite the client implements
+func probeCipherSuites() []*tls.CipherSuite {
+	var suites []*tls.CipherSuite
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		for _, v := range suite.SupportedVersions {
+			if v <= tls.VersionTLS12 {
+				suites = append(suites, suite)
+				break
+			}
+		}
+	}
+	return suites
+}
@@ -0,0 +1,144 @@
+package checks
+
+import (
+	"crypto/tls"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTLSPolicyProbe(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	leaf, key := root.issue(t, nil)
+	certificate := serverCertificate(leaf, key)
+
+	tls12Only := func(suites ...uint16) func(*tls.Config) {
+		return func(c *tls.Config) {
+			c.MinVersion, c.MaxVersion = tls.VersionTLS12, tls.VersionTLS12
+			c.CipherSuites = suites
+		}
+	}
+
+	tests := []struct {
+		name          string
+		server        func(*tls.Config)
+		policy        TLSPolicy
+		wantProtocols []string
+		wantSuites    []AcceptedCipherSuite
+		wantALPN      map[string]bool
+		wantErr       string
+	}{
+		{
+			name:          "TLS 1.2 with one suite",
+			server:        tls12Only(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256),
+			policy:        TLSPolicy{MinVersion: "1.2", BannedCiphers: []string{"CBC", "3DES"}},
+			wantProtocols: []string{"TLS 1.2"},
+			wantSuites:    []AcceptedCipherSuite{{Name: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", Version: "TLS 1.2"}},
+		},
+		{
+			name:          "TLS 1.3 only",
+			server:        func(c *tls.Config) { c.MinVersion = tls.VersionTLS13 },
+			policy:        TLSPolicy{MinVersion: "1.3"},
+			wantProtocols: []string{"TLS 1.3"},
+			wantSuites:    []AcceptedCipherSuite{{Name: "TLS_AES_128_GCM_SHA256", Version: "TLS 1.3"}},
+		},
+		{
+			name:          "below the minimum version",
+			server:        func(c *tls.Config) { c.MinVersion, c.MaxVersion = tls.VersionTLS11, tls.VersionTLS12 },
+			policy:        TLSPolicy{MinVersion: "TLS1.2"},
+			wantProtocols: []string{"TLS 1.1", "TLS 1.2"},
+			wantErr:       "TLS policy violation: server accepts TLS 1.1, below the minimum of TLS 1.2",
+		},
+		{
+			name:          "banned cipher suite",
+			server:        tls12Only(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA),
+			policy:        TLSPolicy{BannedCiphers: []string{"cbc"}},
+			wantProtocols: []string{"TLS 1.2"},
+			wantSuites: []AcceptedCipherSuite{
+				{Name: "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA", Version: "TLS 1.2"},
+				{Name: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", Version: "TLS 1.2"},
+			},
+			wantErr: "TLS policy violation: server accepts banned cipher suite TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA (cbc)",
+		},
+		{
+			name:          "insecure suite",
+			server:        tls12Only(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA),
+			wantProtocols: []string{"TLS 1.2"},
+			wantSuites: []AcceptedCipherSuite{
+				{Name: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", Version: "TLS 1.2"},
+				{Name: "TLS_ECDHE_ECDSA_WITH_RC4_128_SHA", Version: "TLS 1.2", Insecure: true},
+			},
+		},
+		{
+			name:          "ALPN negotiated",
+			server:        func(c *tls.Config) { c.MinVersion, c.NextProtos = tls.VersionTLS13, []string{"h2", "http/1.1"} },
+			policy:        TLSPolicy{RequiredALPN: []string{"h2", "http/1.1"}},
+			wantProtocols: []string{"TLS 1.3"},
+			wantALPN:      map[string]bool{"h2": true, "http/1.1": true},
+		},
+		{
+			name:          "ALPN missing",
+			server:        func(c *tls.Config) { c.MinVersion, c.NextProtos = tls.VersionTLS13, []string{"http/1.1"} },
+			policy:        TLSPolicy{RequiredALPN: []string{"http/1.1", "h2"}},
+			wantProtocols: []string{"TLS 1.3"},
+			wantALPN:      map[string]bool{"h2": false, "http/1.1": true},
+			wantErr:       "TLS policy violation: server does not negotiate ALPN protocol h2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestSSLConfig(startTLSTestServer(t, certificate, tt.server), root)
+			policy := tt.policy
+			cfg.Policy = &policy
+
+			result := runSSL(t, cfg)
+			if tt.wantErr == "" && result.Status != StatusSuccess {
+				t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+			}
+			if tt.wantErr != "" && (result.Status != StatusError || result.ErrorMessage != tt.wantErr) {
+				t.Fatalf("status = %q (%s), want an error %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if protocols := result.Metadata["protocols"]; !reflect.DeepEqual(protocols, tt.wantProtocols) {
+				t.Errorf("protocols = %v, want %v", protocols, tt.wantProtocols)
+			}
+			if tt.wantSuites != nil && !reflect.DeepEqual(result.Metadata["cipher_suites"], tt.wantSuites) {
+				t.Errorf("cipher_suites = %+v, want %+v", result.Metadata["cipher_suites"], tt.wantSuites)
+			}
+			if alpn, ok := result.Metadata["alpn"]; ok != (tt.wantALPN != nil) || ok && !reflect.DeepEqual(alpn, tt.wantALPN) {
+				t.Errorf("alpn = %v, want %v", alpn, tt.wantALPN)
+			}
+		})
+	}
+}
+
+func TestTLSPolicySkippedOnCertificateFailure(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil, nil)
+	leaf, key := newTestCA(t, "Other Root", nil, nil).issue(t, nil)
+	cfg := newTestSSLConfig(startTLSTestServer(t, serverCertificate(leaf, key), nil), root)
+	cfg.Policy = &TLSPolicy{MinVersion: "1.2"}
+
+	result := runSSL(t, cfg)
+	if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, "Certificate chain verification failed") {
+		t.Errorf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+	if _, ok := result.Metadata["protocols"]; ok {
+		t.Error("protocols were probed after the certificate check failed")
+	}
+}
+
+func TestTLSPolicyBanned(t *testing.T) {
+	policy := TLSPolicy{BannedCiphers: []string{"3DES", "TLS_RSA_WITH_AES_128_CBC_SHA"}}
+	tests := map[string]string{
+		"TLS_RSA_WITH_3DES_EDE_CBC_SHA":          "3DES",
+		"TLS_RSA_WITH_AES_128_CBC_SHA":           "TLS_RSA_WITH_AES_128_CBC_SHA",
+		"TLS_RSA_WITH_AES_128_CBC_SHA256":        "TLS_RSA_WITH_AES_128_CBC_SHA",
+		"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":  "",
+		"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305": "",
+	}
+	for suite, want := range tests {
+		if got := policy.banned(suite); got != want {
+			t.Errorf("banned(%s) = %q, want %q", suite, got, want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TransactionConfig configures a multi-step API transaction check. JSON field
+// names match the TransactionCheck spec.
+type TransactionConfig struct {
+	Timeout   Duration          `json:"timeout"`   // Per-step request timeout
+	Variables map[string]string `json:"variables"` // Initial variables, e.g. credentials or a base URL
+	Steps     []TransactionStep `json:"steps"`
+}
+
+// TransactionStep is one HTTP request of a transaction. URL, header values and
+// body may reference variables as {{name}}.
+type TransactionStep struct {
+	Name           string            `json:"name"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers"`
+	Body           string            `json:"body"`
+	ExpectedStatus int               `json:"expectedStatusCode"`
+	Assertions     HTTPAssertions    `json:"assertions"`
+	Extract        []Extraction      `json:"extract"`
+}
+
+// Extraction stores a value from a step's response in a variable. Exactly one
+// of JSONPath, Header and Regex is set; for Regex the first capture group is
+// used if there is one, otherwise the whole match.
+type Extraction struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"jsonPath"`
+	Header   string `json:"header"`
+	Regex    string `json:"regex"`
+}
+
+// StepResult records the outcome of a single transaction step
+type StepResult struct {
+	Name           string             `json:"name"`
+	Method         string             `json:"method"`
+	URL            string             `json:"url"`
+	Status         string             `json:"status"`
+	StatusCode     int                `json:"status_code,omitempty"`
+	ResponseTimeMs int64              `json:"response_time"`
+	Timings        map[string]float64 `json:"timings,omitempty"`
+	Extracted      []string           `json:"extracted,omitempty"` // Names only, values may be secrets
+	Error          string             `json:"error,omitempty"`
+}
+
+// NewTransactionConfig returns a TransactionConfig with defaults applied
+func NewTransactionConfig() *TransactionConfig {
+	return &TransactionConfig{
+		Timeout: Duration(30 * time.Second),
+	}
+}
+
+// LoadEnv is not supported: transactions have too much structure for
+// environment variables and must be configured with CHECK_CONFIG_FILE
+func (c *TransactionConfig) LoadEnv() error {
+	return fmt.Errorf("transaction checks must be configured with CHECK_CONFIG_FILE")
+}
+
+// Validate reports whether the configuration is complete
+func (c *TransactionConfig) Validate() error {
+	if len(c.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	for i, step := range c.Steps {
+		step = step.withDefaults(i)
+		if step.URL == "" {
+			return fmt.Errorf("%s: url is required", step.Name)
+		}
+		if err := step.Assertions.validate(); err != nil {
+			return fmt.Errorf("%s: %w", step.Name, err)
+		}
+		for _, extraction := range step.Extract {
+			if err := extraction.validate(); err != nil {
+				return fmt.Errorf("%s: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// withDefaults fills in the name, method and expected status of the i-th step
+func (s TransactionStep) withDefaults(i int) TransactionStep {
+	if s.Name == "" {
+		s.Name = fmt.Sprintf("step %d", i+1)
+	}
+	if s.Method == "" {
+		s.Method = http.MethodGet
+	}
+	if s.ExpectedStatus == 0 {
+		s.ExpectedStatus = http.StatusOK
+	}
+	return s
+}
+
+func (e Extraction) validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("extracted variables need a name")
+	}
+	set := 0
+	for _, source := range []string{e.JSONPath, e.Header, e.Regex} {
+		if source != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("variable %s must set exactly one of jsonPath, header or regex", e.Name)
+	}
+	if e.JSONPath != "" {
+		if _, err := parseJSONPath(e.JSONPath); err != nil {
+			return err
+		}
+	}
+	if e.Regex != "" {
+		if _, err := regexp.Compile(e.Regex); err != nil {
+			return fmt.Errorf("invalid regex for variable %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// transactionChecker runs multi-step API transaction checks
+type transactionChecker struct{}
+
+func init() {
+	Register(transactionChecker{})
+}
+
+func (transactionChecker) Name() string {
+	return "transaction"
+}
+
+func (transactionChecker) NewConfig() Config {
+	return NewTransactionConfig()
+}
+
+func (transactionChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*TransactionConfig]("transaction", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunTransactionCheck(ctx, c)
+}
+
+// RunTransactionCheck runs the steps of a transaction in order, sharing
+// cookies and extracted variables between them, and stops at the first
+// failing step. Every step's result is recorded in Metadata["steps"].
+func RunTransactionCheck(ctx context.Context, cfg *TransactionConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+	client := &http.Client{
+		Timeout: cfg.Timeout.Std(),
+		Jar:     jar,
+	}
+
+	variables := make(map[string]string, len(cfg.Variables))
+	for name, value := range cfg.Variables {
+		variables[name] = value
+	}
+
+	var steps []StepResult
+	start := time.Now()
+
+	for i, step := range cfg.Steps {
+		step = step.withDefaults(i)
+		stepResult, err := runTransactionStep(ctx, client, step, variables)
+		steps = append(steps, stepResult)
+		if err != nil {
+			result.ResponseTimeMs = time.Since(start).Milliseconds()
+			result.Metadata["steps"] = steps
+			result.Metadata["failed_step"] = step.Name
+			result.Status = StatusError
+			result.ErrorMessage = fmt.Sprintf("Step %q failed: %v", step.Name, err)
+			return result, nil
+		}
+	}
+
+	result.ResponseTimeMs = time.Since(start).Milliseconds()
+	result.Metadata["steps"] = steps
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// runTransactionStep sends one step's request, verifies the response and
+// stores the extracted values in variables
+func runTransactionStep(ctx context.Context, client *http.Client, step TransactionStep, variables map[string]string) (StepResult, error) {
+	stepResult := StepResult{Name: step.Name, Method: step.Method, Status: StatusError}
+
+	fail := func(err error) (StepResult, error) {
+		stepResult.Error = err.Error()
+		return stepResult, err
+	}
+
+	url, err := substitute(step.URL, variables)
+	if err != nil {
+		return fail(err)
+	}
+	stepResult.URL = url
+
+	headers := make(map[string]string, len(step.Headers))
+	for key, value := range step.Headers {
+		if headers[key], err = substitute(value, variables); err != nil {
+			return fail(err)
+		}
+	}
+
+	body, err := substitute(step.Body, variables)
+	if err != nil {
+		return fail(err)
+	}
+
+	req, timings, err := newTracedRequest(ctx, step.Method, url, headers, body)
+	if err != nil {
+		return fail(err)
+	}
+
+	resp, err := sendHTTPRequest(client, req, timings, step.Assertions.MaxBodySize)
+	stepResult.ResponseTimeMs = resp.ResponseTime.Milliseconds()
+	stepResult.Timings = resp.Timings
+	stepResult.StatusCode = resp.StatusCode
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := resp.verify(step.ExpectedStatus, &step.Assertions); err != nil {
+		return fail(err)
+	}
+
+	for _, extraction := range step.Extract {
+		value, err := extraction.extract(resp)
+		if err != nil {
+			return fail(err)
+		}
+		variables[extraction.Name] = value
+		stepResult.Extracted = append(stepResult.Extracted, extraction.Name)
+	}
+
+	stepResult.Status = StatusSuccess
+	return stepResult, nil
+}
+
+// extract reads the variable's value from a response
+func (e Extraction) extract(resp *httpResponse) (string, error) {
+	switch {
+	case e.Header != "":
+		value := resp.Header.Get(e.Header)
+		if value == "" {
+			return "", fmt.Errorf("header %s not found for variable %s", e.Header, e.Name)
+		}
+		return value, nil
+
+	case e.Regex != "":
+		match := regexp.MustCompile(e.Regex).FindSubmatch(resp.Body)
+		if match == nil {
+			return "", fmt.Errorf("body does not match /%s/ for variable %s", e.Regex, e.Name)
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+
+	default:
+		value, err := lookupJSONPath(resp.Body, e.JSONPath)
+		if err != nil {
+			return "", fmt.Errorf("%v for variable %s", err, e.Name)
+		}
+		return value, nil
+	}
+}
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// substitute replaces {{name}} references with variable values
+func substitute(text string, variables map[string]string) (string, error) {
+	var missing []string
+	replaced := variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := variablePattern.FindStringSubmatch(ref)[1]
+		value, ok := variables[name]
+		if !ok {
+			missing = append(missing, name)
+			return ref
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable %s", strings.Join(missing, ", "))
+	}
+	return replaced, nil
+}
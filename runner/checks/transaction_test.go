@@ -0,0 +1,289 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shopServer is a small API where logging in returns a token and a session
+// cookie that the order and checkout endpoints require
+type shopServer struct {
+	*httptest.Server
+
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func startShopServer(t *testing.T) *shopServer {
+	t.Helper()
+	shop := &shopServer{hits: make(map[string]int)}
+
+	authorized := func(r *http.Request) bool {
+		cookie, err := r.Cookie("session")
+		return err == nil && cookie.Value == "s-1" && r.Header.Get("Authorization") == "Bearer tok-123"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		var credentials struct{ User, Password string }
+		if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil || credentials.User != "alice" || credentials.Password != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s-1"})
+		w.Header().Set("X-Request-Id", "req-9")
+		w.Write([]byte(`{"token":"tok-123","user":{"id":42,"name":"Alice"}}`))
+	})
+	mux.HandleFunc("GET /users/{id}/orders", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, "Latest: Order #1001 for user %s", r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /checkout", func(w http.ResponseWriter, r *http.Request) {
+		var order struct{ Order string }
+		if !authorized(r) || r.Header.Get("X-Request-Id") != "req-9" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&order); err != nil || order.Order != "1001" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"confirmed"}`))
+	})
+
+	shop.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shop.mu.Lock()
+		shop.hits[r.URL.Path]++
+		shop.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(shop.Close)
+	return shop
+}
+
+func (s *shopServer) hitCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+// shopTransaction logs in, finds the latest order and checks it out
+func shopTransaction(baseURL string) *TransactionConfig {
+	cfg := NewTransactionConfig()
+	cfg.Timeout = Duration(5 * time.Second)
+	cfg.Variables = map[string]string{"baseURL": baseURL, "password": "hunter2"}
+	cfg.Steps = []TransactionStep{
+		{
+			Name:   "login",
+			Method: http.MethodPost,
+			URL:    "{{baseURL}}/login",
+			Body:   `{"user":"alice","password":"{{password}}"}`,
+			Extract: []Extraction{
+				{Name: "token", JSONPath: "$.token"},
+				{Name: "userId", JSONPath: "$.user.id"},
+				{Name: "requestId", Header: "X-Request-Id"},
+			},
+		},
+		{
+			Name:       "orders",
+			URL:        "{{baseURL}}/users/{{userId}}/orders",
+			Headers:    map[string]string{"Authorization": "Bearer {{ token }}"},
+			Assertions: HTTPAssertions{Contains: []string{"user 42"}},
+			Extract:    []Extraction{{Name: "orderId", Regex: `Order #(\d+)`}},
+		},
+		{
+			Name:           "checkout",
+			Method:         http.MethodPost,
+			URL:            "{{baseURL}}/checkout",
+			Headers:        map[string]string{"Authorization": "Bearer {{token}}", "X-Request-Id": "{{requestId}}"},
+			Body:           `{"order":"{{orderId}}"}`,
+			ExpectedStatus: http.StatusCreated,
+			Assertions:     HTTPAssertions{JSON: []JSONAssertion{jsonEquals("$.status", `"confirmed"`)}},
+		},
+	}
+	return cfg
+}
+
+func runTransaction(t *testing.T, cfg *TransactionConfig) (*CheckResult, []StepResult) {
+	t.Helper()
+	result, err := RunTransactionCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunTransactionCheck: %v", err)
+	}
+	steps, _ := result.Metadata["steps"].([]StepResult)
+	return result, steps
+}
+
+func TestTransactionCapturesVariables(t *testing.T) {
+	shop := startShopServer(t)
+
+	result, steps := runTransaction(t, shopTransaction(shop.URL))
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+	}
+	if _, ok := result.Metadata["failed_step"]; ok {
+		t.Errorf("failed_step = %v on success", result.Metadata["failed_step"])
+	}
+
+	want := []struct {
+		name       string
+		method     string
+		url        string
+		statusCode int
+		extracted  string
+	}{
+		{"login", http.MethodPost, shop.URL + "/login", 200, "token,userId,requestId"},
+		{"orders", http.MethodGet, shop.URL + "/users/42/orders", 200, "orderId"},
+		{"checkout", http.MethodPost, shop.URL + "/checkout", 201, ""},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("steps = %+v, want %d", steps, len(want))
+	}
+	for i, step := range steps {
+		w := want[i]
+		if step.Name != w.name || step.Method != w.method || step.URL != w.url || step.StatusCode != w.statusCode || step.Status != StatusSuccess {
+			t.Errorf("step %d = %+v, want %s %s %s -> %d", i+1, step, w.name, w.method, w.url, w.statusCode)
+		}
+		if got := strings.Join(step.Extracted, ","); got != w.extracted {
+			t.Errorf("%s extracted = %s, want %s", step.Name, got, w.extracted)
+		}
+		if step.Timings["total_ms"] <= 0 || step.Error != "" {
+			t.Errorf("%s timings = %v, error = %q", step.Name, step.Timings, step.Error)
+		}
+	}
+}
+
+func TestTransactionStopsAtFailedStep(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(cfg *TransactionConfig)
+		wantStep   string
+		wantSteps  int
+		wantErr    string
+		wantStatus int
+	}{
+		{
+			name:       "wrong credentials",
+			modify:     func(cfg *TransactionConfig) { cfg.Variables["password"] = "wrong" },
+			wantStep:   "login",
+			wantSteps:  1,
+			wantErr:    `Step "login" failed: Expected status 200, got 401`,
+			wantStatus: 401,
+		},
+		{
+			name:      "missing header",
+			modify:    func(cfg *TransactionConfig) { cfg.Steps[0].Extract[2].Header = "X-Trace-Id" },
+			wantStep:  "login",
+			wantSteps: 1,
+			wantErr:   `Step "login" failed: header X-Trace-Id not found for variable requestId`,
+		},
+		{
+			name:      "missing JSON value",
+			modify:    func(cfg *TransactionConfig) { cfg.Steps[0].Extract[0].JSONPath = "$.access_token" },
+			wantStep:  "login",
+			wantSteps: 1,
+			wantErr:   `Step "login" failed: $.access_token does not exist for variable token`,
+		},
+		{
+			name:      "regex without a match",
+			modify:    func(cfg *TransactionConfig) { cfg.Steps[1].Extract[0].Regex = `Invoice #(\d+)` },
+			wantStep:  "orders",
+			wantSteps: 2,
+			wantErr:   `Step "orders" failed: body does not match /Invoice #(\d+)/ for variable orderId`,
+		},
+		{
+			name:      "failed assertion",
+			modify:    func(cfg *TransactionConfig) { cfg.Steps[1].Assertions.Contains = []string{"user 7"} },
+			wantStep:  "orders",
+			wantSteps: 2,
+			wantErr:   `Step "orders" failed: Assertion failed: body does not contain "user 7"`,
+		},
+		{
+			name:      "undefined variable",
+			modify:    func(cfg *TransactionConfig) { cfg.Steps[1].Headers["Authorization"] = "Bearer {{apiKey}}" },
+			wantStep:  "orders",
+			wantSteps: 2,
+			wantErr:   `Step "orders" failed: undefined variable apiKey`,
+		},
+		{
+			name:       "last step",
+			modify:     func(cfg *TransactionConfig) { cfg.Steps[2].ExpectedStatus = http.StatusOK },
+			wantStep:   "checkout",
+			wantSteps:  3,
+			wantErr:    `Step "checkout" failed: Expected status 200, got 201`,
+			wantStatus: 201,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shop := startShopServer(t)
+			cfg := shopTransaction(shop.URL)
+			tt.modify(cfg)
+
+			result, steps := runTransaction(t, cfg)
+			if result.Status != StatusError || result.ErrorMessage != tt.wantErr {
+				t.Errorf("status = %q (%s), want an error %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if result.Metadata["failed_step"] != tt.wantStep {
+				t.Errorf("failed_step = %v, want %s", result.Metadata["failed_step"], tt.wantStep)
+			}
+			if len(steps) != tt.wantSteps {
+				t.Fatalf("steps = %+v, want %d", steps, tt.wantSteps)
+			}
+			for _, step := range steps[:len(steps)-1] {
+				if step.Status != StatusSuccess {
+					t.Errorf("%s status = %q, want success", step.Name, step.Status)
+				}
+			}
+			failed := steps[len(steps)-1]
+			if failed.Status != StatusError || !strings.HasSuffix(tt.wantErr, failed.Error) {
+				t.Errorf("failed step = %+v", failed)
+			}
+			if tt.wantStatus != 0 && failed.StatusCode != tt.wantStatus {
+				t.Errorf("failed step status code = %d, want %d", failed.StatusCode, tt.wantStatus)
+			}
+			if tt.wantSteps < 3 && shop.hitCount("/checkout") != 0 {
+				t.Error("checkout ran after an earlier step failed")
+			}
+		})
+	}
+}
+
+func TestTransactionValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []TransactionStep
+		want  string
+	}{
+		{"no steps", nil, "at least one step is required"},
+		{"no url", []TransactionStep{{}}, "step 1: url is required"},
+		{"bad assertion", []TransactionStep{{Name: "home", URL: "http://example.test", Assertions: HTTPAssertions{Matches: []string{"("}}}}, "home: invalid body assertion pattern"},
+		{"unnamed variable", []TransactionStep{{URL: "http://example.test", Extract: []Extraction{{Header: "X-Id"}}}}, "extracted variables need a name"},
+		{"two sources", []TransactionStep{{URL: "http://example.test", Extract: []Extraction{{Name: "id", Header: "X-Id", Regex: "id"}}}}, "variable id must set exactly one of jsonPath, header or regex"},
+		{"no source", []TransactionStep{{URL: "http://example.test", Extract: []Extraction{{Name: "id"}}}}, "variable id must set exactly one of jsonPath, header or regex"},
+		{"bad regex", []TransactionStep{{URL: "http://example.test", Extract: []Extraction{{Name: "id", Regex: "("}}}}, "invalid regex for variable id"},
+		{"bad path", []TransactionStep{{URL: "http://example.test", Extract: []Extraction{{Name: "id", JSONPath: "id"}}}}, "must start with $"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTransactionConfig()
+			cfg.Steps = tt.steps
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,19 @@ package checks
 
 import "time"
 
+// Check result statuses
+const (
+	StatusSuccess  = "success"
+	StatusDegraded = "degraded" // Passed, but slower than the latency warning threshold
+	StatusError    = "error"
+)
+
 // CheckResult represents the result of a synthetic check execution
 type CheckResult struct {
-	Status         string    `json:"status"`          // "success" or "error"
-	ResponseTimeMs int64     `json:"response_time"`   // Response time in milliseconds
-	Timestamp      time.Time `json:"timestamp"`       // When the check was executed
-	ErrorMessage   string    `json:"error,omitempty"` // Error message if status is "error"
+	Status         string                 `json:"status"`             // "success", "degraded" or "error"
+	ResponseTimeMs int64                  `json:"response_time"`      // Response time in milliseconds
+	Timestamp      time.Time              `json:"timestamp"`          // When the check was executed
+	ErrorMessage   string                 `json:"error,omitempty"`    // Error message if status is "error"
 	Metadata       map[string]interface{} `json:"metadata,omitempty"` // Additional check-specific data
 }
 
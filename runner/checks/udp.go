@@ -0,0 +1,234 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// UDPConfig configures a UDP check. JSON field names match the UdpCheck spec.
+type UDPConfig struct {
+	Host    string   `json:"host"`
+	Port    int      `json:"port"` // Default: 123 in NTP mode
+	Timeout Duration `json:"timeout"`
+
+	// Payload sent and response expected, e.g. a query and part of its answer
+	Exchange
+
+	NTP *NTPConfig `json:"ntp"` // Query the server with NTP instead of a payload
+}
+
+// udpMaxDatagram is the largest datagram the check reads
+const udpMaxDatagram = 64 * 1024
+
+// NewUDPConfig returns a UDPConfig with defaults applied
+func NewUDPConfig() *UDPConfig {
+	return &UDPConfig{
+		Timeout:  Duration(10 * time.Second),
+		Exchange: newExchange(),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
+//   - UDP_HOST: Target host (required)
+//   - UDP_PORT: Target port (required, default: 123 in NTP mode)
+//   - UDP_TIMEOUT: Timeout in seconds (default: 10)
+//   - UDP_SEND: Payload to send; escapes such as \r\n and \x00 are interpreted
+//   - UDP_SEND_HEX: Hex-encoded payload to send, as an alternative to UDP_SEND
+//   - UDP_EXPECT: Expected response
+//   - UDP_MATCH: How UDP_EXPECT is compared: contains, regex or hex (default: contains)
+//   - UDP_READ_TIMEOUT: How long to wait for the response (default: 5s)
+//   - UDP_NTP: Set to true to query the server with NTP
+//   - UDP_NTP_MAX_OFFSET: Largest allowed clock offset (default: 1s)
+//   - UDP_NTP_MAX_STRATUM: Highest allowed stratum (default: 15)
+func (c *UDPConfig) LoadEnv() error {
+	envString("UDP_HOST", &c.Host)
+	if c.Host == "" {
+		return fmt.Errorf("UDP_HOST environment variable is required")
+	}
+
+	if err := envInt("UDP_PORT", &c.Port); err != nil {
+		return err
+	}
+	if err := envDuration("UDP_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	if err := c.Exchange.loadEnv("UDP_"); err != nil {
+		return err
+	}
+
+	var enabled bool
+	if err := envBool("UDP_NTP", &enabled); err != nil {
+		return err
+	}
+	ntp := NewNTPConfig()
+	if err := envDuration("UDP_NTP_MAX_OFFSET", &ntp.MaxOffset); err != nil {
+		return err
+	}
+	if err := envInt("UDP_NTP_MAX_STRATUM", &ntp.MaxStratum); err != nil {
+		return err
+	}
+	if enabled {
+		c.NTP = ntp
+	}
+
+	if c.Port == 0 && c.NTP == nil {
+		return fmt.Errorf("UDP_PORT environment variable is required")
+	}
+	return nil
+}
+
+// Validate reports whether the configuration is complete
+func (c *UDPConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if c.port() == 0 {
+		return fmt.Errorf("port is required")
+	}
+	if err := c.Exchange.validate(); err != nil {
+		return err
+	}
+	if c.NTP != nil {
+		if c.Exchange.active() {
+			return fmt.Errorf("send and expect cannot be used in NTP mode")
+		}
+		return c.NTP.Validate()
+	}
+	if len(c.payload()) == 0 {
+		return fmt.Errorf("send or sendHex is required")
+	}
+	return nil
+}
+
+// port returns the configured port, or the NTP port in NTP mode
+func (c *UDPConfig) port() int {
+	if c.Port == 0 && c.NTP != nil {
+		return ntpPort
+	}
+	return c.Port
+}
+
+// udpChecker runs UDP checks
+type udpChecker struct{}
+
+func init() {
+	Register(udpChecker{})
+}
+
+func (udpChecker) Name() string {
+	return "udp"
+}
+
+func (udpChecker) NewConfig() Config {
+	return NewUDPConfig()
+}
+
+func (udpChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*UDPConfig]("udp", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunUDPCheck(ctx, c)
+}
+
+// RunUDPCheck sends a payload to a UDP service and matches the response, or
+// queries an NTP server and checks its clock offset and stratum
+func RunUDPCheck(ctx context.Context, cfg *UDPConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	result.Metadata["host"] = cfg.Host
+	result.Metadata["port"] = cfg.port()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout.Std())
+	defer cancel()
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.port()))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = fmt.Sprintf("UDP dial failed: %v", err)
+		return result, nil
+	}
+	defer conn.Close()
+
+	result.Metadata["local_addr"] = conn.LocalAddr().String()
+	result.Metadata["remote_addr"] = conn.RemoteAddr().String()
+
+	// Unblock reads if the check is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if cfg.NTP != nil {
+		err = checkNTP(ctx, conn, cfg, result)
+	} else {
+		err = exchangeUDP(ctx, conn, cfg, result)
+	}
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// exchangeUDP sends the payload and reads datagrams until one matches expect
+// or the read timeout passes. Without an expectation the first datagram is
+// recorded, and no response at all is not a failure since many UDP services
+// (syslog, for one) never answer; an ICMP port unreachable still fails.
+func exchangeUDP(ctx context.Context, conn net.Conn, cfg *UDPConfig, result *CheckResult) error {
+	conn.SetDeadline(cfg.readDeadline(ctx.Deadline()))
+
+	start := time.Now()
+	n, err := conn.Write(cfg.payload())
+	result.Metadata["sent_bytes"] = n
+	if err != nil {
+		return fmt.Errorf("Failed to send payload: %v", err)
+	}
+
+	buf := make([]byte, udpMaxDatagram)
+	var last []byte
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			result.ResponseTimeMs = time.Since(start).Milliseconds()
+			if last != nil {
+				recordResponse(result, last)
+			}
+
+			var netErr net.Error
+			switch {
+			case errors.Is(err, syscall.ECONNREFUSED):
+				return fmt.Errorf("UDP port unreachable: %v", err)
+			case !errors.As(err, &netErr) || !netErr.Timeout():
+				return fmt.Errorf("Failed to read response: %v", err)
+			case cfg.Expect == "":
+				result.Metadata["response_received"] = false
+				return nil
+			case last != nil:
+				return fmt.Errorf("Response does not match %q (%s). Got: %q", cfg.Expect, cfg.Match, truncateBytes(last, 100))
+			default:
+				return fmt.Errorf("No response received within %s", cfg.ReadTimeout.Std())
+			}
+		}
+
+		last = append([]byte(nil), buf[:n]...)
+		if cfg.Expect == "" || cfg.matches(last) {
+			result.ResponseTimeMs = time.Since(start).Milliseconds()
+			result.Metadata["response_received"] = true
+			recordResponse(result, last)
+			return nil
+		}
+	}
+}
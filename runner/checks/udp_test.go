@@ -0,0 +1,291 @@
+package checks
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveUDP answers datagrams on a loopback port until the test ends with the
+// datagrams reply returns, and returns the port
+func serveUDP(t *testing.T, reply func(request []byte) [][]byte) int {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, udpMaxDatagram)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			for _, datagram := range reply(append([]byte(nil), buf[:n]...)) {
+				pc.WriteTo(datagram, addr)
+			}
+		}
+	}()
+	return pc.LocalAddr().(*net.UDPAddr).Port
+}
+
+func runUDP(t *testing.T, cfg *UDPConfig) *CheckResult {
+	t.Helper()
+	cfg.Host = "127.0.0.1"
+	cfg.Timeout = Duration(5 * time.Second)
+	result, err := RunUDPCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunUDPCheck: %v", err)
+	}
+	return result
+}
+
+func TestUDPExchange(t *testing.T) {
+	answers := func(replies ...string) func([]byte) [][]byte {
+		return func(request []byte) [][]byte {
+			if string(request) != "ping" && request[0] != 0x01 {
+				return nil
+			}
+			var datagrams [][]byte
+			for _, reply := range replies {
+				datagrams = append(datagrams, []byte(reply))
+			}
+			return datagrams
+		}
+	}
+
+	tests := []struct {
+		name         string
+		replies      []string
+		exchange     Exchange
+		wantErr      string
+		wantReceived any
+	}{
+		{name: "expected reply", replies: []string{"pong"}, exchange: Exchange{Send: "ping", Expect: "pong"}, wantReceived: true},
+		{name: "reply after noise", replies: []string{"noise", "pong"}, exchange: Exchange{Send: "ping", Expect: "^po", Match: MatchRegex}, wantReceived: true},
+		{name: "hex", replies: []string{"\xca\xfe"}, exchange: Exchange{SendHex: "01", Expect: "cafe", Match: MatchHex}, wantReceived: true},
+		{name: "any reply", replies: []string{"pong"}, exchange: Exchange{Send: "ping"}, wantReceived: true},
+		{name: "no reply expected", exchange: Exchange{Send: "ping"}, wantReceived: false},
+		{name: "no reply", exchange: Exchange{Send: "ping", Expect: "pong"}, wantErr: "No response received within 200ms"},
+		{name: "different reply", replies: []string{"nope"}, exchange: Exchange{Send: "ping", Expect: "pong"}, wantErr: `Response does not match "pong" (contains). Got: "nope"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewUDPConfig()
+			cfg.Port = serveUDP(t, answers(tt.replies...))
+			cfg.Exchange = tt.exchange
+			if cfg.Match == "" {
+				cfg.Match = MatchContains
+			}
+			cfg.ReadTimeout = Duration(200 * time.Millisecond)
+
+			result := runUDP(t, cfg)
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+			} else if result.Status != StatusError || result.ErrorMessage != tt.wantErr {
+				t.Fatalf("status = %q (%s), want an error %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if tt.wantReceived != nil && result.Metadata["response_received"] != tt.wantReceived {
+				t.Errorf("response_received = %v, want %v", result.Metadata["response_received"], tt.wantReceived)
+			}
+		})
+	}
+}
+
+func TestUDPPortUnreachable(t *testing.T) {
+	_, port, _ := net.SplitHostPort(closedDNSAddress(t))
+	cfg := NewUDPConfig()
+	cfg.Send = "ping"
+	cfg.ReadTimeout = Duration(time.Second)
+	cfg.Port, _ = strconv.Atoi(port)
+
+	result := runUDP(t, cfg)
+	if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, "UDP port unreachable") {
+		t.Errorf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+}
+
+// fakeNTP describes how a fake NTP server answers
+type fakeNTP struct {
+	leap        uint8
+	stratum     uint8
+	referenceID [4]byte
+	offset      time.Duration // Added to the server's clock
+	stray       [][]byte      // Sent before the reply
+	silent      bool          // Send no reply after the stray packets
+}
+
+func (f fakeNTP) reply(request []byte) [][]byte {
+	datagrams := append([][]byte(nil), f.stray...)
+	if f.silent || len(request) < ntpPacketSize {
+		return datagrams
+	}
+
+	response := make([]byte, ntpPacketSize)
+	response[0] = f.leap<<6 | 4<<3 | ntpModeServer
+	response[1] = f.stratum
+	binary.BigEndian.PutUint32(response[4:], 0x00000800) // 31.25ms root delay
+	binary.BigEndian.PutUint32(response[8:], 0x00000400) // 15.625ms root dispersion
+	copy(response[12:], f.referenceID[:])
+	copy(response[24:32], request[40:48]) // Origin is the client's transmit time
+	now := toNTPTime(time.Now().Add(f.offset))
+	binary.BigEndian.PutUint64(response[32:], now)
+	binary.BigEndian.PutUint64(response[40:], now)
+	return append(datagrams, response)
+}
+
+// ntpPacket returns a 48-byte packet in the given mode with an origin
+// timestamp that matches no request
+func ntpPacket(mode uint8) []byte {
+	packet := make([]byte, ntpPacketSize)
+	packet[0] = 4<<3 | mode
+	packet[1] = 2
+	binary.BigEndian.PutUint64(packet[24:], toNTPTime(time.Now().Add(-time.Hour)))
+	return packet
+}
+
+func TestNTP(t *testing.T) {
+	upstream := [4]byte{192, 0, 2, 1}
+	tests := []struct {
+		name       string
+		server     fakeNTP
+		maxStratum int
+		wantErr    string
+	}{
+		{name: "synchronized", server: fakeNTP{stratum: 2, referenceID: upstream}},
+		{name: "clock offset", server: fakeNTP{stratum: 2, offset: 5 * time.Second}, wantErr: "NTP clock offset "},
+		{name: "clock behind", server: fakeNTP{stratum: 2, offset: -3 * time.Second}, wantErr: "NTP clock offset -"},
+		{name: "stratum too high", server: fakeNTP{stratum: 4}, maxStratum: 3, wantErr: "NTP stratum 4 is above the maximum of 3"},
+		{name: "unsynchronized", server: fakeNTP{leap: ntpLeapAlarm, stratum: 2}, wantErr: "NTP server is not synchronized"},
+		{name: "stratum 16", server: fakeNTP{stratum: 16}, wantErr: "NTP server is not synchronized"},
+		{name: "kiss-of-death", server: fakeNTP{stratum: 0, referenceID: [4]byte{'R', 'A', 'T', 'E'}}, wantErr: `NTP server sent kiss-of-death "RATE"`},
+		{
+			name: "stray packets are skipped",
+			server: fakeNTP{stratum: 2, stray: [][]byte{
+				[]byte("short"),
+				ntpPacket(ntpModeClient),
+				ntpPacket(ntpModeServer),
+			}},
+		},
+		{
+			name:    "only invalid packets",
+			server:  fakeNTP{silent: true, stray: [][]byte{ntpPacket(ntpModeServer), []byte("short")}},
+			wantErr: "No valid NTP response received: NTP response too short: 5 bytes",
+		},
+		{
+			name:    "only a late reply",
+			server:  fakeNTP{silent: true, stray: [][]byte{ntpPacket(ntpModeServer)}},
+			wantErr: "No NTP response received",
+		},
+		{name: "no reply", server: fakeNTP{silent: true}, wantErr: "No NTP response received"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewUDPConfig()
+			cfg.Port = serveUDP(t, tt.server.reply)
+			cfg.ReadTimeout = Duration(200 * time.Millisecond)
+			cfg.NTP = NewNTPConfig()
+			if tt.maxStratum != 0 {
+				cfg.NTP.MaxStratum = tt.maxStratum
+			}
+
+			result := runUDP(t, cfg)
+			if tt.wantErr == "" {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status = %q (%s), want success", result.Status, result.ErrorMessage)
+				}
+			} else if result.Status != StatusError || !strings.HasPrefix(result.ErrorMessage, tt.wantErr) {
+				t.Fatalf("status = %q (%s), want an error starting with %q", result.Status, result.ErrorMessage, tt.wantErr)
+			}
+			if offset, ok := result.Metadata["ntp_offset_ms"].(float64); ok {
+				if want := milliseconds(tt.server.offset); offset < want-100 || offset > want+100 {
+					t.Errorf("ntp_offset_ms = %v, want about %v", offset, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNTPMetadata(t *testing.T) {
+	tests := []struct {
+		server          fakeNTP
+		wantReferenceID string
+	}{
+		{fakeNTP{stratum: 2, referenceID: [4]byte{192, 0, 2, 1}}, "192.0.2.1"},
+		{fakeNTP{stratum: 1, referenceID: [4]byte{'G', 'P', 'S', 0}}, "GPS"},
+	}
+
+	for _, tt := range tests {
+		cfg := NewUDPConfig()
+		cfg.Port = serveUDP(t, tt.server.reply)
+		cfg.NTP = NewNTPConfig()
+
+		result := runUDP(t, cfg)
+		if result.Status != StatusSuccess {
+			t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+		}
+		if result.Metadata["ntp_reference_id"] != tt.wantReferenceID || result.Metadata["ntp_stratum"] != tt.server.stratum {
+			t.Errorf("ntp_reference_id = %v, ntp_stratum = %v", result.Metadata["ntp_reference_id"], result.Metadata["ntp_stratum"])
+		}
+		if result.Metadata["ntp_version"] != uint8(4) || result.Metadata["ntp_root_delay_ms"] != 31.25 || result.Metadata["ntp_root_dispersion_ms"] != 15.625 {
+			t.Errorf("ntp_version = %v, ntp_root_delay_ms = %v, ntp_root_dispersion_ms = %v",
+				result.Metadata["ntp_version"], result.Metadata["ntp_root_delay_ms"], result.Metadata["ntp_root_dispersion_ms"])
+		}
+	}
+}
+
+func TestNTPTimestamps(t *testing.T) {
+	now := time.Date(2026, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	if got := fromNTPTime(toNTPTime(now)); got.Sub(now).Abs() > time.Nanosecond {
+		t.Errorf("round trip = %s, want %s", got, now)
+	}
+	if got := fromNTPTime(uint64(ntpEpochOffset) << 32); !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("Unix epoch = %s", got)
+	}
+	if got := fromNTPShort(0x00018000); got != 1500*time.Millisecond {
+		t.Errorf("fromNTPShort(1.5) = %s", got)
+	}
+}
+
+func TestUDPValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  func(cfg *UDPConfig)
+		want string
+	}{
+		{"no host", func(cfg *UDPConfig) { cfg.Host = "" }, "host is required"},
+		{"no port", func(cfg *UDPConfig) { cfg.Port = 0 }, "port is required"},
+		{"no payload", func(cfg *UDPConfig) { cfg.Send = "" }, "send or sendHex is required"},
+		{"payload in NTP mode", func(cfg *UDPConfig) { cfg.NTP = NewNTPConfig() }, "send and expect cannot be used in NTP mode"},
+		{"NTP offset", func(cfg *UDPConfig) { cfg.Send, cfg.NTP = "", &NTPConfig{MaxStratum: 15} }, "NTP max offset must be positive"},
+		{"NTP stratum", func(cfg *UDPConfig) {
+			cfg.Send, cfg.NTP = "", &NTPConfig{MaxOffset: Duration(time.Second), MaxStratum: 16}
+		}, "NTP max stratum must be between 1 and 15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewUDPConfig()
+			cfg.Host, cfg.Port, cfg.Send = "ntp.example.test", 1234, "ping"
+			tt.cfg(cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	cfg := NewUDPConfig()
+	cfg.Host, cfg.NTP = "ntp.example.test", NewNTPConfig()
+	if err := cfg.Validate(); err != nil || cfg.port() != ntpPort {
+		t.Errorf("NTP mode: Validate() = %v, port = %d, want the NTP port", err, cfg.port())
+	}
+}
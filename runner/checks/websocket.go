@@ -0,0 +1,288 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketConfig configures a WebSocket check. JSON field names match the WebSocketCheck spec.
+type WebSocketConfig struct {
+	URL                string            `json:"url"` // ws:// or wss:// URL
+	Timeout            Duration          `json:"timeout"`
+	Headers            map[string]string `json:"headers"`      // Sent with the handshake, e.g. Authorization
+	Subprotocols       []string          `json:"subprotocols"` // Offered in Sec-WebSocket-Protocol; the server must accept one
+	InsecureSkipVerify bool              `json:"insecureSkipVerify"`
+	Exchange
+	ExpectJSON []JSONAssertion `json:"expectJson"` // Assertions a JSON message must satisfy
+}
+
+// NewWebSocketConfig returns a WebSocketConfig with defaults applied
+func NewWebSocketConfig() *WebSocketConfig {
+	return &WebSocketConfig{
+		Timeout:  Duration(10 * time.Second),
+		Exchange: newExchange(),
+	}
+}
+
+// LoadEnv reads the configuration from environment variables:
+//   - WEBSOCKET_URL: Target ws:// or wss:// URL (required)
+//   - WEBSOCKET_TIMEOUT: Timeout in seconds (default: 10)
+//   - WEBSOCKET_HEADERS: Comma-separated key:value pairs (e.g., "Authorization:Bearer token")
+//   - WEBSOCKET_SUBPROTOCOLS: Comma-separated subprotocols to offer
+//   - WEBSOCKET_INSECURE_SKIP_VERIFY: Set to true to skip verifying the server's certificate
+//   - WEBSOCKET_SEND: Text message to send after connecting; escapes such as \n are interpreted
+//   - WEBSOCKET_SEND_HEX: Hex-encoded binary message to send, as an alternative to WEBSOCKET_SEND
+//   - WEBSOCKET_EXPECT: Expected message, compared according to WEBSOCKET_MATCH
+//   - WEBSOCKET_MATCH: contains, regex or hex (default: contains)
+//   - WEBSOCKET_READ_TIMEOUT: How long to wait for the expected message (default: 5s)
+//   - WEBSOCKET_JSON_EQUALS: Comma-separated path=value pairs the message must satisfy, e.g. "$.type=pong"
+//   - WEBSOCKET_JSON_EXISTS: Comma-separated JSONPaths that must exist in the message
+func (c *WebSocketConfig) LoadEnv() error {
+	envString("WEBSOCKET_URL", &c.URL)
+	if c.URL == "" {
+		return fmt.Errorf("WEBSOCKET_URL environment variable is required")
+	}
+
+	if err := envDuration("WEBSOCKET_TIMEOUT", &c.Timeout); err != nil {
+		return err
+	}
+	envPairs("WEBSOCKET_HEADERS", &c.Headers)
+	envList("WEBSOCKET_SUBPROTOCOLS", &c.Subprotocols)
+	if err := envBool("WEBSOCKET_INSECURE_SKIP_VERIFY", &c.InsecureSkipVerify); err != nil {
+		return err
+	}
+	if err := c.Exchange.loadEnv("WEBSOCKET_"); err != nil {
+		return err
+	}
+	return loadJSONAssertionsEnv("WEBSOCKET_", &c.ExpectJSON)
+}
+
+// Validate reports whether the configuration is complete
+func (c *WebSocketConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute ws or wss URL")
+	}
+	if c.InsecureSkipVerify && u.Scheme != "wss" {
+		return fmt.Errorf("insecureSkipVerify requires a wss URL")
+	}
+	if err := c.Exchange.validate(); err != nil {
+		return err
+	}
+	if len(c.ExpectJSON) > 0 && c.ReadTimeout <= 0 {
+		return fmt.Errorf("read timeout must be positive")
+	}
+	return validateJSONAssertions(c.ExpectJSON)
+}
+
+// waits reports whether the check reads messages after the handshake
+func (c *WebSocketConfig) waits() bool {
+	return c.Exchange.active() || len(c.ExpectJSON) > 0
+}
+
+// expects reports whether a message has to satisfy an expectation
+func (c *WebSocketConfig) expects() bool {
+	return c.Expect != "" || len(c.ExpectJSON) > 0
+}
+
+// checkMessage describes why a message does not satisfy expect and expectJson
+func (c *WebSocketConfig) checkMessage(message []byte) error {
+	if c.Expect != "" && !c.matches(message) {
+		return fmt.Errorf("message does not match %q (%s)", c.Expect, c.Match)
+	}
+	if len(c.ExpectJSON) == 0 {
+		return nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(message, &document); err != nil {
+		return fmt.Errorf("message is not valid JSON: %v", err)
+	}
+	for _, assertion := range c.ExpectJSON {
+		if err := assertion.check(document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webSocketChecker runs WebSocket checks
+type webSocketChecker struct{}
+
+func init() {
+	Register(webSocketChecker{})
+}
+
+func (webSocketChecker) Name() string {
+	return "websocket"
+}
+
+func (webSocketChecker) NewConfig() Config {
+	return NewWebSocketConfig()
+}
+
+func (webSocketChecker) Run(ctx context.Context, cfg Config) (*CheckResult, error) {
+	c, err := configAs[*WebSocketConfig]("websocket", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunWebSocketCheck(ctx, c)
+}
+
+// RunWebSocketCheck opens a WebSocket connection and, if configured, sends a
+// message and waits for one matching the expectations
+func RunWebSocketCheck(ctx context.Context, cfg *WebSocketConfig) (*CheckResult, error) {
+	result := NewCheckResult()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	result.Metadata["url"] = cfg.URL
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout.Std())
+	defer cancel()
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: cfg.Timeout.Std(),
+		Subprotocols:     cfg.Subprotocols,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify},
+	}
+	header := make(http.Header)
+	for key, value := range cfg.Headers {
+		header.Set(key, value)
+	}
+
+	start := time.Now()
+	conn, resp, err := dialer.DialContext(ctx, cfg.URL, header)
+	handshake := time.Since(start)
+	result.ResponseTimeMs = handshake.Milliseconds()
+	if resp != nil {
+		result.Metadata["status_code"] = resp.StatusCode
+	}
+	if err != nil {
+		result.Status = StatusError
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			result.ErrorMessage = fmt.Sprintf("WebSocket handshake failed: server responded with status %d", resp.StatusCode)
+		} else {
+			result.ErrorMessage = fmt.Sprintf("WebSocket connection failed: %v", err)
+		}
+		return result, nil
+	}
+	defer conn.Close()
+
+	result.Metadata["handshake_time_ms"] = handshake.Milliseconds()
+	if protocol := conn.Subprotocol(); protocol != "" {
+		result.Metadata["subprotocol"] = protocol
+	}
+
+	// Unblock reads and writes if the check is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.NetConn().SetDeadline(time.Now()) })
+	defer stop()
+
+	err = checkWebSocket(ctx, conn, cfg, result, start)
+	closeWebSocket(conn)
+	if err != nil {
+		result.Status = StatusError
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// checkWebSocket verifies the negotiated subprotocol, then sends the payload
+// and reads messages until one satisfies the expectations or the read timeout
+// passes. With a payload but no expectation the first message is recorded, and
+// no reply at all is not a failure.
+func checkWebSocket(ctx context.Context, conn *websocket.Conn, cfg *WebSocketConfig, result *CheckResult, start time.Time) error {
+	if len(cfg.Subprotocols) > 0 && conn.Subprotocol() == "" {
+		return fmt.Errorf("Server did not accept any of the subprotocols %s", strings.Join(cfg.Subprotocols, ", "))
+	}
+	if !cfg.waits() {
+		return nil
+	}
+
+	deadline := cfg.readDeadline(ctx.Deadline())
+	conn.SetWriteDeadline(deadline)
+	conn.SetReadDeadline(deadline)
+
+	// Messages are read whole, so cap them as HTTP response bodies are
+	conn.SetReadLimit(defaultBodyReadLimit)
+
+	sent := time.Now()
+	if payload := cfg.payload(); len(payload) > 0 {
+		messageType := websocket.TextMessage
+		if cfg.SendHex != "" {
+			messageType = websocket.BinaryMessage
+		}
+		if err := conn.WriteMessage(messageType, payload); err != nil {
+			return fmt.Errorf("Failed to send message: %v", err)
+		}
+		result.Metadata["sent_bytes"] = len(payload)
+	}
+
+	received := 0
+	var last []byte
+	var mismatch error
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			result.ResponseTimeMs = time.Since(start).Milliseconds()
+			result.Metadata["messages_received"] = received
+			if last != nil {
+				recordResponse(result, last)
+			}
+
+			var netErr net.Error
+			var closeErr *websocket.CloseError
+			switch {
+			case errors.As(err, &closeErr):
+				return fmt.Errorf("Server closed the connection: %v", closeErr)
+			case !errors.As(err, &netErr) || !netErr.Timeout():
+				return fmt.Errorf("Failed to read message: %v", err)
+			case !cfg.expects():
+				result.Metadata["response_received"] = false
+				return nil
+			case mismatch != nil:
+				return fmt.Errorf("No matching message received within %s: %v. Got: %q", cfg.ReadTimeout.Std(), mismatch, truncateBytes(last, 100))
+			default:
+				return fmt.Errorf("No message received within %s", cfg.ReadTimeout.Std())
+			}
+		}
+
+		received++
+		if received == 1 {
+			result.Metadata["first_message_ms"] = time.Since(sent).Milliseconds()
+		}
+		last = message
+		if mismatch = cfg.checkMessage(message); mismatch == nil {
+			result.ResponseTimeMs = time.Since(start).Milliseconds()
+			result.Metadata["messages_received"] = received
+			result.Metadata["response_received"] = true
+			recordResponse(result, message)
+			return nil
+		}
+	}
+}
+
+// closeWebSocket sends a close frame so the server sees a clean disconnect
+func closeWebSocket(conn *websocket.Conn) {
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+}
@@ -0,0 +1,77 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startWebSocketServer answers every message with reply(message)
+func startWebSocketServer(t *testing.T, reply func([]byte) []byte) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, reply(message)); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	url := startWebSocketServer(t, func(message []byte) []byte {
+		return append([]byte(`{"type":"pong","echo":"`), append(message, `"}`...)...)
+	})
+
+	cfg := NewWebSocketConfig()
+	cfg.URL = url
+	cfg.Send = "ping"
+	cfg.ExpectJSON = []JSONAssertion{{Path: "$.type", Equals: json.RawMessage(`"pong"`)}}
+
+	result, err := RunWebSocketCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunWebSocketCheck: %v", err)
+	}
+	if result.Status != StatusSuccess {
+		t.Fatalf("status = %q (%s)", result.Status, result.ErrorMessage)
+	}
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	url := startWebSocketServer(t, func([]byte) []byte {
+		return bytes.Repeat([]byte("x"), defaultBodyReadLimit+1)
+	})
+
+	cfg := NewWebSocketConfig()
+	cfg.URL = url
+	cfg.Send = "ping"
+	cfg.Expect = "pong"
+	cfg.ReadTimeout = Duration(5 * time.Second)
+
+	result, err := RunWebSocketCheck(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunWebSocketCheck: %v", err)
+	}
+	if result.Status != StatusError || !strings.Contains(result.ErrorMessage, "read limit") {
+		t.Errorf("status = %q (%s), want a read limit error", result.Status, result.ErrorMessage)
+	}
+}
@@ -35,11 +35,42 @@ type ExecutionRequest struct {
 	Details      map[string]interface{} `json:"details,omitempty"`
 }
 
+// Job is a monitoring job as returned by the API
+type Job struct {
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Config    json.RawMessage `json:"config"`
+	Enabled   bool            `json:"enabled"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ListJobs fetches all jobs from the API
+func (c *Client) ListJobs() ([]Job, error) {
+	url := fmt.Sprintf("%s/api/v1/jobs", c.baseURL)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned non-success status: %d", resp.StatusCode)
+	}
+
+	var jobs []Job
+	if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
 // ReportExecution reports a check execution result to the API
 func (c *Client) ReportExecution(jobName string, result *checks.CheckResult) error {
-	// Map "error" status to "failure" for API compatibility
+	// Map "error" status to "failure" for API compatibility; "success" and "degraded" pass through
 	status := result.Status
-	if status == "error" {
+	if status == checks.StatusError {
 		status = "failure"
 	}
 
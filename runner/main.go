@@ -1,21 +1,47 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/itskarma/moogie/runner/checks"
 	"github.com/itskarma/moogie/runner/client"
+	"github.com/itskarma/moogie/runner/scheduler"
 )
 
 func main() {
 	// Read configuration from environment variables
-	checkType := os.Getenv("CHECK_TYPE")
 	apiURL := os.Getenv("MOOGIE_API_URL")
 	if apiURL == "" {
 		apiURL = "http://moogie-api:8080"
 	}
+
+	// Create API client
+	apiClient := client.NewClient(apiURL)
+
+	switch mode := os.Getenv("RUNNER_MODE"); mode {
+	case "", "once":
+		runOnce(apiClient)
+	case "scheduler":
+		runScheduler(apiClient)
+	default:
+		log.Fatalf("Unknown RUNNER_MODE: %s (expected \"once\" or \"scheduler\")", mode)
+	}
+}
+
+// runOnce executes the single check described by CHECK_TYPE and reports the
+// result, as used by the Kubernetes CronJobs. The check is configured from
+// CHECK_CONFIG_FILE (a JSON spec or moogie.io/v1 document) when set, and from
+// its environment variables otherwise.
+func runOnce(apiClient *client.Client) {
+	checkType := os.Getenv("CHECK_TYPE")
 	jobName := os.Getenv("JOB_NAME")
 
 	if checkType == "" {
@@ -25,32 +51,26 @@ func main() {
 		log.Fatal("JOB_NAME environment variable is required")
 	}
 
-	// Create API client
-	apiClient := client.NewClient(apiURL)
+	checker, ok := checks.Lookup(checkType)
+	if !ok {
+		log.Fatalf("Unknown check type: %s (available: %s)", checkType, strings.Join(checks.Names(), ", "))
+	}
+
+	cfg, opts, err := loadCheckConfig(checker)
 
-	// Execute the check based on type
+	// Execute the check, retrying failed attempts
 	var result *checks.CheckResult
-	var err error
-
-	switch checkType {
-	case "http":
-		result, err = checks.RunHTTPCheck()
-	case "ssl":
-		result, err = checks.RunSSLCheck()
-	case "dns":
-		result, err = checks.RunDNSCheck()
-	case "tcp":
-		result, err = checks.RunTCPCheck()
-	default:
-		log.Fatalf("Unknown check type: %s", checkType)
+	if err == nil {
+		result, err = checks.Run(context.Background(), checker, cfg, opts)
 	}
 
 	if err != nil {
 		log.Printf("Check execution error: %v", err)
 		// Still report the failure to the API
 		result = &checks.CheckResult{
-			Status:       "error",
+			Status:       checks.StatusError,
 			ErrorMessage: err.Error(),
+			Timestamp:    time.Now().UTC(),
 		}
 	}
 
@@ -62,3 +82,63 @@ func main() {
 	fmt.Printf("Check completed successfully. Status: %s, Response Time: %dms\n",
 		result.Status, result.ResponseTimeMs)
 }
+
+// loadCheckConfig builds the check config and run options from CHECK_CONFIG_FILE
+// when it is set, and from environment variables otherwise
+func loadCheckConfig(checker checks.Checker) (checks.Config, *checks.RunOptions, error) {
+	if path := os.Getenv("CHECK_CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		cfg, err := checks.ConfigFromJSON(checker, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts, err := checks.RunOptionsFromJSON(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cfg, opts, nil
+	}
+
+	cfg, err := checks.ConfigFromEnv(checker)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts, err := checks.RunOptionsFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, opts, nil
+}
+
+// runScheduler runs every enabled job from the API on its own schedule until interrupted
+//   - SCHEDULER_WORKERS: Number of checks that can run concurrently (default: 4)
+//   - SCHEDULER_RELOAD_INTERVAL: How often to reload jobs from the API (default: 30s)
+func runScheduler(apiClient *client.Client) {
+	workers := 4
+	if workersStr := os.Getenv("SCHEDULER_WORKERS"); workersStr != "" {
+		w, err := strconv.Atoi(workersStr)
+		if err != nil || w < 1 {
+			log.Fatalf("Invalid SCHEDULER_WORKERS: %s", workersStr)
+		}
+		workers = w
+	}
+
+	reloadInterval := 30 * time.Second
+	if intervalStr := os.Getenv("SCHEDULER_RELOAD_INTERVAL"); intervalStr != "" {
+		d, err := time.ParseDuration(intervalStr)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid SCHEDULER_RELOAD_INTERVAL: %s", intervalStr)
+		}
+		reloadInterval = d
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := scheduler.New(apiClient, workers, reloadInterval).Run(ctx); err != nil {
+		log.Fatalf("Scheduler failed: %v", err)
+	}
+}
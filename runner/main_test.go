@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itskarma/moogie/runner/checks"
+)
+
+func TestLoadCheckConfigPrefersFile(t *testing.T) {
+	checker, ok := checks.Lookup("tcp")
+	if !ok {
+		t.Fatal("tcp checker not registered")
+	}
+
+	t.Setenv("TCP_HOST", "from-env")
+	t.Setenv("TCP_PORT", "1111")
+	t.Setenv("CHECK_RETRIES", "5")
+
+	// Environment variables are used when no file is given
+	t.Setenv("CHECK_CONFIG_FILE", "")
+	cfg, opts, err := loadCheckConfig(checker)
+	if err != nil {
+		t.Fatalf("loadCheckConfig: %v", err)
+	}
+	if got := cfg.(*checks.TCPConfig); got.Host != "from-env" || got.Port != 1111 {
+		t.Errorf("env config = %+v", got)
+	}
+	if opts.Retry.Retries != 5 {
+		t.Errorf("env retries = %d, want 5", opts.Retry.Retries)
+	}
+
+	// The file wins over the environment, including for run options
+	path := filepath.Join(t.TempDir(), "check.json")
+	data := `{"apiVersion":"moogie.io/v1","kind":"TcpCheck","spec":{"host":"from-file","port":2222,"retries":1}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CHECK_CONFIG_FILE", path)
+
+	cfg, opts, err = loadCheckConfig(checker)
+	if err != nil {
+		t.Fatalf("loadCheckConfig: %v", err)
+	}
+	if got := cfg.(*checks.TCPConfig); got.Host != "from-file" || got.Port != 2222 {
+		t.Errorf("file config = %+v", got)
+	}
+	if opts.Retry.Retries != 1 {
+		t.Errorf("file retries = %d, want 1", opts.Retry.Retries)
+	}
+}
@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/itskarma/moogie/runner/checks"
+	"github.com/itskarma/moogie/runner/client"
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler runs enabled jobs from the API on their cron schedule inside a
+// single long-running process, as an alternative to one CronJob per check
+type Scheduler struct {
+	client         jobAPI
+	workers        int
+	reloadInterval time.Duration
+
+	cron    *cron.Cron
+	queue   chan client.Job
+	entries map[string]entry // keyed by job name
+
+	mu      sync.Mutex
+	running map[string]bool // jobs currently executing, to avoid overlapping runs
+}
+
+// jobAPI is the part of the API client the scheduler uses
+type jobAPI interface {
+	ListJobs() ([]client.Job, error)
+	ReportExecution(jobName string, result *checks.CheckResult) error
+}
+
+// entry is a scheduled job together with the version it was scheduled from
+type entry struct {
+	id        cron.EntryID
+	signature string
+}
+
+// New creates a scheduler that executes checks on a pool of workers and
+// reloads the job list from the API every reloadInterval
+func New(apiClient *client.Client, workers int, reloadInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		client:         apiClient,
+		workers:        workers,
+		reloadInterval: reloadInterval,
+		cron:           cron.New(),
+		queue:          make(chan client.Job, workers*10),
+		entries:        make(map[string]entry),
+		running:        make(map[string]bool),
+	}
+}
+
+// Run schedules jobs until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	if err := s.reload(); err != nil {
+		return fmt.Errorf("failed to load jobs: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+
+	s.cron.Start()
+	log.Printf("Scheduler started with %d workers, %d jobs scheduled", s.workers, len(s.entries))
+
+	ticker := time.NewTicker(s.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-s.cron.Stop().Done()
+			close(s.queue)
+			wg.Wait()
+			log.Println("Scheduler stopped")
+			return nil
+		case <-ticker.C:
+			if err := s.reload(); err != nil {
+				// Keep running the current schedule if the API is unavailable
+				log.Printf("Failed to reload jobs: %v", err)
+			}
+		}
+	}
+}
+
+// reload fetches the job list and adds, replaces or removes cron entries for jobs that changed
+func (s *Scheduler) reload() error {
+	jobs, err := s.client.ListJobs()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, job := range jobs {
+		if !job.Enabled {
+			continue
+		}
+		if _, ok := checks.Lookup(job.Type); !ok {
+			// Left to custom check containers running on their own schedule, or
+			// pushed by the job itself for heartbeats
+			continue
+		}
+		seen[job.Name] = true
+
+		signature := job.Type + "|" + string(job.Config)
+		if existing, ok := s.entries[job.Name]; ok {
+			if existing.signature == signature {
+				continue
+			}
+			s.cron.Remove(existing.id)
+			delete(s.entries, job.Name)
+		}
+
+		schedule, err := jobSchedule(job)
+		if err != nil {
+			log.Printf("Skipping job %s: %v", job.Name, err)
+			continue
+		}
+
+		job := job
+		id, err := s.cron.AddFunc(schedule, func() { s.enqueue(job) })
+		if err != nil {
+			log.Printf("Skipping job %s: invalid schedule %q: %v", job.Name, schedule, err)
+			continue
+		}
+
+		s.entries[job.Name] = entry{id: id, signature: signature}
+		log.Printf("Scheduled job %s (%s) with schedule %q", job.Name, job.Type, schedule)
+	}
+
+	for name, existing := range s.entries {
+		if !seen[name] {
+			s.cron.Remove(existing.id)
+			delete(s.entries, name)
+			log.Printf("Unscheduled job %s", name)
+		}
+	}
+
+	return nil
+}
+
+// enqueue hands a due job to the worker pool, skipping it if the previous run has not finished
+func (s *Scheduler) enqueue(job client.Job) {
+	s.mu.Lock()
+	if s.running[job.Name] {
+		s.mu.Unlock()
+		log.Printf("Skipping job %s: previous run still in progress", job.Name)
+		return
+	}
+	s.running[job.Name] = true
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- job:
+	default:
+		s.finish(job.Name)
+		log.Printf("Skipping job %s: worker queue is full", job.Name)
+	}
+}
+
+func (s *Scheduler) finish(name string) {
+	s.mu.Lock()
+	delete(s.running, name)
+	s.mu.Unlock()
+}
+
+// work executes queued jobs and reports their results. Checks already queued
+// are allowed to finish on shutdown rather than being reported as cancelled.
+func (s *Scheduler) work(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	for job := range s.queue {
+		result, err := runJob(ctx, job)
+		if err != nil {
+			log.Printf("Check execution error for %s: %v", job.Name, err)
+			// Still report the failure to the API
+			result = &checks.CheckResult{
+				Status:       checks.StatusError,
+				ErrorMessage: err.Error(),
+				Timestamp:    time.Now().UTC(),
+			}
+		}
+
+		if err := s.client.ReportExecution(job.Name, result); err != nil {
+			log.Printf("Failed to report execution result for %s: %v", job.Name, err)
+		} else {
+			log.Printf("Check %s completed. Status: %s, Response Time: %dms", job.Name, result.Status, result.ResponseTimeMs)
+		}
+
+		s.finish(job.Name)
+	}
+}
+
+// jobSpec extracts the spec from a job config
+func jobSpec(job client.Job) (json.RawMessage, error) {
+	var config struct {
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal(job.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if len(config.Spec) == 0 {
+		return nil, fmt.Errorf("config has no spec")
+	}
+	return config.Spec, nil
+}
+
+// jobSchedule extracts the cron schedule from a job config
+func jobSchedule(job client.Job) (string, error) {
+	spec, err := jobSpec(job)
+	if err != nil {
+		return "", err
+	}
+
+	var common struct {
+		Schedule string `json:"schedule"`
+	}
+	if err := json.Unmarshal(spec, &common); err != nil {
+		return "", fmt.Errorf("invalid spec: %w", err)
+	}
+	if common.Schedule == "" {
+		return "", fmt.Errorf("spec has no schedule")
+	}
+	return common.Schedule, nil
+}
+
+// runJob builds the check's config and run options from the job and runs the check
+func runJob(ctx context.Context, job client.Job) (*checks.CheckResult, error) {
+	checker, ok := checks.Lookup(job.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown check type: %s", job.Type)
+	}
+
+	cfg, err := checks.ConfigFromJSON(checker, job.Config)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := checks.RunOptionsFromJSON(job.Config)
+	if err != nil {
+		return nil, err
+	}
+	return checks.Run(ctx, checker, cfg, opts)
+}
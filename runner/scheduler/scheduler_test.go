@@ -0,0 +1,255 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itskarma/moogie/runner/checks"
+	"github.com/itskarma/moogie/runner/client"
+)
+
+// fakeAPI serves a job list that tests can change and records reported results
+type fakeAPI struct {
+	mu      sync.Mutex
+	jobs    []client.Job
+	err     error
+	reports map[string]int
+}
+
+func (f *fakeAPI) ListJobs() ([]client.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]client.Job(nil), f.jobs...), f.err
+}
+
+func (f *fakeAPI) ReportExecution(jobName string, result *checks.CheckResult) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.reports == nil {
+		f.reports = make(map[string]int)
+	}
+	f.reports[jobName]++
+	return nil
+}
+
+func (f *fakeAPI) setJobs(jobs ...client.Job) {
+	f.mu.Lock()
+	f.jobs = jobs
+	f.mu.Unlock()
+}
+
+func (f *fakeAPI) reported() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	total := 0
+	for _, n := range f.reports {
+		total += n
+	}
+	return total
+}
+
+func newTestScheduler(api *fakeAPI, workers int) *Scheduler {
+	s := New(nil, workers, time.Hour)
+	s.client = api
+	return s
+}
+
+// testJob returns an enabled job whose spec has the given schedule and host
+func testJob(name, checkType, schedule, host string) client.Job {
+	spec, _ := json.Marshal(map[string]any{"schedule": schedule, "host": host, "port": 443})
+	return client.Job{Name: name, Type: checkType, Enabled: true, Config: json.RawMessage(`{"spec":` + string(spec) + `}`)}
+}
+
+func TestReload(t *testing.T) {
+	api := &fakeAPI{}
+	s := newTestScheduler(api, 1)
+
+	disabled := testJob("disabled", "tcp", "@every 1m", "db.example.test")
+	disabled.Enabled = false
+	api.setJobs(
+		testJob("web", "tcp", "@every 1m", "web.example.test"),
+		testJob("db", "tcp", "@every 5m", "db.example.test"),
+		testJob("cache", "tcp", "@every 5m", "cache.example.test"),
+		disabled,
+		testJob("custom", "my-custom-check", "@every 1m", "custom.example.test"),
+		testJob("invalid schedule", "tcp", "every minute", "web.example.test"),
+		testJob("no schedule", "tcp", "", "web.example.test"),
+	)
+	if err := s.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	assertScheduled(t, s, "web", "db", "cache")
+	before := maps.Clone(s.entries)
+
+	// db is rescheduled, cache is removed and queue is added; web is unchanged
+	api.setJobs(
+		testJob("web", "tcp", "@every 1m", "web.example.test"),
+		testJob("db", "tcp", "@every 10m", "db.example.test"),
+		testJob("queue", "tcp", "@every 1m", "queue.example.test"),
+	)
+	if err := s.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	assertScheduled(t, s, "web", "db", "queue")
+	if s.entries["web"].id != before["web"].id {
+		t.Error("web was rescheduled although it did not change")
+	}
+	if s.entries["db"].id == before["db"].id {
+		t.Error("db was not rescheduled after its schedule changed")
+	}
+	if got := s.cron.Entry(s.entries["db"].id).Schedule.Next(time.Time{}); got != (time.Time{}).Add(10*time.Minute) {
+		t.Errorf("db next run = %s, want the new schedule", got)
+	}
+
+	// A disabled job is unscheduled like a deleted one
+	db := testJob("db", "tcp", "@every 10m", "db.example.test")
+	db.Enabled = false
+	api.setJobs(testJob("web", "tcp", "@every 1m", "web.example.test"), db)
+	if err := s.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	assertScheduled(t, s, "web")
+
+	// The current schedule is kept while the API is unavailable
+	api.err = errors.New("connection refused")
+	if err := s.reload(); err == nil {
+		t.Error("reload succeeded while the API failed")
+	}
+	assertScheduled(t, s, "web")
+}
+
+// assertScheduled checks that exactly the named jobs have cron entries
+func assertScheduled(t *testing.T, s *Scheduler, names ...string) {
+	t.Helper()
+	if len(s.entries) != len(names) || len(s.cron.Entries()) != len(names) {
+		t.Errorf("scheduled %d jobs with %d cron entries, want %v", len(s.entries), len(s.cron.Entries()), names)
+	}
+	for _, name := range names {
+		if _, ok := s.entries[name]; !ok {
+			t.Errorf("%s is not scheduled", name)
+		}
+	}
+}
+
+// blockingChecker holds every run until released and tracks how many run at once
+type blockingChecker struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	release   chan struct{}
+}
+
+var blocking = &blockingChecker{}
+
+func init() {
+	checks.Register(blocking)
+}
+
+func (b *blockingChecker) Name() string             { return "scheduler-test-blocking" }
+func (b *blockingChecker) NewConfig() checks.Config { return checks.NewTCPConfig() }
+func (b *blockingChecker) Run(ctx context.Context, cfg checks.Config) (*checks.CheckResult, error) {
+	b.mu.Lock()
+	b.active++
+	b.maxActive = max(b.maxActive, b.active)
+	release := b.release
+	b.mu.Unlock()
+
+	<-release
+
+	b.mu.Lock()
+	b.active--
+	b.mu.Unlock()
+	result := checks.NewCheckResult()
+	result.Status = checks.StatusSuccess
+	return result, nil
+}
+
+func (b *blockingChecker) reset() {
+	b.mu.Lock()
+	b.active, b.maxActive = 0, 0
+	b.release = make(chan struct{})
+	b.mu.Unlock()
+}
+
+func (b *blockingChecker) running() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.active
+}
+
+func TestWorkerPoolBound(t *testing.T) {
+	blocking.reset()
+	api := &fakeAPI{}
+	const workers = 3
+	s := newTestScheduler(api, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(context.Background())
+		}()
+	}
+
+	const jobs = 10
+	for i := 0; i < jobs; i++ {
+		s.enqueue(testJob(fmt.Sprintf("job-%d", i), blocking.Name(), "@every 1m", "example.test"))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for blocking.running() < workers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Give any extra worker the chance to pick up a job
+	time.Sleep(50 * time.Millisecond)
+	if running := blocking.running(); running != workers {
+		t.Errorf("%d checks running, want %d", running, workers)
+	}
+
+	close(blocking.release)
+	close(s.queue)
+	wg.Wait()
+
+	if blocking.maxActive != workers {
+		t.Errorf("at most %d checks ran at once, want %d", blocking.maxActive, workers)
+	}
+	if got := api.reported(); got != jobs {
+		t.Errorf("reported %d results, want %d", got, jobs)
+	}
+	if len(s.running) != 0 {
+		t.Errorf("jobs still marked running: %v", s.running)
+	}
+}
+
+func TestEnqueueSkips(t *testing.T) {
+	s := newTestScheduler(&fakeAPI{}, 1)
+	job := testJob("web", "tcp", "@every 1m", "web.example.test")
+
+	// A job whose previous run has not finished is not queued again
+	s.enqueue(job)
+	s.enqueue(job)
+	if len(s.queue) != 1 {
+		t.Errorf("queued %d runs of the same job, want 1", len(s.queue))
+	}
+	<-s.queue
+	s.finish(job.Name)
+
+	// Jobs beyond the queue capacity are dropped rather than blocking the cron
+	for i := 0; i < cap(s.queue)+1; i++ {
+		s.enqueue(testJob(fmt.Sprintf("job-%d", i), "tcp", "@every 1m", "example.test"))
+	}
+	if len(s.queue) != cap(s.queue) {
+		t.Errorf("queued %d jobs, want %d", len(s.queue), cap(s.queue))
+	}
+	dropped := fmt.Sprintf("job-%d", cap(s.queue))
+	if s.running[dropped] {
+		t.Errorf("%s is still marked running after being dropped", dropped)
+	}
+}
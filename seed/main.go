@@ -17,32 +17,32 @@ import (
 var jobTypes = []string{"http", "tcp", "dns", "ssl", "ping"}
 
 // Possible statuses for executions
-var statuses = []string{"success", "failure"}
+var statuses = []string{"success", "degraded", "failure"}
 
 // Sample configurations for different job types
 type HTTPConfig struct {
-	APIVersion string            `json:"apiVersion"`
-	Kind       string            `json:"kind"`
-	Metadata   map[string]string `json:"metadata"`
-	Spec       HTTPSpec          `json:"spec"`
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       HTTPSpec `json:"spec"`
 }
 
 type HTTPSpec struct {
-	URL                  string            `json:"url"`
-	Method               string            `json:"method"`
-	Timeout              string            `json:"timeout"`
-	ExpectedStatusCode   int               `json:"expectedStatusCode"`
-	Schedule             string            `json:"schedule"`
-	Retries              int               `json:"retries"`
-	Headers              map[string]string `json:"headers"`
-	Alerts               AlertConfig       `json:"alerts"`
+	URL                string            `json:"url"`
+	Method             string            `json:"method"`
+	Timeout            string            `json:"timeout"`
+	ExpectedStatusCode int               `json:"expectedStatusCode"`
+	Schedule           string            `json:"schedule"`
+	Retries            int               `json:"retries"`
+	Headers            map[string]string `json:"headers"`
+	Alerts             AlertConfig       `json:"alerts"`
 }
 
 type TCPConfig struct {
-	APIVersion string            `json:"apiVersion"`
-	Kind       string            `json:"kind"`
-	Metadata   map[string]string `json:"metadata"`
-	Spec       TCPSpec           `json:"spec"`
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       TCPSpec  `json:"spec"`
 }
 
 type TCPSpec struct {
@@ -55,43 +55,43 @@ type TCPSpec struct {
 }
 
 type DNSConfig struct {
-	APIVersion string            `json:"apiVersion"`
-	Kind       string            `json:"kind"`
-	Metadata   map[string]string `json:"metadata"`
-	Spec       DNSSpec           `json:"spec"`
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       DNSSpec  `json:"spec"`
 }
 
 type DNSSpec struct {
-	Domain   string      `json:"domain"`
-	Type     string      `json:"type"`
-	Expected string      `json:"expected"`
-	Timeout  string      `json:"timeout"`
-	Schedule string      `json:"schedule"`
-	Retries  int         `json:"retries"`
-	Alerts   AlertConfig `json:"alerts"`
+	Domain     string      `json:"domain"`
+	RecordType string      `json:"recordType"`
+	ExpectedIP string      `json:"expectedIp,omitempty"`
+	Timeout    string      `json:"timeout"`
+	Schedule   string      `json:"schedule"`
+	Retries    int         `json:"retries"`
+	Alerts     AlertConfig `json:"alerts"`
 }
 
 type SSLConfig struct {
-	APIVersion string            `json:"apiVersion"`
-	Kind       string            `json:"kind"`
-	Metadata   map[string]string `json:"metadata"`
-	Spec       SSLSpec           `json:"spec"`
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       SSLSpec  `json:"spec"`
 }
 
 type SSLSpec struct {
 	Host             string      `json:"host"`
 	Port             int         `json:"port"`
-	ExpirationDays   int         `json:"expirationDays"`
+	DaysBeforeExpiry int         `json:"daysBeforeExpiry"`
 	Schedule         string      `json:"schedule"`
 	Retries          int         `json:"retries"`
 	Alerts           AlertConfig `json:"alerts"`
 }
 
 type PingConfig struct {
-	APIVersion string            `json:"apiVersion"`
-	Kind       string            `json:"kind"`
-	Metadata   map[string]string `json:"metadata"`
-	Spec       PingSpec          `json:"spec"`
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       PingSpec `json:"spec"`
 }
 
 type PingSpec struct {
@@ -103,6 +103,12 @@ type PingSpec struct {
 	Alerts   AlertConfig `json:"alerts"`
 }
 
+// Metadata mirrors the metadata block of the config/checks manifests
+type Metadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
 type AlertConfig struct {
 	OnFailure bool   `json:"onFailure"`
 	Email     string `json:"email"`
@@ -196,7 +202,7 @@ func createJobs(db *sql.DB, count int) ([]int, error) {
 	for i := 0; i < count; i++ {
 		jobType := jobTypes[rand.Intn(len(jobTypes))]
 		name := generateJobName(jobType, i)
-		config := generateJobConfig(jobType)
+		config := generateJobConfig(jobType, name)
 		enabled := rand.Float32() < 0.9 // 90% chance of being enabled
 
 		configJSON, err := json.Marshal(config)
@@ -210,7 +216,7 @@ func createJobs(db *sql.DB, count int) ([]int, error) {
 			VALUES ($1, $2, $3, $4, $5, $6)
 			RETURNING id
 		`, name, jobType, configJSON, enabled, time.Now(), time.Now()).Scan(&jobID)
-		
+
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert job: %w", err)
 		}
@@ -246,6 +252,9 @@ func createExecutions(db *sql.DB, jobIDs []int, days int) error {
 			status := "success"
 			if rand.Float64() > successRate {
 				status = "failure"
+			} else if rand.Float64() < 0.05 {
+				// A few passing checks exceed their latency warning threshold
+				status = "degraded"
 			}
 
 			// Generate realistic response time
@@ -299,13 +308,16 @@ func generateJobName(jobType string, index int) string {
 	}
 }
 
-func generateJobConfig(jobType string) interface{} {
+func generateJobConfig(jobType, name string) interface{} {
 	gofakeit.Seed(time.Now().UnixNano())
 
-	metadata := map[string]string{
-		"environment": []string{"production", "staging", "development"}[rand.Intn(3)],
-		"team":        []string{"backend", "frontend", "devops", "platform"}[rand.Intn(4)],
-		"service":     gofakeit.AppName(),
+	metadata := Metadata{
+		Name: name,
+		Labels: map[string]string{
+			"environment": []string{"production", "staging", "development"}[rand.Intn(3)],
+			"team":        []string{"backend", "frontend", "devops", "platform"}[rand.Intn(4)],
+			"service":     gofakeit.AppName(),
+		},
 	}
 
 	alerts := AlertConfig{
@@ -356,13 +368,13 @@ func generateJobConfig(jobType string) interface{} {
 			Kind:       "DnsCheck",
 			Metadata:   metadata,
 			Spec: DNSSpec{
-				Domain:   gofakeit.DomainName(),
-				Type:     []string{"A", "AAAA", "CNAME", "MX"}[rand.Intn(4)],
-				Expected: gofakeit.IPv4Address(),
-				Timeout:  fmt.Sprintf("%ds", 5+rand.Intn(10)),
-				Schedule: schedule,
-				Retries:  1 + rand.Intn(3),
-				Alerts:   alerts,
+				Domain:     gofakeit.DomainName(),
+				RecordType: "A",
+				ExpectedIP: gofakeit.IPv4Address(),
+				Timeout:    fmt.Sprintf("%ds", 5+rand.Intn(10)),
+				Schedule:   schedule,
+				Retries:    1 + rand.Intn(3),
+				Alerts:     alerts,
 			},
 		}
 	case "ssl":
@@ -371,12 +383,12 @@ func generateJobConfig(jobType string) interface{} {
 			Kind:       "SslCheck",
 			Metadata:   metadata,
 			Spec: SSLSpec{
-				Host:           gofakeit.DomainName(),
-				Port:           443,
-				ExpirationDays: 7 + rand.Intn(23), // 7-30 days warning
-				Schedule:       schedule,
-				Retries:        1 + rand.Intn(3),
-				Alerts:         alerts,
+				Host:             gofakeit.DomainName(),
+				Port:             443,
+				DaysBeforeExpiry: 7 + rand.Intn(23), // 7-30 days warning
+				Schedule:         schedule,
+				Retries:          1 + rand.Intn(3),
+				Alerts:           alerts,
 			},
 		}
 	case "ping":
@@ -402,6 +414,9 @@ func generateResponseTime(status string) int64 {
 	if status == "success" {
 		// Success: 50-500ms typically
 		return int64(50 + rand.Intn(450))
+	} else if status == "degraded" {
+		// Degraded: passed, but slow (800-2000ms)
+		return int64(800 + rand.Intn(1200))
 	} else {
 		// Failure: either timeout (5000ms+) or quick failure (100-1000ms)
 		if rand.Float32() < 0.3 {
@@ -422,7 +437,7 @@ func generateExecutionDetails(status string, responseTime int64) map[string]inte
 		"timestamp":     time.Now().Format(time.RFC3339),
 	}
 
-	if status == "success" {
+	if status == "success" || status == "degraded" {
 		details["message"] = "Check completed successfully"
 		details["response_code"] = []int{200, 201, 204}[rand.Intn(3)]
 		details["response_headers"] = map[string]string{
@@ -430,6 +445,9 @@ func generateExecutionDetails(status string, responseTime int64) map[string]inte
 			"content-length": fmt.Sprintf("%d", 100+rand.Intn(900)),
 			"server":         []string{"nginx", "apache", "cloudflare"}[rand.Intn(3)],
 		}
+		if status == "degraded" {
+			details["warning"] = fmt.Sprintf("Response time %dms exceeded warning threshold of 750ms", responseTime)
+		}
 	} else {
 		errors := []string{
 			"Connection timeout",
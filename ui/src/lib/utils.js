@@ -26,6 +26,7 @@ export function getStatusColor(status) {
     case "error":
       return getCSSVar("status-failed");
     case "warning":
+    case "degraded":
       return getCSSVar("status-warning");
     case "timeout":
       return getCSSVar("status-timeout");